@@ -0,0 +1,85 @@
+// Package client 供接入方服务使用的工具，不依赖权限系统内部包
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const syncRoutesPath = "/permission/node/syncroutes"
+
+// Route 与权限系统 /node/syncroutes 接口的 routes 参数一致
+type Route struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Label  string `json:"label,omitempty"`
+}
+
+// SyncRequest /node/syncroutes 的请求参数
+type SyncRequest struct {
+	ProductId int64   `json:"productId"`
+	AppId     int64   `json:"appId"`
+	ParentId  int64   `json:"parentId"`
+	UserId    int64   `json:"userId"`
+	Routes    []Route `json:"routes,omitempty"`
+	OpenApi   string  `json:"openApi,omitempty"`
+	DryRun    bool    `json:"dryRun"`
+}
+
+// SyncNode 与权限系统 models.Node 中同步相关的字段
+type SyncNode struct {
+	ID       int64  `json:"id"`
+	Label    string `json:"label"`
+	Resource string `json:"resource"`
+	IsStale  int8   `json:"isStale"`
+}
+
+type SyncResult struct {
+	Added   []SyncNode `json:"added"`
+	Updated []SyncNode `json:"updated"`
+	Stale   []SyncNode `json:"stale"`
+	Revived []SyncNode `json:"revived"`
+}
+
+// RoutesFromEngine 从 gin 路由表生成路由清单，skip 返回 true 的路由(如探针、监控接口)不上报
+func RoutesFromEngine(engine *gin.Engine, skip func(gin.RouteInfo) bool) []Route {
+	var routes []Route
+	for _, r := range engine.Routes() {
+		if skip != nil && skip(r) {
+			continue
+		}
+		routes = append(routes, Route{Method: r.Method, Path: r.Path})
+	}
+	return routes
+}
+
+// SyncRoutes 调用权限系统同步路由清单，host 形如 http://permission.example.com
+func SyncRoutes(host string, req SyncRequest) (SyncResult, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	resp, err := httpClient.Post(host+syncRoutesPath, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return SyncResult{}, err
+	}
+	defer resp.Body.Close()
+	var ret struct {
+		ErrNo  int        `json:"errNo"`
+		ErrMsg string     `json:"errMsg"`
+		Data   SyncResult `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&ret); err != nil {
+		return SyncResult{}, err
+	}
+	if ret.ErrNo != 0 {
+		return SyncResult{}, fmt.Errorf("sync routes failure, errNo:%d errMsg:%s", ret.ErrNo, ret.ErrMsg)
+	}
+	return ret.Data, nil
+}
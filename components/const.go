@@ -4,6 +4,9 @@ const PAGE_NUM = 1
 const PAGE_SIZE = 20
 const TABLE_PREX = "tb_permission_"
 
+// 用户权限组关系表按 user_id 取模分表的数量
+const USER_GROUP_SHARD_NUM int64 = 16
+
 const (
 	CASBIN_RULE_PTYPE = "p"
 	CASBIN_ACT_ANY    = "any"
@@ -33,3 +36,67 @@ const (
 	NODE_TYPE_API  int8 = 0
 	NODE_TYPE_PAGE int8 = 1
 )
+
+// 菜单节点是否在导航中展示
+const (
+	NODE_HIDDEN int8 = 0
+	NODE_SHOW   int8 = 1
+)
+
+// casbin 模型状态：新建为草稿，校验通过后启用才能绑定到domain
+const (
+	CASBIN_MODEL_STATUS_DRAFT  int8 = 0
+	CASBIN_MODEL_STATUS_ACTIVE int8 = 1
+)
+
+// 未绑定模型的domain使用的内置模型，即 conf/rbac_model.conf
+const CASBIN_MODEL_DEFAULT = "default"
+
+// 权限组下接口的来源：直接授予或由页面依赖带出
+const (
+	GROUP_NODE_EXPLICIT int8 = 0
+	GROUP_NODE_IMPLIED  int8 = 1
+)
+
+const (
+	SNAPSHOT_TRIGGER_MANUAL   int8 = 0
+	SNAPSHOT_TRIGGER_AUTO     int8 = 1
+	SNAPSHOT_TRIGGER_ROLLBACK int8 = 2
+)
+
+// 单次变更涉及的映射关系数达到该阈值时，变更前自动为产线打快照
+const SNAPSHOT_AUTO_THRESHOLD = 50
+
+const (
+	ACCESS_REQUEST_TYPE_GROUP    int8 = 0
+	ACCESS_REQUEST_TYPE_RESOURCE int8 = 1
+)
+
+// 权限申请单状态：pending 可流转到其余任一状态，approved 的限时授权到期后流转到 expired
+const (
+	ACCESS_REQUEST_STATUS_PENDING   int8 = 0
+	ACCESS_REQUEST_STATUS_APPROVED  int8 = 1
+	ACCESS_REQUEST_STATUS_REJECTED  int8 = 2
+	ACCESS_REQUEST_STATUS_EXPIRED   int8 = 3
+	ACCESS_REQUEST_STATUS_CANCELLED int8 = 4
+)
+
+// 权限复核活动状态：进行中的活动到截止时间或被提前结束后置为已结束
+const (
+	REVIEW_CAMPAIGN_STATUS_OPEN   int8 = 0
+	REVIEW_CAMPAIGN_STATUS_CLOSED int8 = 1
+)
+
+// 复核结论：活动结束时仍待复核的条目按收回处理，记为 expired
+const (
+	REVIEW_DECISION_PENDING int8 = 0
+	REVIEW_DECISION_KEEP    int8 = 1
+	REVIEW_DECISION_REVOKE  int8 = 2
+	REVIEW_DECISION_EXPIRED int8 = 3
+)
+
+// 直接授予单个用户的校验规则，规则的 sub(v0) 为该前缀加 userId
+const CASBIN_SUB_USER_PREFIX = "user:"
+
+// 模拟用户(以目标用户身份查看菜单和鉴权)所需的资源，需在产线下作为接口节点授予支持人员所在的权限组
+const IMPERSONATE_RESOURCE = "permission:impersonate"
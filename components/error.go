@@ -13,6 +13,10 @@ var ErrorGroupParamsInvalid = base.Error{
 	ErrNo:  7500,
 	ErrMsg: "group param invalid",
 }
+var ErrorGroupVersionConflict = base.Error{
+	ErrNo:  7501,
+	ErrMsg: "group has been modified by others, please refresh and retry: %s",
+}
 
 // 8000000-8499999 policy校验规则逻辑错误
 var ErrorPolicyParamsInvalid = base.Error{
@@ -25,6 +29,10 @@ var ErrorUserGroupParamsInvalid = base.Error{
 	ErrNo:  8500,
 	ErrMsg: "user group param invalid",
 }
+var ErrorUserGroupNotExist = base.Error{
+	ErrNo:  8501,
+	ErrMsg: "user group not exist: %s",
+}
 
 // 9000000-9299999 node节点资源逻辑错误
 var ErrorNodeParamsInvalid = base.Error{
@@ -44,6 +52,146 @@ var ErrorParamUserNotLogin = base.Error{
 	ErrMsg: "param user not login",
 }
 
+// 10000000-10099999 snapshot权限快照逻辑错误
+var ErrorSnapshotParamsInvalid = base.Error{
+	ErrNo:  10000,
+	ErrMsg: "snapshot param invalid: %s",
+}
+var ErrorSnapshotNotExist = base.Error{
+	ErrNo:  10001,
+	ErrMsg: "snapshot not exist: %s",
+}
+
+// 10100000-10199999 access权限申请逻辑错误
+var ErrorAccessRequestParamsInvalid = base.Error{
+	ErrNo:  10100,
+	ErrMsg: "access request param invalid: %s",
+}
+var ErrorAccessRequestNotExist = base.Error{
+	ErrNo:  10101,
+	ErrMsg: "access request not exist: %s",
+}
+var ErrorAccessRequestStatusInvalid = base.Error{
+	ErrNo:  10102,
+	ErrMsg: "access request status not allowed: %s",
+}
+var ErrorAccessRequestNotApprover = base.Error{
+	ErrNo:  10103,
+	ErrMsg: "not an approver of this request: %s",
+}
+
+// 10200000-10299999 dataScope数据权限逻辑错误
+var ErrorDataScopeParamsInvalid = base.Error{
+	ErrNo:  10200,
+	ErrMsg: "data scope param invalid: %s",
+}
+
+// 10300000-10399999 sod职责分离逻辑错误
+var ErrorSodParamsInvalid = base.Error{
+	ErrNo:  10300,
+	ErrMsg: "separation of duty param invalid: %s",
+}
+var ErrorSodViolation = base.Error{
+	ErrNo:  10301,
+	ErrMsg: "violates separation of duty: %s",
+}
+
+// 10400000-10499999 owner权限组负责人逻辑错误
+var ErrorOwnerParamsInvalid = base.Error{
+	ErrNo:  10400,
+	ErrMsg: "group owner param invalid: %s",
+}
+var ErrorNotGroupOwner = base.Error{
+	ErrNo:  10401,
+	ErrMsg: "not an owner of the group: %s",
+}
+var ErrorNodeNotHeld = base.Error{
+	ErrNo:  10402,
+	ErrMsg: "owner does not hold the node: %s",
+}
+var ErrorNotSuperAdmin = base.Error{
+	ErrNo:  10403,
+	ErrMsg: "super admin required: %s",
+}
+
+// 10500000-10599999 audit审计日志逻辑错误
+var ErrorAuditParamsInvalid = base.Error{
+	ErrNo:  10500,
+	ErrMsg: "audit log param invalid: %s",
+}
+var ErrorDecisionLogDisabled = base.Error{
+	ErrNo:  10501,
+	ErrMsg: "decision log not enabled: %s",
+}
+
+// 10600000-10699999 manifest路由清单逻辑错误
+var ErrorManifestParamsInvalid = base.Error{
+	ErrNo:  10600,
+	ErrMsg: "route manifest param invalid: %s",
+}
+
+// 10700000-10799999 domain按需加载逻辑错误
+var ErrorDomainNotServed = base.Error{
+	ErrNo:  10700,
+	ErrMsg: "domain not served by this instance: %s",
+}
+var ErrorDomainLoad = base.Error{
+	ErrNo:  10701,
+	ErrMsg: "load domain policy failure: %s",
+}
+
+// 10800000-10899999 casbin模型逻辑错误
+var ErrorModelParamsInvalid = base.Error{
+	ErrNo:  10800,
+	ErrMsg: "casbin model param invalid: %s",
+}
+var ErrorModelInvalid = base.Error{
+	ErrNo:  10801,
+	ErrMsg: "casbin model invalid: %s",
+}
+var ErrorModelNotActive = base.Error{
+	ErrNo:  10802,
+	ErrMsg: "casbin model not active: %s",
+}
+
+// 10900000-10999999 授权使用情况逻辑错误
+var ErrorUsageParamsInvalid = base.Error{
+	ErrNo:  10900,
+	ErrMsg: "usage report param invalid: %s",
+}
+
+// 11000000-11099999 review权限复核逻辑错误
+var ErrorReviewParamsInvalid = base.Error{
+	ErrNo:  11000,
+	ErrMsg: "review param invalid: %s",
+}
+var ErrorReviewNotExist = base.Error{
+	ErrNo:  11001,
+	ErrMsg: "review campaign or item not exist: %s",
+}
+var ErrorReviewClosed = base.Error{
+	ErrNo:  11002,
+	ErrMsg: "review campaign closed: %s",
+}
+var ErrorReviewNotReviewer = base.Error{
+	ErrNo:  11003,
+	ErrMsg: "not the reviewer of the item: %s",
+}
+var ErrorReviewDecided = base.Error{
+	ErrNo:  11004,
+	ErrMsg: "review item already decided: %s",
+}
+
+// 11100000-11199999 impersonate模拟用户逻辑错误
+var ErrorImpersonateParamsInvalid = base.Error{
+	ErrNo:  11100,
+	ErrMsg: "impersonate param invalid: %s",
+}
+var ErrorImpersonateDenied = base.Error{
+	ErrNo:  11101,
+	ErrMsg: "impersonate not allowed: %s",
+}
+
 // 6000000-6999999 oauth逻辑错误
 var ErrorOauthParamsInvalid = base.Error{
 	ErrNo:  6000,
@@ -1,6 +1,9 @@
 package conf
 
 import (
+	"fmt"
+	"time"
+
 	"permission/pkg/golib/v2/base"
 	"permission/pkg/golib/v2/env"
 	"permission/pkg/golib/v2/hbase"
@@ -25,6 +28,45 @@ type TBasic struct {
 	Log    zlog.LogConfig
 	Server http.ServerConfig
 	// ....业务可扩展其他简单的配置
+	Permission TPermission
+}
+
+// 权限系统业务配置，对应config.yaml中的permission
+type TPermission struct {
+	// 权限变更通知发送的kafka topic，为空时不发送kafka通知
+	NotifyTopic string `yaml:"notifyTopic"`
+	// 权限申请待审批的有效期，超时未审批自动过期，默认7天
+	AccessRequestTTL time.Duration `yaml:"accessRequestTTL"`
+	// 超级管理员uid，可管理任意权限组，必填；首个负责人只能由超级管理员添加，启动时校验不能为空
+	SuperAdmins []int64 `yaml:"superAdmins"`
+	// 全量加载校验规则的周期，作为增量更新之外的兜底，默认5分钟
+	PolicyReloadInterval time.Duration `yaml:"policyReloadInterval"`
+	// 本实例服务的domain(product:app)，配置后只在首次鉴权时加载这些domain的规则；为空时加载全部
+	Domains []string `yaml:"domains"`
+	// 按domain加载时，domain 超过该时长未被鉴权则卸载其规则，为0时不卸载
+	DomainIdleTTL time.Duration `yaml:"domainIdleTTL"`
+	// 授权使用情况从内存写库的周期，默认1分钟
+	UsageFlushInterval time.Duration `yaml:"usageFlushInterval"`
+	// 鉴权决策日志，esService 和 fileName 均为空时不记录
+	DecisionLog TDecisionLog `yaml:"decisionLog"`
+	// 鉴权时passport用户信息的本地缓存时长，为0时不缓存；用户禁用、类型变更最多延迟该时长生效
+	PassportCacheTTL time.Duration `yaml:"passportCacheTTL"`
+}
+
+// 鉴权决策日志配置，配置了 esService 时写入es，否则写入本地文件
+type TDecisionLog struct {
+	// 采样比例，取值0~1，为0时不记录
+	SampleRate float64 `yaml:"sampleRate"`
+	// resource.yaml 中 elastic 的 key
+	EsService string `yaml:"esService"`
+	// es索引名前缀，按天建索引，默认 permission-decision
+	Index string `yaml:"index"`
+	// 未配置es时写入日志目录下的该文件，每行一条json
+	FileName string `yaml:"fileName"`
+	// 等待写入的队列长度，队列满时丢弃，默认10000
+	QueueSize int `yaml:"queueSize"`
+	// 批量写入的间隔，默认1秒
+	FlushInterval time.Duration `yaml:"flushInterval"`
 }
 
 // 对应 api.yaml
@@ -55,4 +97,20 @@ func InitConf() {
 	// 加载资源类配置（optional）
 	env.LoadConf("resource.yaml", env.SubConfMount, &RConf)
 
+	if err := BasicConf.Permission.validate(); err != nil {
+		panic("invalid config.yaml: " + err.Error())
+	}
+}
+
+// validate 校验启动必需的业务配置
+func (p *TPermission) validate() error {
+	if len(p.SuperAdmins) == 0 {
+		return fmt.Errorf("permission.superAdmins is required, owners can only be bootstrapped by a super admin")
+	}
+	for _, uid := range p.SuperAdmins {
+		if uid <= 0 {
+			return fmt.Errorf("permission.superAdmins contains invalid uid %d", uid)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,11 @@
+package command
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/service/access"
+)
+
+// ExpireAccessRequests 处理超时未审批的申请与到期的限时授权
+func ExpireAccessRequests(ctx *gin.Context) error {
+	return access.ExpireRequests(ctx)
+}
@@ -0,0 +1,45 @@
+package command
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/consistency"
+)
+
+// ConsistencyCheck 输出权限数据中互相矛盾的记录，带 repair 参数时删除这些记录(申请单与用户规则不一致的只报告)
+// 如 ./permission consistency 或 ./permission consistency repair
+func ConsistencyCheck(ctx *gin.Context, args ...string) error {
+	issues, err := consistency.Check(ctx)
+	if err != nil {
+		zlog.Errorf(ctx, "consistency check failure, err:%v", err)
+		return err
+	}
+	counts := make(map[string]int)
+	for _, i := range issues {
+		fmt.Println(i.String())
+		counts[i.Kind]++
+	}
+	for _, kind := range []string{
+		consistency.KindOrphanRule,
+		consistency.KindStaleRule,
+		consistency.KindDuplicateRule,
+		consistency.KindDanglingBinding,
+		consistency.KindDeletedMember,
+		consistency.KindMissingGrant,
+		consistency.KindExpiredGrant,
+	} {
+		fmt.Printf("%s: %d\n", kind, counts[kind])
+	}
+	fmt.Printf("total issues: %d\n", len(issues))
+	if len(args) == 0 || args[0] != "repair" {
+		return nil
+	}
+	rows, err := consistency.Repair(ctx, issues)
+	if err != nil {
+		zlog.Errorf(ctx, "consistency repair failure, err:%v", err)
+		return err
+	}
+	fmt.Printf("repaired rows: %d\n", rows)
+	return nil
+}
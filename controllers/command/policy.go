@@ -0,0 +1,30 @@
+package command
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/conf"
+	"permission/helpers"
+	"permission/pkg/golib/v2/zlog"
+)
+
+// ReloadPolicy 周期全量加载校验规则，修正增量更新遗漏以及其他实例写入的变更
+func ReloadPolicy(ctx *gin.Context) error {
+	if err := helpers.ReloadPolicy(); err != nil {
+		zlog.Errorf(ctx, "casbin reload policy failure, err:%v", err)
+		return err
+	}
+	return nil
+}
+
+// UnloadIdleDomains 按domain加载时，卸载空闲超时的domain规则
+func UnloadIdleDomains(ctx *gin.Context) error {
+	domains, err := helpers.UnloadIdleDomains(conf.BasicConf.Permission.DomainIdleTTL)
+	if err != nil {
+		zlog.Errorf(ctx, "unload idle domains failure, err:%v", err)
+		return err
+	}
+	if len(domains) > 0 {
+		zlog.Infof(ctx, "unload idle domains: %v", domains)
+	}
+	return nil
+}
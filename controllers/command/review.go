@@ -0,0 +1,11 @@
+package command
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/service/review"
+)
+
+// ExpireReviewCampaigns 结束到期的权限复核活动，收回仍待复核的组员资格
+func ExpireReviewCampaigns(ctx *gin.Context) error {
+	return review.ExpireCampaigns(ctx)
+}
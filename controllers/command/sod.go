@@ -0,0 +1,22 @@
+package command
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/sod"
+)
+
+// SodReport 输出全部分表中违反互斥约束的组员资格
+func SodReport(ctx *gin.Context, args ...string) error {
+	violations, err := sod.Report(ctx)
+	if err != nil {
+		zlog.Errorf(ctx, "sod report failure, err:%v", err)
+		return err
+	}
+	for _, v := range violations {
+		fmt.Println(v.String())
+	}
+	fmt.Printf("total violations: %d\n", len(violations))
+	return nil
+}
@@ -0,0 +1,23 @@
+package command
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/conf"
+	"permission/service/usage"
+	"time"
+)
+
+// 默认授权使用情况写库的周期
+const defaultUsageFlushInterval = time.Minute
+
+func UsageFlushInterval() time.Duration {
+	if conf.BasicConf.Permission.UsageFlushInterval > 0 {
+		return conf.BasicConf.Permission.UsageFlushInterval
+	}
+	return defaultUsageFlushInterval
+}
+
+// FlushUsage 将鉴权路径在内存中累计的授权使用情况写库
+func FlushUsage(ctx *gin.Context) error {
+	return usage.Flush(ctx)
+}
@@ -0,0 +1,51 @@
+package access
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/access"
+)
+
+func SetApprovers(ctx *gin.Context) {
+	var params struct {
+		GroupId    int64   `json:"groupId" form:"groupId" binding:"required"`
+		UserIds    []int64 `json:"userIds" form:"userIds"`
+		OperateUid int64   `json:"operateUid" form:"operateUid" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorAccessRequestParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	approverInput := &access.AApproverInput{
+		GroupId:    params.GroupId,
+		UserIds:    params.UserIds,
+		OperateUid: params.OperateUid,
+	}
+	response, err := approverInput.SetApprovers(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
+
+func GetApproverList(ctx *gin.Context) {
+	var params struct {
+		GroupId int64 `json:"groupId" form:"groupId" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorAccessRequestParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	approverInput := &access.AApproverInput{GroupId: params.GroupId}
+	response, err := approverInput.GetApproverList(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
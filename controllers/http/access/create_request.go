@@ -0,0 +1,45 @@
+package access
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/access"
+)
+
+func CreateRequest(ctx *gin.Context) {
+	var params struct {
+		ProductId   int64  `json:"productId" form:"productId" binding:"required"`
+		AppId       int64  `json:"appId" form:"appId" binding:"required"`
+		UserType    int8   `json:"userType" form:"userType" binding:"required"`
+		UserId      int64  `json:"userId" form:"userId" binding:"required"`
+		RequestType int8   `json:"requestType" form:"requestType"` // 0:权限组 1:单个资源
+		GroupId     int64  `json:"groupId" form:"groupId"`
+		Resource    string `json:"resource" form:"resource"`
+		Reason      string `json:"reason" form:"reason" binding:"required"`
+		Duration    int64  `json:"duration" form:"duration"` // 授权时长(秒)，0表示永久
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorAccessRequestParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	createInput := &access.ACreateInput{
+		ProductId:   params.ProductId,
+		AppId:       params.AppId,
+		UserType:    params.UserType,
+		UserId:      params.UserId,
+		RequestType: params.RequestType,
+		GroupId:     params.GroupId,
+		Resource:    params.Resource,
+		Reason:      params.Reason,
+		Duration:    params.Duration,
+	}
+	response, err := createInput.CreateRequest(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
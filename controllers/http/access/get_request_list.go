@@ -0,0 +1,41 @@
+package access
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/access"
+)
+
+func GetRequestList(ctx *gin.Context) {
+	var params struct {
+		ProductId int64  `json:"productId" form:"productId" binding:"required"`
+		AppId     int64  `json:"appId" form:"appId" binding:"required"`
+		UserId    int64  `json:"userId" form:"userId"`
+		GroupId   int64  `json:"groupId" form:"groupId"`
+		Statuses  []int8 `json:"statuses" form:"statuses"`
+		PageNo    int    `json:"pageNo" form:"pageNo"`
+		PageSize  int    `json:"pageSize" form:"pageSize"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorAccessRequestParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	listInput := &access.AListInput{
+		ProductId: params.ProductId,
+		AppId:     params.AppId,
+		UserId:    params.UserId,
+		GroupId:   params.GroupId,
+		Statuses:  params.Statuses,
+		PageNo:    params.PageNo,
+		PageSize:  params.PageSize,
+	}
+	response, err := listInput.GetRequestList(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
@@ -0,0 +1,77 @@
+package access
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/access"
+)
+
+type handleParams struct {
+	RequestId int64  `json:"requestId" form:"requestId" binding:"required"`
+	HandleUid int64  `json:"handleUid" form:"handleUid" binding:"required"`
+	Remark    string `json:"remark" form:"remark"`
+}
+
+func ApproveRequest(ctx *gin.Context) {
+	var params handleParams
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorAccessRequestParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	handleInput := &access.AHandleInput{
+		RequestId: params.RequestId,
+		HandleUid: params.HandleUid,
+		Remark:    params.Remark,
+	}
+	response, err := handleInput.ApproveRequest(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
+
+func RejectRequest(ctx *gin.Context) {
+	var params handleParams
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorAccessRequestParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	handleInput := &access.AHandleInput{
+		RequestId: params.RequestId,
+		HandleUid: params.HandleUid,
+		Remark:    params.Remark,
+	}
+	response, err := handleInput.RejectRequest(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
+
+func CancelRequest(ctx *gin.Context) {
+	var params struct {
+		RequestId int64 `json:"requestId" form:"requestId" binding:"required"`
+		UserId    int64 `json:"userId" form:"userId" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorAccessRequestParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	cancelInput := &access.ACancelInput{
+		RequestId: params.RequestId,
+		UserId:    params.UserId,
+	}
+	response, err := cancelInput.CancelRequest(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
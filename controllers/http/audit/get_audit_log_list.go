@@ -0,0 +1,43 @@
+package audit
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/audit"
+)
+
+func GetAuditLogList(ctx *gin.Context) {
+	var params struct {
+		ProductId  int64  `json:"productId" form:"productId" binding:"required"`
+		AppId      int64  `json:"appId" form:"appId" binding:"required"`
+		OperateUid int64  `json:"operateUid" form:"operateUid"`
+		Action     string `json:"action" form:"action"`
+		TargetType string `json:"targetType" form:"targetType"`
+		TargetId   int64  `json:"targetId" form:"targetId"`
+		PageNo     int    `json:"pageNo" form:"pageNo"`
+		PageSize   int    `json:"pageSize" form:"pageSize"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorAuditParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	listInput := &audit.AListInput{
+		ProductId:  params.ProductId,
+		AppId:      params.AppId,
+		OperateUid: params.OperateUid,
+		Action:     params.Action,
+		TargetType: params.TargetType,
+		TargetId:   params.TargetId,
+		PageNo:     params.PageNo,
+		PageSize:   params.PageSize,
+	}
+	response, err := listInput.GetAuditLogList(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
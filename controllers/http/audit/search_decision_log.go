@@ -0,0 +1,43 @@
+package audit
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/audit"
+)
+
+func SearchDecisionLog(ctx *gin.Context) {
+	var params struct {
+		StartTime int64  `json:"startTime" form:"startTime" binding:"required"`
+		EndTime   int64  `json:"endTime" form:"endTime"`
+		UserId    int64  `json:"userId" form:"userId"`
+		ProductId int64  `json:"productId" form:"productId"`
+		AppId     int64  `json:"appId" form:"appId"`
+		Resource  string `json:"resource" form:"resource"`
+		Result    string `json:"result" form:"result"`
+		Size      int    `json:"size" form:"size"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorAuditParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	searchInput := &audit.ADecisionInput{
+		StartTime: params.StartTime,
+		EndTime:   params.EndTime,
+		UserId:    params.UserId,
+		ProductId: params.ProductId,
+		AppId:     params.AppId,
+		Resource:  params.Resource,
+		Result:    params.Result,
+		Size:      params.Size,
+	}
+	response, err := searchInput.SearchDecisionLog(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
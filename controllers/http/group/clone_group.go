@@ -0,0 +1,39 @@
+package group
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/group"
+)
+
+func CloneGroup(ctx *gin.Context) {
+	var params struct {
+		GroupId     int64             `json:"groupId" form:"groupId" binding:"required"`
+		ProductId   int64             `json:"productId" form:"productId"`
+		AppId       int64             `json:"appId" form:"appId"`
+		GroupName   string            `json:"groupName" form:"groupName" binding:"required"`
+		UserId      int64             `json:"userId" form:"userId" binding:"required"`
+		ResourceMap map[string]string `json:"resourceMap" form:"resourceMap"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorGroupParamsInvalid)
+		return
+	}
+	cloneInput := &group.GCloneInput{
+		GroupId:     params.GroupId,
+		ProductId:   params.ProductId,
+		AppId:       params.AppId,
+		GroupName:   params.GroupName,
+		UserId:      params.UserId,
+		ResourceMap: params.ResourceMap,
+	}
+	response, err := cloneInput.CloneGroup(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
@@ -0,0 +1,31 @@
+package group
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/group"
+)
+
+func DiffGroup(ctx *gin.Context) {
+	var params struct {
+		GroupId      int64 `json:"groupId" form:"groupId" binding:"required"`
+		OtherGroupId int64 `json:"otherGroupId" form:"otherGroupId" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorGroupParamsInvalid)
+		return
+	}
+	diffInput := &group.GDiffInput{
+		GroupId:      params.GroupId,
+		OtherGroupId: params.OtherGroupId,
+	}
+	response, err := diffInput.DiffGroup(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
@@ -0,0 +1,35 @@
+package group
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/group"
+)
+
+func SetDataScope(ctx *gin.Context) {
+	var params struct {
+		GroupId   int64  `json:"groupId" form:"groupId" binding:"required"`
+		NodeId    int64  `json:"nodeId" form:"nodeId" binding:"required"`
+		DataScope string `json:"dataScope" form:"dataScope"` // 如 {"region":["north"]}，为空表示不限制
+		UserId    int64  `json:"userId" form:"userId" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorDataScopeParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	scopeInput := &group.DataScopeInput{
+		GroupId:   params.GroupId,
+		NodeId:    params.NodeId,
+		DataScope: params.DataScope,
+		UserId:    params.UserId,
+	}
+	response, err := scopeInput.SetDataScope(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
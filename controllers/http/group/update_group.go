@@ -16,8 +16,9 @@ func Updategroup(ctx *gin.Context) {
 		UserId      int64   `json:"userId" form:"userId" binding:"required"`
 		GroupName   string  `json:"groupName" form:"groupName" binding:"required"`
 		MenuList    []int64 `json:"menuList" form:"menuList" binding:"required"`
-		NodeList    []int64 `json:"nodeList" form:"nodeList" binding:"required"`
+		NodeList    []int64 `json:"nodeList" form:"nodeList" binding:"required"` // 直接授予的接口
 		GroupStatus int8    `json:"groupStatus" form:"groupStatus"`
+		Version     int64   `json:"version" form:"version" binding:"required,min=1"`
 	}
 	if err := ctx.BindJSON(&params); err != nil {
 		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
@@ -33,6 +34,7 @@ func Updategroup(ctx *gin.Context) {
 		GroupStatus: params.GroupStatus,
 		NodeList:    params.NodeList,
 		MenuList:    params.MenuList,
+		Version:     params.Version,
 	}
 	response, err := groupInput.UpdateGroup(ctx)
 	if err != nil {
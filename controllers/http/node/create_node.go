@@ -18,6 +18,9 @@ func CreateNode(ctx *gin.Context) {
 		NodeType  int8   `json:"nodeType" form:"nodeType"`
 		UserId    int64  `json:"userId" form:"userId" binding:"required"`
 		ParentId  int64  `json:"parentId" form:"parentId"`
+		SortOrder int    `json:"sortOrder" form:"sortOrder"`
+		Icon      string `json:"icon" form:"icon"`
+		Route     string `json:"route" form:"route"`
 	}
 	if err := ctx.BindJSON(&params); err != nil {
 		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
@@ -33,6 +36,9 @@ func CreateNode(ctx *gin.Context) {
 		ParentId:  params.ParentId,
 		UserId:    params.UserId,
 		NodeType:  params.NodeType,
+		SortOrder: params.SortOrder,
+		Icon:      params.Icon,
+		Route:     params.Route,
 	}
 	response, err := nodeInput.CreateNode(ctx)
 	if err != nil {
@@ -0,0 +1,51 @@
+package node
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/node"
+)
+
+func SetDependency(ctx *gin.Context) {
+	var params struct {
+		NodeId     int64   `json:"nodeId" form:"nodeId" binding:"required"`
+		ApiNodeIds []int64 `json:"apiNodeIds" form:"apiNodeIds"`
+		UserId     int64   `json:"userId" form:"userId" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorNodeParamsInvalid)
+		return
+	}
+	dependencyInput := &node.NDependencyInput{
+		NodeId:     params.NodeId,
+		ApiNodeIds: params.ApiNodeIds,
+		UserId:     params.UserId,
+	}
+	response, err := dependencyInput.SetDependencies(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
+
+func GetDependency(ctx *gin.Context) {
+	var params struct {
+		NodeId int64 `json:"nodeId" form:"nodeId" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorNodeParamsInvalid)
+		return
+	}
+	dependencyInput := &node.NDependencyInput{NodeId: params.NodeId}
+	response, err := dependencyInput.GetDependencies(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
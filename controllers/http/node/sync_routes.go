@@ -0,0 +1,41 @@
+package node
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/node"
+)
+
+func SyncRoutes(ctx *gin.Context) {
+	var params struct {
+		ProductId int64        `json:"productId" form:"productId" binding:"required"`
+		AppId     int64        `json:"appId" form:"appId" binding:"required"`
+		ParentId  int64        `json:"parentId" form:"parentId"`
+		UserId    int64        `json:"userId" form:"userId" binding:"required"`
+		Routes    []node.Route `json:"routes" form:"routes"`
+		OpenApi   string       `json:"openApi" form:"openApi"`
+		DryRun    bool         `json:"dryRun" form:"dryRun"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorManifestParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	syncInput := &node.NSyncInput{
+		ProductId: params.ProductId,
+		AppId:     params.AppId,
+		ParentId:  params.ParentId,
+		UserId:    params.UserId,
+		Routes:    params.Routes,
+		OpenApi:   params.OpenApi,
+		DryRun:    params.DryRun,
+	}
+	response, err := syncInput.SyncRoutes(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
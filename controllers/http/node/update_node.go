@@ -10,11 +10,14 @@ import (
 
 func UpdateNode(ctx *gin.Context) {
 	var params struct {
-		Id       int64  `json:"id" form:"id" binding:"required"`
-		Label    string `json:"label" form:"label" binding:"required"`
-		Resource string `json:"resource" form:"resource" binding:"required"`
-		ParentId int64  `json:"parentId" form:"parentId"`
-		UserId   int64  `json:"userId" form:"userId" binding:"required"`
+		Id        int64  `json:"id" form:"id" binding:"required"`
+		Label     string `json:"label" form:"label" binding:"required"`
+		Resource  string `json:"resource" form:"resource" binding:"required"`
+		ParentId  int64  `json:"parentId" form:"parentId"`
+		SortOrder int    `json:"sortOrder" form:"sortOrder"`
+		Icon      string `json:"icon" form:"icon"`
+		Route     string `json:"route" form:"route"`
+		UserId    int64  `json:"userId" form:"userId" binding:"required"`
 	}
 	if err := ctx.BindJSON(&params); err != nil {
 		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
@@ -22,11 +25,14 @@ func UpdateNode(ctx *gin.Context) {
 		return
 	}
 	nodeInput := &node.NUpdateInput{
-		Id:       params.Id,
-		Label:    params.Label,
-		Resource: params.Resource,
-		ParentId: params.ParentId,
-		UserId:   params.UserId,
+		Id:        params.Id,
+		Label:     params.Label,
+		Resource:  params.Resource,
+		ParentId:  params.ParentId,
+		UserId:    params.UserId,
+		SortOrder: params.SortOrder,
+		Icon:      params.Icon,
+		Route:     params.Route,
 	}
 	response, err := nodeInput.UpdateNode(ctx)
 	if err != nil {
@@ -0,0 +1,73 @@
+package owner
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/owner"
+)
+
+type ownerParams struct {
+	GroupId    int64 `json:"groupId" form:"groupId" binding:"required"`
+	UserId     int64 `json:"userId" form:"userId" binding:"required"`
+	OperateUid int64 `json:"operateUid" form:"operateUid" binding:"required"`
+}
+
+func AddOwner(ctx *gin.Context) {
+	var params ownerParams
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorOwnerParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	ownerInput := &owner.OwnerInput{
+		GroupId:    params.GroupId,
+		UserId:     params.UserId,
+		OperateUid: params.OperateUid,
+	}
+	response, err := ownerInput.AddOwner(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
+
+func RemoveOwner(ctx *gin.Context) {
+	var params ownerParams
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorOwnerParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	ownerInput := &owner.OwnerInput{
+		GroupId:    params.GroupId,
+		UserId:     params.UserId,
+		OperateUid: params.OperateUid,
+	}
+	response, err := ownerInput.RemoveOwner(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
+
+func GetOwnerList(ctx *gin.Context) {
+	var params struct {
+		GroupId int64 `json:"groupId" form:"groupId" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorOwnerParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	ownerInput := &owner.OwnerInput{GroupId: params.GroupId}
+	response, err := ownerInput.GetOwnerList(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
@@ -10,10 +10,14 @@ import (
 
 func CheckPermission(ctx *gin.Context) {
 	var params struct {
-		ProductId int64  `json:"productId" form:"productId" binding:"required"`
-		AppId     int64  `json:"appId" form:"appId" binding:"required"`
-		UserId    int64  `json:"userId" form:"userId" binding:"required"`
-		Resource  string `json:"resource" form:"resource" binding:"required"`
+		ProductId     int64             `json:"productId" form:"productId" binding:"required"`
+		AppId         int64             `json:"appId" form:"appId" binding:"required"`
+		UserId        int64             `json:"userId" form:"userId" binding:"required"`
+		Resource      string            `json:"resource" form:"resource" binding:"required"`
+		ClientIp      string            `json:"clientIp" form:"clientIp"`           // 终端用户ip，用于ip段条件
+		Attrs         map[string]string `json:"attrs" form:"attrs"`                 // 自定义属性，用于属性条件
+		WithDataScope bool              `json:"withDataScope" form:"withDataScope"` // 为 true 时附带数据范围
+		Impersonator  int64             `json:"impersonator" form:"impersonator"`   // 模拟 userId 鉴权的管理员uid
 	}
 	if err := ctx.BindJSON(&params); err != nil {
 		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
@@ -21,10 +25,14 @@ func CheckPermission(ctx *gin.Context) {
 		return
 	}
 	checkInput := &perm.CheckInput{
-		ProductId: params.ProductId,
-		AppId:     params.AppId,
-		UserId:    params.UserId,
-		Resource:  params.Resource,
+		ProductId:     params.ProductId,
+		AppId:         params.AppId,
+		UserId:        params.UserId,
+		Resource:      params.Resource,
+		ClientIp:      params.ClientIp,
+		Attrs:         params.Attrs,
+		WithDataScope: params.WithDataScope,
+		Impersonator:  params.Impersonator,
 	}
 	response, err := checkInput.CheckPermission(ctx)
 	if err != nil {
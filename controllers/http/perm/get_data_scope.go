@@ -0,0 +1,41 @@
+package perm
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/perm"
+)
+
+func GetDataScope(ctx *gin.Context) {
+	var params struct {
+		ProductId    int64             `json:"productId" form:"productId" binding:"required"`
+		AppId        int64             `json:"appId" form:"appId" binding:"required"`
+		UserId       int64             `json:"userId" form:"userId" binding:"required"`
+		Resource     string            `json:"resource" form:"resource" binding:"required"`
+		ClientIp     string            `json:"clientIp" form:"clientIp"`         // 终端用户ip，用于ip段条件
+		Attrs        map[string]string `json:"attrs" form:"attrs"`               // 自定义属性，用于属性条件
+		Impersonator int64             `json:"impersonator" form:"impersonator"` // 模拟 userId 查询的管理员uid
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorDataScopeParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	scopeInput := &perm.DataScopeInput{
+		ProductId:    params.ProductId,
+		AppId:        params.AppId,
+		UserId:       params.UserId,
+		Resource:     params.Resource,
+		ClientIp:     params.ClientIp,
+		Attrs:        params.Attrs,
+		Impersonator: params.Impersonator,
+	}
+	response, err := scopeInput.GetDataScope(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
@@ -0,0 +1,36 @@
+package policy
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/policy"
+)
+
+func ActivateModel(ctx *gin.Context) {
+	var params struct {
+		Name       string              `json:"name" form:"name" binding:"required"`
+		Policies   [][]string          `json:"policies" form:"policies"`
+		Cases      []helpers.ModelCase `json:"cases" form:"cases"`
+		OperateUid int64               `json:"operateUid" form:"operateUid" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorModelParamsInvalid)
+		return
+	}
+	activateInput := &policy.MActivateInput{
+		Name:       params.Name,
+		Policies:   params.Policies,
+		Cases:      params.Cases,
+		OperateUid: params.OperateUid,
+	}
+	response, err := activateInput.ActivateModel(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
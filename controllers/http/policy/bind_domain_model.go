@@ -0,0 +1,35 @@
+package policy
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/policy"
+)
+
+func BindDomainModel(ctx *gin.Context) {
+	var params struct {
+		ProductId  int64  `json:"productId" form:"productId" binding:"required"`
+		AppId      int64  `json:"appId" form:"appId" binding:"required"`
+		ModelName  string `json:"modelName" form:"modelName" binding:"required"`
+		OperateUid int64  `json:"operateUid" form:"operateUid" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorModelParamsInvalid)
+		return
+	}
+	bindInput := &policy.MBindInput{
+		ProductId:  params.ProductId,
+		AppId:      params.AppId,
+		ModelName:  params.ModelName,
+		OperateUid: params.OperateUid,
+	}
+	response, err := bindInput.BindDomainModel(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
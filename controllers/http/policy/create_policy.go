@@ -15,6 +15,7 @@ func CreatePolicy(ctx *gin.Context) {
 		ProductId      int64  `json:"productId" form:"productId" binding:"required"`
 		Resource       string `json:"resource" form:"resource" binding:"required"`
 		PermissionType string `json:"permissionType" form:"permissionType" binding:"required"`
+		Condition      string `json:"condition" form:"condition"` // 附加条件json，如 {"ipRanges":["10.0.0.0/8"],"weekdays":[1,2,3,4,5]}
 	}
 	if err := ctx.BindJSON(&params); err != nil {
 		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
@@ -27,6 +28,7 @@ func CreatePolicy(ctx *gin.Context) {
 		ProductId:      params.ProductId,
 		Resource:       params.Resource,
 		PermissionType: params.PermissionType,
+		Condition:      params.Condition,
 	}
 	response, err := policyInput.CreatePolicy(ctx)
 	if err != nil {
@@ -0,0 +1,16 @@
+package policy
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/pkg/golib/v2/base"
+	"permission/service/policy"
+)
+
+func GetModelList(ctx *gin.Context) {
+	response, err := policy.GetModelList(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
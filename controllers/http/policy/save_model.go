@@ -0,0 +1,35 @@
+package policy
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/policy"
+)
+
+func SaveModel(ctx *gin.Context) {
+	var params struct {
+		Name       string `json:"name" form:"name" binding:"required"`
+		Text       string `json:"text" form:"text" binding:"required"`
+		Remark     string `json:"remark" form:"remark"`
+		OperateUid int64  `json:"operateUid" form:"operateUid" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorModelParamsInvalid)
+		return
+	}
+	saveInput := &policy.MSaveInput{
+		Name:       params.Name,
+		Text:       params.Text,
+		Remark:     params.Remark,
+		OperateUid: params.OperateUid,
+	}
+	response, err := saveInput.SaveModel(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
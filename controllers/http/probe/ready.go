@@ -2,23 +2,33 @@ package probe
 
 import (
 	"github.com/gin-gonic/gin"
+	"net/http"
 	"permission/pkg/golib/v2/zlog"
+	"permission/service/probe"
 )
 
 /*
-	Kubernetes使用就绪性探针（readiness probes）来实现探测服务是否准备好接收流量
+Kubernetes使用就绪性探针（readiness probes）来实现探测服务是否准备好接收流量，
+使用存活探针（liveness probes）判断容器是否需要重启。
 
-	golib中默认 ready探针，业务可以根据具体使用场景实现自己的探针，
-	在 Bootstrap 前通过 base.RegReadyProbe(probe.Ready) 注册探针即可。
+在 Bootstrap 前通过 base.RegReadyProbe(probe.Ready)、base.RegHealthProbe(probe.Health) 注册探针，
+返回各依赖的检查明细，任一依赖不可用时返回 503。
 */
 func Ready(ctx *gin.Context) {
-	// 不打印本接口的日志，根据自己需求是否开启。
+	// 不打印本接口的日志
 	zlog.SetNoLogFlag(ctx)
-	// 业务逻辑
+	render(ctx, probe.Ready(ctx))
+}
 
-	// 业务逻辑判断没有问题返回：
-	ctx.String(200, "success")
+func Health(ctx *gin.Context) {
+	zlog.SetNoLogFlag(ctx)
+	render(ctx, probe.Health(ctx))
+}
 
-	// 如果业务逻辑判断资源未就绪，那么返回：
-	// c.String(500, "fail")
+func render(ctx *gin.Context, report probe.Report) {
+	code := http.StatusOK
+	if report.Status != probe.StatusOk {
+		code = http.StatusServiceUnavailable
+	}
+	ctx.JSON(code, report)
 }
@@ -0,0 +1,31 @@
+package review
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/review"
+)
+
+func CloseCampaign(ctx *gin.Context) {
+	var params struct {
+		CampaignId int64 `json:"campaignId" form:"campaignId" binding:"required"`
+		OperateUid int64 `json:"operateUid" form:"operateUid" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorReviewParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	closeInput := &review.CCloseInput{
+		CampaignId: params.CampaignId,
+		OperateUid: params.OperateUid,
+	}
+	response, err := closeInput.CloseCampaign(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
@@ -0,0 +1,39 @@
+package review
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/review"
+)
+
+func CreateCampaign(ctx *gin.Context) {
+	var params struct {
+		ProductId  int64   `json:"productId" form:"productId" binding:"required"`
+		AppId      int64   `json:"appId" form:"appId" binding:"required"`
+		Name       string  `json:"name" form:"name" binding:"required"`
+		GroupIds   []int64 `json:"groupIds" form:"groupIds" binding:"required"`
+		Deadline   int64   `json:"deadline" form:"deadline" binding:"required"`
+		OperateUid int64   `json:"operateUid" form:"operateUid" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorReviewParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	createInput := &review.CCreateInput{
+		ProductId:  params.ProductId,
+		AppId:      params.AppId,
+		Name:       params.Name,
+		GroupIds:   params.GroupIds,
+		Deadline:   params.Deadline,
+		OperateUid: params.OperateUid,
+	}
+	response, err := createInput.CreateCampaign(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
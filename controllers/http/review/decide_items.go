@@ -0,0 +1,37 @@
+package review
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/review"
+)
+
+func DecideItems(ctx *gin.Context) {
+	var params struct {
+		CampaignId int64   `json:"campaignId" form:"campaignId" binding:"required"`
+		ItemIds    []int64 `json:"itemIds" form:"itemIds" binding:"required"`
+		Decision   int8    `json:"decision" form:"decision" binding:"required"`
+		Remark     string  `json:"remark" form:"remark"`
+		OperateUid int64   `json:"operateUid" form:"operateUid" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorReviewParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	decideInput := &review.CDecideInput{
+		CampaignId: params.CampaignId,
+		ItemIds:    params.ItemIds,
+		Decision:   params.Decision,
+		Remark:     params.Remark,
+		OperateUid: params.OperateUid,
+	}
+	response, err := decideInput.DecideItems(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
@@ -0,0 +1,37 @@
+package review
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/review"
+)
+
+func GetCampaignList(ctx *gin.Context) {
+	var params struct {
+		ProductId int64  `json:"productId" form:"productId" binding:"required"`
+		AppId     int64  `json:"appId" form:"appId" binding:"required"`
+		Statuses  []int8 `json:"statuses" form:"statuses"`
+		PageNo    int    `json:"pageNo" form:"pageNo"`
+		PageSize  int    `json:"pageSize" form:"pageSize"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorReviewParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	listInput := &review.CListInput{
+		ProductId: params.ProductId,
+		AppId:     params.AppId,
+		Statuses:  params.Statuses,
+		PageNo:    params.PageNo,
+		PageSize:  params.PageSize,
+	}
+	response, err := listInput.GetCampaignList(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
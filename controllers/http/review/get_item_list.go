@@ -0,0 +1,39 @@
+package review
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/review"
+)
+
+func GetItemList(ctx *gin.Context) {
+	var params struct {
+		CampaignId  int64  `json:"campaignId" form:"campaignId" binding:"required"`
+		GroupId     int64  `json:"groupId" form:"groupId"`
+		ReviewerUid int64  `json:"reviewerUid" form:"reviewerUid"`
+		Decisions   []int8 `json:"decisions" form:"decisions"`
+		PageNo      int    `json:"pageNo" form:"pageNo"`
+		PageSize    int    `json:"pageSize" form:"pageSize"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorReviewParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	listInput := &review.CItemListInput{
+		CampaignId:  params.CampaignId,
+		GroupId:     params.GroupId,
+		ReviewerUid: params.ReviewerUid,
+		Decisions:   params.Decisions,
+		PageNo:      params.PageNo,
+		PageSize:    params.PageSize,
+	}
+	response, err := listInput.GetItemList(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
@@ -0,0 +1,39 @@
+package review
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/review"
+)
+
+func GetReport(ctx *gin.Context) {
+	var params struct {
+		CampaignId int64  `json:"campaignId" form:"campaignId" binding:"required"`
+		Format     string `json:"format" form:"format"` // csv 时以附件下载，默认json
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorReviewParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	reportInput := &review.CReportInput{
+		CampaignId: params.CampaignId,
+	}
+	response, err := reportInput.GetReport(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+		return
+	}
+	if params.Format != "csv" {
+		base.RenderJsonSucc(ctx, response)
+		return
+	}
+	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=review-%d.csv", params.CampaignId))
+	if err := response.WriteCsv(ctx.Writer); err != nil {
+		zlog.Warnf(ctx, "write review report csv failure err:%v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package snapshot
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/snapshot"
+)
+
+func CreateSnapshot(ctx *gin.Context) {
+	var params struct {
+		ProductId int64  `json:"productId" form:"productId" binding:"required"`
+		AppId     int64  `json:"appId" form:"appId" binding:"required"`
+		UserId    int64  `json:"userId" form:"userId" binding:"required"`
+		Reason    string `json:"reason" form:"reason"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorSnapshotParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	snapshotInput := &snapshot.SCreateInput{
+		ProductId: params.ProductId,
+		AppId:     params.AppId,
+		UserId:    params.UserId,
+		Reason:    params.Reason,
+	}
+	response, err := snapshotInput.CreateSnapshot(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
@@ -0,0 +1,35 @@
+package snapshot
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/snapshot"
+)
+
+func DiffSnapshot(ctx *gin.Context) {
+	var params struct {
+		ProductId int64 `json:"productId" form:"productId" binding:"required"`
+		AppId     int64 `json:"appId" form:"appId" binding:"required"`
+		FromId    int64 `json:"fromId" form:"fromId"` // 0 表示当前线上数据
+		ToId      int64 `json:"toId" form:"toId"`     // 0 表示当前线上数据
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorSnapshotParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	diffInput := &snapshot.SDiffInput{
+		ProductId: params.ProductId,
+		AppId:     params.AppId,
+		FromId:    params.FromId,
+		ToId:      params.ToId,
+	}
+	response, err := diffInput.DiffSnapshot(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
@@ -0,0 +1,35 @@
+package snapshot
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/snapshot"
+)
+
+func GetSnapshotList(ctx *gin.Context) {
+	var params struct {
+		ProductId int64 `json:"productId" form:"productId" binding:"required"`
+		AppId     int64 `json:"appId" form:"appId" binding:"required"`
+		PageNo    int   `json:"pageNo" form:"pageNo"`
+		PageSize  int   `json:"pageSize" form:"pageSize"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorSnapshotParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	listInput := &snapshot.SListInput{
+		ProductId: params.ProductId,
+		AppId:     params.AppId,
+		PageNo:    params.PageNo,
+		PageSize:  params.PageSize,
+	}
+	response, err := listInput.GetSnapshotList(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
@@ -0,0 +1,79 @@
+package sod
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/sod"
+)
+
+func CreateExclusion(ctx *gin.Context) {
+	var params struct {
+		ProductId int64  `json:"productId" form:"productId" binding:"required"`
+		AppId     int64  `json:"appId" form:"appId" binding:"required"`
+		GroupIdA  int64  `json:"groupIdA" form:"groupIdA" binding:"required"`
+		GroupIdB  int64  `json:"groupIdB" form:"groupIdB" binding:"required"`
+		Reason    string `json:"reason" form:"reason"`
+		UserId    int64  `json:"userId" form:"userId" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorSodParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	createInput := &sod.SCreateInput{
+		ProductId: params.ProductId,
+		AppId:     params.AppId,
+		GroupIdA:  params.GroupIdA,
+		GroupIdB:  params.GroupIdB,
+		Reason:    params.Reason,
+		UserId:    params.UserId,
+	}
+	response, err := createInput.CreateExclusion(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
+
+func DeleteExclusion(ctx *gin.Context) {
+	var params struct {
+		Id int64 `json:"id" form:"id" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorSodParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	deleteInput := &sod.SDeleteInput{Id: params.Id}
+	response, err := deleteInput.DeleteExclusion(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
+
+func GetExclusionList(ctx *gin.Context) {
+	var params struct {
+		ProductId int64 `json:"productId" form:"productId" binding:"required"`
+		AppId     int64 `json:"appId" form:"appId" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorSodParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	listInput := &sod.SListInput{
+		ProductId: params.ProductId,
+		AppId:     params.AppId,
+	}
+	response, err := listInput.GetExclusionList(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
@@ -0,0 +1,35 @@
+package usage
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/usage"
+)
+
+func GetUnusedReport(ctx *gin.Context) {
+	var params struct {
+		ProductId int64 `json:"productId" form:"productId" binding:"required"`
+		AppId     int64 `json:"appId" form:"appId" binding:"required"`
+		GroupId   int64 `json:"groupId" form:"groupId"`
+		Days      int   `json:"days" form:"days" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorUsageParamsInvalid.Sprintf(err.Error()))
+		return
+	}
+	reportInput := &usage.UReportInput{
+		ProductId: params.ProductId,
+		AppId:     params.AppId,
+		GroupId:   params.GroupId,
+		Days:      params.Days,
+	}
+	response, err := reportInput.GetUnusedReport(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
@@ -0,0 +1,39 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/user"
+)
+
+func CopyUser(ctx *gin.Context) {
+	var params struct {
+		SourceUserId int64    `json:"sourceUserId" form:"sourceUserId" binding:"required"`
+		TargetUserId int64    `json:"targetUserId" form:"targetUserId" binding:"required"`
+		OperateUid   int64    `json:"operateUid" form:"operateUid" binding:"required"`
+		Domains      []string `json:"domains" form:"domains"`
+		ExpireTime   int64    `json:"expireTime" form:"expireTime"`
+		Preview      bool     `json:"preview" form:"preview"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorUserGroupParamsInvalid)
+		return
+	}
+	copyInput := &user.RCopyInput{
+		SourceUserId: params.SourceUserId,
+		TargetUserId: params.TargetUserId,
+		OperateUid:   params.OperateUid,
+		Domains:      params.Domains,
+		ExpireTime:   params.ExpireTime,
+		Preview:      params.Preview,
+	}
+	response, err := copyInput.CopyUser(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
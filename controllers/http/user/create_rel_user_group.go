@@ -16,6 +16,8 @@ func CreateRelUserGroup(ctx *gin.Context) {
 		UserId     int64 `json:"userId" form:"userId" binding:"required"` // 通过uid来添加
 		GroupId    int64 `json:"groupId" form:"groupId" binding:"required"`
 		Status     int8  `json:"status" form:"status"`
+		ExpireTime int64 `json:"expireTime" form:"expireTime"` // 到期时间戳，0表示永久
+		Append     bool  `json:"append" form:"append"`         // 为 true 时加入该权限组并保留已有的组员资格，默认设置(替换)用户的权限组
 		OperateUid int64 `json:"operateUid" form:"operateUid" binding:"required"`
 	}
 	if err := ctx.BindJSON(&params); err != nil {
@@ -30,6 +32,8 @@ func CreateRelUserGroup(ctx *gin.Context) {
 		UserId:     params.UserId,
 		GroupId:    params.GroupId,
 		Status:     params.Status,
+		ExpireTime: params.ExpireTime,
+		Append:     params.Append,
 		OperateUid: params.OperateUid,
 	}
 	response, err := userGroupInput.CreateUserGroup(ctx)
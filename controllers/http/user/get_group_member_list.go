@@ -0,0 +1,33 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/user"
+)
+
+func GetGroupMemberList(ctx *gin.Context) {
+	var params struct {
+		GroupId  int64 `json:"groupId" form:"groupId" binding:"required"`
+		PageNo   int   `json:"pageNo" form:"pageNo"`
+		PageSize int   `json:"pageSize" form:"pageSize"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorUserGroupParamsInvalid)
+		return
+	}
+	listInput := &user.RListInput{
+		GroupId:  params.GroupId,
+		PageNo:   params.PageNo,
+		PageSize: params.PageSize,
+	}
+	response, err := listInput.GetGroupMemberList(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
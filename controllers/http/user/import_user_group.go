@@ -0,0 +1,35 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/user"
+)
+
+func ImportUserGroup(ctx *gin.Context) {
+	var params struct {
+		Rows       []user.ImportRow `json:"rows" form:"rows"`
+		Csv        string           `json:"csv" form:"csv"`
+		OperateUid int64            `json:"operateUid" form:"operateUid" binding:"required"`
+		DryRun     bool             `json:"dryRun" form:"dryRun"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorUserGroupParamsInvalid)
+		return
+	}
+	importInput := &user.RImportInput{
+		Rows:       params.Rows,
+		Csv:        params.Csv,
+		OperateUid: params.OperateUid,
+		DryRun:     params.DryRun,
+	}
+	response, err := importInput.ImportUserGroup(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
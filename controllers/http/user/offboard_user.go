@@ -0,0 +1,31 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/user"
+)
+
+func OffboardUser(ctx *gin.Context) {
+	var params struct {
+		UserId     int64 `json:"userId" form:"userId" binding:"required"`
+		OperateUid int64 `json:"operateUid" form:"operateUid" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorUserGroupParamsInvalid)
+		return
+	}
+	offboardInput := &user.ROffboardInput{
+		UserId:     params.UserId,
+		OperateUid: params.OperateUid,
+	}
+	response, err := offboardInput.OffboardUser(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
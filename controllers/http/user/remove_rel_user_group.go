@@ -0,0 +1,39 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/user"
+)
+
+func RemoveRelUserGroup(ctx *gin.Context) {
+	var params struct {
+		ProductId  int64 `json:"productId" form:"productId" binding:"required"`
+		AppId      int64 `json:"appId" form:"appId" binding:"required"`
+		UserType   int8  `json:"userType" form:"userType"`
+		UserId     int64 `json:"userId" form:"userId" binding:"required"`
+		GroupId    int64 `json:"groupId" form:"groupId" binding:"required"`
+		OperateUid int64 `json:"operateUid" form:"operateUid" binding:"required"`
+	}
+	if err := ctx.BindJSON(&params); err != nil {
+		zlog.Warnf(ctx, "json params reflection failure err:%v", err)
+		base.RenderJsonFail(ctx, components.ErrorUserGroupParamsInvalid)
+		return
+	}
+	removeInput := &user.RRemoveInput{
+		ProductId:  params.ProductId,
+		AppId:      params.AppId,
+		UserType:   params.UserType,
+		UserId:     params.UserId,
+		GroupId:    params.GroupId,
+		OperateUid: params.OperateUid,
+	}
+	response, err := removeInput.RemoveUserGroup(ctx)
+	if err != nil {
+		base.RenderJsonFail(ctx, err)
+	} else {
+		base.RenderJsonSucc(ctx, response)
+	}
+}
@@ -0,0 +1,14 @@
+package helpers
+
+import (
+	"time"
+
+	"permission/pkg/golib/v2/gcache"
+)
+
+// 进程内本地缓存，用于缓存鉴权链路上变化不频繁的下游数据（如passport用户信息）
+var LocalCache *gcache.BucketCache
+
+func InitLocalCache() {
+	LocalCache = gcache.NewBucketCache(time.Minute, 5*time.Minute, 16)
+}
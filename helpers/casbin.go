@@ -1,14 +1,22 @@
 package helpers
 
 import (
+	"errors"
+	"sync"
+	"time"
+
 	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
 	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"gorm.io/gorm"
+	"permission/conf"
 )
 
 const modelPolicyAddr = "conf/rbac_model.conf"
 
 var (
-	Enforcer *casbin.Enforcer
+	Enforcer *casbin.SyncedEnforcer
 	Adapter  *gormadapter.Adapter
 )
 
@@ -23,9 +31,323 @@ type CasbinRule struct {
 	V5    string `gorm:"v5" default:""`
 }
 
+const casbinRuleTable = "tb_permission_casbin_rule"
+
+// policyAdapter gorm adapter 加载规则时会截掉末尾的空字段，
+// 没有附加条件(v5为空)的规则会因字段数与 policy_definition 不一致而加载失败，这里按定义补齐
+type policyAdapter struct {
+	*gormadapter.Adapter
+}
+
+func (a *policyAdapter) LoadPolicy(m model.Model) error {
+	// 按domain加载时，全量加载只刷新当前已加载的domain
+	if domains, ok := loadedDomainList(); ok {
+		return a.LoadFilteredPolicy(m, gormadapter.Filter{V1: domains})
+	}
+	return a.loadLines(m, MysqlClientPermission.Table(casbinRuleTable))
+}
+
+// LoadFilteredPolicy 按 gormadapter.Filter 加载规则，目前只支持按 V1(domain) 过滤
+func (a *policyAdapter) LoadFilteredPolicy(m model.Model, filter interface{}) error {
+	f, ok := filter.(gormadapter.Filter)
+	if !ok {
+		return errors.New("invalid filter type, want gormadapter.Filter")
+	}
+	if len(f.V1) == 0 {
+		return nil
+	}
+	return a.loadLines(m, MysqlClientPermission.Table(casbinRuleTable).Where("v1 IN ?", f.V1))
+}
+
+func (a *policyAdapter) loadLines(m model.Model, db *gorm.DB) error {
+	var lines []CasbinRule
+	if err := db.Order("id").Find(&lines).Error; err != nil {
+		return err
+	}
+	for _, line := range lines {
+		rule := []string{line.Ptype, line.V0, line.V1, line.V2, line.V3, line.V4, line.V5}
+		assertion, ok := m[line.Ptype[:1]][line.Ptype]
+		if !ok {
+			continue
+		}
+		if n := len(assertion.Tokens) + 1; n < len(rule) {
+			rule = rule[:n]
+		}
+		if err := persist.LoadPolicyArray(rule, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func InitCasbin() {
-	Adapter, _ = gormadapter.NewAdapterByDBWithCustomTable(MysqlClientPermission, CasbinRule{}, "tb_permission_casbin_rule")
-	Enforcer, _ = casbin.NewEnforcer(modelPolicyAddr, Adapter)
-	Enforcer.LoadModel()
-	Enforcer.LoadPolicy()
+	gormAdapter, _ := gormadapter.NewAdapterByDBWithCustomTable(MysqlClientPermission, CasbinRule{}, casbinRuleTable)
+	Adapter = gormAdapter
+	m, err := model.NewModelFromFile(modelPolicyAddr)
+	if err != nil {
+		panic("load casbin model failure: " + err.Error())
+	}
+	Enforcer = newEnforcer(m, &policyAdapter{Adapter: gormAdapter})
+	initDomainFilter(conf.BasicConf.Permission.Domains)
+	ReloadPolicy()
+}
+
+// newEnforcer 创建并发安全的 enforcer，规则由 ReloadPolicy 加载
+func newEnforcer(m model.Model, adapter persist.Adapter) *casbin.SyncedEnforcer {
+	emptyModel = m.Copy()
+	e := newModelEnforcer(m)
+	e.SetAdapter(adapter)
+	return e
+}
+
+// 增量变更与全量加载互斥：加载期间到达的变更在新规则集替换后再应用，避免被加载前读取的旧数据覆盖
+var policyMu sync.Mutex
+
+// AddPolicies 将已写库的规则增量加入 enforcer，规则字段顺序与 policy_definition 一致
+func AddPolicies(rules [][]string) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	// 未加载的domain在首次鉴权时从库中加载，这里不提前放入内存
+	if rules = servedRules(rules); len(rules) == 0 {
+		return nil
+	}
+	if _, err := Enforcer.SelfAddPoliciesEx("p", "p", rules); err != nil {
+		return err
+	}
+	for e, routed := range routeRules(rules) {
+		if _, err := e.SelfAddPoliciesEx("p", "p", routed); err != nil {
+			return err
+		}
+	}
+	refreshDomainGauge(rules)
+	return nil
+}
+
+// RemovePolicies 从 enforcer 中移除已从库中删除的规则
+func RemovePolicies(rules [][]string) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	if _, err := Enforcer.SelfRemovePolicies("p", "p", rules); err != nil {
+		return err
+	}
+	for e, routed := range routeRules(rules) {
+		if _, err := e.SelfRemovePolicies("p", "p", routed); err != nil {
+			return err
+		}
+	}
+	refreshDomainGauge(rules)
+	return nil
+}
+
+// RemoveFilteredPolicy 从 enforcer 中移除前若干字段匹配的规则，对应按 v0、v1、v2... 条件删库
+func RemoveFilteredPolicy(fieldValues ...string) error {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	if _, err := Enforcer.SelfRemoveFilteredPolicy("p", "p", 0, fieldValues...); err != nil {
+		return err
+	}
+	// 指定了domain时只涉及该domain所绑定的模型
+	enforcers := modelEnforcers()
+	if len(fieldValues) > 1 {
+		enforcers = enforcers[:0]
+		for e := range routeRules([][]string{fieldValues}) {
+			enforcers = append(enforcers, e)
+		}
+	}
+	for _, e := range enforcers {
+		if _, err := e.SelfRemoveFilteredPolicy("p", "p", 0, fieldValues...); err != nil {
+			return err
+		}
+	}
+	if len(fieldValues) > 1 {
+		refreshDomainGauge([][]string{fieldValues})
+	}
+	return nil
+}
+
+// UpdatePolicy 将 enforcer 中的规则替换为库中更新后的取值
+func UpdatePolicy(oldRule, newRule []string) error {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	if len(servedRules([][]string{oldRule})) == 0 {
+		return nil
+	}
+	if _, err := Enforcer.SelfUpdatePolicy("p", "p", oldRule, newRule); err != nil {
+		return err
+	}
+	for e := range routeRules([][]string{oldRule}) {
+		if _, err := e.SelfUpdatePolicy("p", "p", oldRule, newRule); err != nil {
+			return err
+		}
+	}
+	refreshDomainGauge([][]string{oldRule, newRule})
+	return nil
+}
+
+// 最近一次加载校验规则的结果，供探针判断规则是否可用
+var policyLoad struct {
+	sync.RWMutex
+	loadedAt time.Time // 最近一次成功加载的时间
+	lastErr  error     // 最近一次加载的错误，成功后清空
+}
+
+// PolicyLoadState 返回最近一次成功加载规则的时间和最近一次加载的错误
+func PolicyLoadState() (loadedAt time.Time, lastErr error) {
+	policyLoad.RLock()
+	defer policyLoad.RUnlock()
+	return policyLoad.loadedAt, policyLoad.lastErr
+}
+
+// 默认全量加载校验规则的周期
+const defaultPolicyReloadInterval = 5 * time.Minute
+
+// PolicyReloadInterval 周期全量加载的间隔，直接写库或由其他实例写入的变更最晚在一个周期后生效
+func PolicyReloadInterval() time.Duration {
+	if conf.BasicConf.Permission.PolicyReloadInterval > 0 {
+		return conf.BasicConf.Permission.PolicyReloadInterval
+	}
+	return defaultPolicyReloadInterval
+}
+
+// reloadCall 一轮全量加载，done 关闭后 err 可读
+type reloadCall struct {
+	done chan struct{}
+	err  error
+}
+
+// 同一时间只有一轮加载在执行，执行期间到达的请求合并为下一轮
+var reloadState struct {
+	sync.Mutex
+	running bool
+	pending *reloadCall
+}
+
+// ReloadPolicy 全量加载校验规则，返回时调用前已提交的规则变更均已生效。
+// 加载期间到达的调用合并为一轮，等当前这轮结束后统一再加载一次
+func ReloadPolicy() error {
+	reloadState.Lock()
+	if reloadState.running {
+		if reloadState.pending == nil {
+			reloadState.pending = &reloadCall{done: make(chan struct{})}
+		}
+		c := reloadState.pending
+		reloadState.Unlock()
+		<-c.done
+		return c.err
+	}
+	reloadState.running = true
+	reloadState.Unlock()
+	c := &reloadCall{done: make(chan struct{})}
+	runReload(c)
+	return c.err
+}
+
+func runReload(c *reloadCall) {
+	c.err = loadPolicy()
+	close(c.done)
+	reloadState.Lock()
+	next := reloadState.pending
+	reloadState.pending = nil
+	if next == nil {
+		reloadState.running = false
+	}
+	reloadState.Unlock()
+	if next != nil {
+		go runReload(next)
+	}
+}
+
+// loadPolicy 在旁路构建新的规则集后原子替换，加载期间鉴权不受阻塞；同时刷新各domain绑定的模型，记录加载耗时和各domain的规则数
+func loadPolicy() error {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	start := time.Now()
+	err := Enforcer.LoadPolicyFast()
+	if err == nil {
+		err = refreshModels()
+	}
+	ReloadDuration.Observe(time.Since(start).Seconds())
+	policyLoad.Lock()
+	policyLoad.lastErr = err
+	if err == nil {
+		policyLoad.loadedAt = time.Now()
+	}
+	policyLoad.Unlock()
+	if err != nil {
+		ReloadCounter.WithLabelValues("fail").Inc()
+		return err
+	}
+	ReloadCounter.WithLabelValues("succ").Inc()
+	refreshPolicyGauge()
+	return nil
+}
+
+// 各domain已加载的规则条数，与 PolicyLoaded 同步维护，供探针读取总数时不必复制全部规则
+var policyCount struct {
+	sync.Mutex
+	domains map[string]int
+}
+
+// LoadedPolicyCount 内存中已加载的规则总条数
+func LoadedPolicyCount() int {
+	policyCount.Lock()
+	defer policyCount.Unlock()
+	total := 0
+	for _, cnt := range policyCount.domains {
+		total += cnt
+	}
+	return total
+}
+
+func setPolicyCount(domain string, cnt int) {
+	policyCount.Lock()
+	defer policyCount.Unlock()
+	if policyCount.domains == nil {
+		policyCount.domains = make(map[string]int)
+	}
+	policyCount.domains[domain] = cnt
+	PolicyLoaded.WithLabelValues(domain).Set(float64(cnt))
+}
+
+func deletePolicyCount(domain string) {
+	policyCount.Lock()
+	defer policyCount.Unlock()
+	delete(policyCount.domains, domain)
+	PolicyLoaded.DeleteLabelValues(domain)
+}
+
+func refreshPolicyGauge() {
+	// domain 对应规则中的 v1 字段（product:app）
+	counts := make(map[string]int)
+	for _, rule := range Enforcer.GetPolicy() {
+		if len(rule) > 1 {
+			counts[rule[1]]++
+		}
+	}
+	policyCount.Lock()
+	policyCount.domains = counts
+	PolicyLoaded.Reset()
+	for domain, cnt := range counts {
+		PolicyLoaded.WithLabelValues(domain).Set(float64(cnt))
+	}
+	policyCount.Unlock()
+}
+
+// refreshDomainGauge 增量变更后只刷新涉及的domain
+func refreshDomainGauge(rules [][]string) {
+	domains := make(map[string]struct{})
+	for _, rule := range rules {
+		if len(rule) > 1 {
+			domains[rule[1]] = struct{}{}
+		}
+	}
+	for domain := range domains {
+		setPolicyCount(domain, len(Enforcer.GetFilteredPolicy(1, domain)))
+	}
 }
@@ -0,0 +1,159 @@
+package helpers
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+	gormadapter "github.com/casbin/gorm-adapter/v3"
+)
+
+// 不含规则的模型副本，按domain加载时在其上读取规则
+var emptyModel model.Model
+
+var errFilterNotSupported = errors.New("casbin adapter does not support filtered policy")
+
+// 按domain加载规则：配置了 permission.domains 时只服务这些domain，
+// 每个domain在首次鉴权时加载，空闲超时后由周期任务卸载；未配置时启动即全量加载
+var domainFilter struct {
+	sync.RWMutex
+	enabled bool
+	allowed map[string]struct{}
+	loaded  map[string]*int64 // domain -> 最近一次鉴权的unix时间
+}
+
+func initDomainFilter(domains []string) {
+	domainFilter.Lock()
+	defer domainFilter.Unlock()
+	domainFilter.enabled = len(domains) > 0
+	domainFilter.allowed = make(map[string]struct{}, len(domains))
+	domainFilter.loaded = make(map[string]*int64)
+	for _, domain := range domains {
+		domainFilter.allowed[domain] = struct{}{}
+	}
+}
+
+// loadedDomainList 返回已加载的domain，未启用按domain加载时 ok 为 false
+func loadedDomainList() (domains []string, ok bool) {
+	domainFilter.RLock()
+	defer domainFilter.RUnlock()
+	if !domainFilter.enabled {
+		return nil, false
+	}
+	for domain := range domainFilter.loaded {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	return domains, true
+}
+
+// servedRules 过滤掉未加载domain的规则，调用方需持有 policyMu
+func servedRules(rules [][]string) [][]string {
+	domainFilter.RLock()
+	defer domainFilter.RUnlock()
+	if !domainFilter.enabled {
+		return rules
+	}
+	var served [][]string
+	for _, rule := range rules {
+		if len(rule) < 2 {
+			continue
+		}
+		if _, ok := domainFilter.loaded[rule[1]]; ok {
+			served = append(served, rule)
+		}
+	}
+	return served
+}
+
+// EnsureDomain 鉴权前调用，domain 未加载时从库中加载；返回 false 表示本实例不服务该domain
+func EnsureDomain(domain string) (bool, error) {
+	now := time.Now().Unix()
+	domainFilter.RLock()
+	if !domainFilter.enabled {
+		domainFilter.RUnlock()
+		return true, nil
+	}
+	if _, ok := domainFilter.allowed[domain]; !ok {
+		domainFilter.RUnlock()
+		return false, nil
+	}
+	if lastUsed, ok := domainFilter.loaded[domain]; ok {
+		atomic.StoreInt64(lastUsed, now)
+		domainFilter.RUnlock()
+		return true, nil
+	}
+	domainFilter.RUnlock()
+
+	// 与全量加载、增量变更互斥，避免加载期间的变更丢失
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	domainFilter.RLock()
+	_, loaded := domainFilter.loaded[domain]
+	domainFilter.RUnlock()
+	if loaded {
+		return true, nil
+	}
+	adapter, ok := Enforcer.GetAdapter().(persist.FilteredAdapter)
+	if !ok {
+		return false, errFilterNotSupported
+	}
+	m := emptyModel.Copy()
+	if err := adapter.LoadFilteredPolicy(m, gormadapter.Filter{V1: []string{domain}}); err != nil {
+		return false, err
+	}
+	if rules := m["p"]["p"].Policy; len(rules) > 0 {
+		if _, err := Enforcer.SelfAddPoliciesEx("p", "p", rules); err != nil {
+			return false, err
+		}
+		for e, routed := range routeRules(rules) {
+			if _, err := e.SelfAddPoliciesEx("p", "p", routed); err != nil {
+				return false, err
+			}
+		}
+	}
+	domainFilter.Lock()
+	domainFilter.loaded[domain] = &now
+	domainFilter.Unlock()
+	DomainLoaded.WithLabelValues(domain).Set(1)
+	refreshDomainGauge([][]string{{"", domain}})
+	return true, nil
+}
+
+// UnloadIdleDomains 卸载超过 idle 未被鉴权的domain，返回卸载的domain
+func UnloadIdleDomains(idle time.Duration) ([]string, error) {
+	if idle <= 0 {
+		return nil, nil
+	}
+	deadline := time.Now().Add(-idle).Unix()
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	domainFilter.Lock()
+	defer domainFilter.Unlock()
+	if !domainFilter.enabled {
+		return nil, nil
+	}
+	var unloaded []string
+	for domain, lastUsed := range domainFilter.loaded {
+		if atomic.LoadInt64(lastUsed) > deadline {
+			continue
+		}
+		if _, err := Enforcer.SelfRemoveFilteredPolicy("p", "p", 1, domain); err != nil {
+			return unloaded, err
+		}
+		if e := EnforcerFor(domain); e != Enforcer {
+			if _, err := e.SelfRemoveFilteredPolicy("p", "p", 1, domain); err != nil {
+				return unloaded, err
+			}
+		}
+		delete(domainFilter.loaded, domain)
+		DomainLoaded.DeleteLabelValues(domain)
+		deletePolicyCount(domain)
+		unloaded = append(unloaded, domain)
+	}
+	return unloaded, nil
+}
@@ -0,0 +1,218 @@
+package helpers
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"permission/components"
+)
+
+// 按domain选用模型：模型注册在 casbin_model 表中，domain_model 表将domain绑定到已启用的模型，未绑定的domain使用默认模型。
+// 绑定了模型的domain的规则除默认 enforcer 外在该模型的 enforcer 中另存一份，鉴权时按domain路由
+
+const (
+	casbinModelTable = "tb_permission_casbin_model"
+	domainModelTable = "tb_permission_domain_model"
+)
+
+type modelEnforcer struct {
+	name     string
+	enforcer *casbin.SyncedEnforcer
+	domains  []string
+}
+
+var modelRegistry struct {
+	sync.RWMutex
+	models  map[string]*modelEnforcer // 模型名 -> enforcer
+	domains map[string]*modelEnforcer // domain -> enforcer
+}
+
+// EnforcerFor 返回domain所用模型的 enforcer
+func EnforcerFor(domain string) *casbin.SyncedEnforcer {
+	modelRegistry.RLock()
+	defer modelRegistry.RUnlock()
+	if me, ok := modelRegistry.domains[domain]; ok {
+		return me.enforcer
+	}
+	return Enforcer
+}
+
+// DomainModelName 返回domain所用模型的名称
+func DomainModelName(domain string) string {
+	modelRegistry.RLock()
+	defer modelRegistry.RUnlock()
+	if me, ok := modelRegistry.domains[domain]; ok {
+		return me.name
+	}
+	return components.CASBIN_MODEL_DEFAULT
+}
+
+// ModelCase 模型校验用例，Expect 为预期的鉴权结果
+type ModelCase struct {
+	Sub    string `json:"sub"`
+	Dom    string `json:"dom"`
+	Obj    string `json:"obj"`
+	Act    string `json:"act"`
+	Expect bool   `json:"expect"`
+}
+
+// parseModel 解析模型文本，请求和规则定义须与默认模型一致(规则表和鉴权参数共用)
+func parseModel(text string) (model.Model, error) {
+	m, err := model.NewModelFromString(text)
+	if err != nil {
+		return nil, err
+	}
+	for _, sec := range []string{"r", "p"} {
+		want, got := emptyModel[sec][sec], m[sec][sec]
+		if got == nil || !reflect.DeepEqual(got.Tokens, want.Tokens) {
+			return nil, fmt.Errorf("%s definition must be %q", sec, want.Value)
+		}
+	}
+	return m, nil
+}
+
+func newModelEnforcer(m model.Model) *casbin.SyncedEnforcer {
+	// 只传入模型时不会在创建时加载规则
+	e, _ := casbin.NewSyncedEnforcer(m)
+	// 规则由业务在事务中写库，enforcer 只维护内存中的规则
+	e.EnableAutoSave(false)
+	e.AddFunction("conditionMatch", conditionMatchFunc)
+	return e
+}
+
+// ValidateModel 校验模型文本，并以给定规则试跑用例，返回与预期不符的用例下标。
+// 没有用例时用一条示例规则试跑，提前暴露匹配器中的错误
+func ValidateModel(text string, policies [][]string, cases []ModelCase) (failed []int, err error) {
+	m, err := parseModel(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(cases) == 0 {
+		policies = [][]string{{"1", "1:1", "/", components.CASBIN_ACT_ANY, components.POLICY_STATUS_ALLOW, ""}}
+		cases = []ModelCase{{Sub: "1", Dom: "1:1", Obj: "/", Act: components.CASBIN_ACT_ANY}}
+	}
+	e := newModelEnforcer(m)
+	if len(policies) > 0 {
+		if _, err := e.SelfAddPoliciesEx("p", "p", policies); err != nil {
+			return nil, err
+		}
+	}
+	attrs := &RequestAttrs{Now: time.Now()}
+	for i, c := range cases {
+		allow, err := e.Enforce(c.Sub, c.Dom, c.Obj, c.Act, attrs)
+		if err != nil {
+			return nil, fmt.Errorf("case %d: %v", i, err)
+		}
+		if allow != c.Expect {
+			failed = append(failed, i)
+		}
+	}
+	return failed, nil
+}
+
+// domainAdapter 只加载绑定到同一模型的domain的规则，按domain加载时只取其中已加载的
+type domainAdapter struct {
+	*policyAdapter
+	domains []string
+}
+
+func (a *domainAdapter) LoadPolicy(m model.Model) error {
+	domains := a.domains
+	if loaded, ok := loadedDomainList(); ok {
+		domains = nil
+		for _, domain := range loaded {
+			if containsString(a.domains, domain) {
+				domains = append(domains, domain)
+			}
+		}
+	}
+	return a.LoadFilteredPolicy(m, gormadapter.Filter{V1: domains})
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshModels 按库中的绑定关系为各模型构建 enforcer，在旁路加载规则后替换，任一模型失败时保留原有的全部模型；调用方需持有 policyMu
+func refreshModels() error {
+	base, ok := Enforcer.GetAdapter().(*policyAdapter)
+	if !ok {
+		// 非数据库 adapter(如测试)只使用默认模型
+		return nil
+	}
+	var bindings []struct {
+		Domain string
+		Name   string
+		Text   string
+	}
+	err := MysqlClientPermission.Table(domainModelTable+" AS d").
+		Select("d.domain, m.name, m.text").
+		Joins("JOIN "+casbinModelTable+" AS m ON m.name = d.model_name AND m.status = ?", components.CASBIN_MODEL_STATUS_ACTIVE).
+		Order("d.id").Scan(&bindings).Error
+	if err != nil {
+		return err
+	}
+	models := make(map[string]*modelEnforcer)
+	domains := make(map[string]*modelEnforcer, len(bindings))
+	for _, b := range bindings {
+		me, ok := models[b.Name]
+		if !ok {
+			m, err := parseModel(b.Text)
+			if err != nil {
+				return fmt.Errorf("model %s: %v", b.Name, err)
+			}
+			me = &modelEnforcer{name: b.Name, enforcer: newModelEnforcer(m)}
+			models[b.Name] = me
+		}
+		me.domains = append(me.domains, b.Domain)
+		domains[b.Domain] = me
+	}
+	for _, me := range models {
+		me.enforcer.SetAdapter(&domainAdapter{policyAdapter: base, domains: me.domains})
+		if err := me.enforcer.LoadPolicyFast(); err != nil {
+			return fmt.Errorf("model %s: %v", me.name, err)
+		}
+	}
+	modelRegistry.Lock()
+	modelRegistry.models = models
+	modelRegistry.domains = domains
+	modelRegistry.Unlock()
+	return nil
+}
+
+// routeRules 将规则按domain分到所绑定模型的 enforcer 上，未绑定模型的规则不返回
+func routeRules(rules [][]string) map[*casbin.SyncedEnforcer][][]string {
+	modelRegistry.RLock()
+	defer modelRegistry.RUnlock()
+	routed := make(map[*casbin.SyncedEnforcer][][]string)
+	for _, rule := range rules {
+		if len(rule) < 2 {
+			continue
+		}
+		if me, ok := modelRegistry.domains[rule[1]]; ok {
+			routed[me.enforcer] = append(routed[me.enforcer], rule)
+		}
+	}
+	return routed
+}
+
+// modelEnforcers 返回全部非默认模型的 enforcer
+func modelEnforcers() []*casbin.SyncedEnforcer {
+	modelRegistry.RLock()
+	defer modelRegistry.RUnlock()
+	enforcers := make([]*casbin.SyncedEnforcer, 0, len(modelRegistry.models))
+	for _, me := range modelRegistry.models {
+		enforcers = append(enforcers, me.enforcer)
+	}
+	return enforcers
+}
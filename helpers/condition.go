@@ -0,0 +1,182 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// RequestAttrs 鉴权请求携带的属性，作为匹配器中的 r.attrs
+type RequestAttrs struct {
+	ClientIp string            `json:"clientIp"`
+	Extra    map[string]string `json:"extra"`
+	Now      time.Time         `json:"-"`
+}
+
+// TimeRange 一天中的时间段，格式 HH:MM，End 小于 Start 时表示跨零点
+type TimeRange struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Condition 规则上的附加条件，存放在 casbin_rule.v5 中
+// 各类条件之间为且的关系，同一类条件的多个取值之间为或的关系，未配置的条件不做限制
+type Condition struct {
+	IpRanges   []string            `json:"ipRanges"`   // 客户端ip或cidr
+	TimeRanges []TimeRange         `json:"timeRanges"` // 允许访问的时间段
+	Weekdays   []time.Weekday      `json:"weekdays"`   // 允许访问的星期，0为周日
+	Attrs      map[string][]string `json:"attrs"`      // 自定义属性的可选值
+
+	nets    []*net.IPNet
+	minutes [][2]int
+}
+
+// 解析后的条件缓存，同一条件表达式只解析一次
+var conditionCache sync.Map
+
+// ParseCondition 解析并校验条件表达式，空表达式表示无条件
+func ParseCondition(expr string) (*Condition, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	if c, ok := conditionCache.Load(expr); ok {
+		return c.(*Condition), nil
+	}
+	c := &Condition{}
+	if err := json.Unmarshal([]byte(expr), c); err != nil {
+		return nil, fmt.Errorf("condition is not valid json: %v", err)
+	}
+	for _, r := range c.IpRanges {
+		if ip := net.ParseIP(r); ip != nil {
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			c.nets = append(c.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ip range %q", r)
+		}
+		c.nets = append(c.nets, ipNet)
+	}
+	for _, r := range c.TimeRanges {
+		start, err := parseMinute(r.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseMinute(r.End)
+		if err != nil {
+			return nil, err
+		}
+		c.minutes = append(c.minutes, [2]int{start, end})
+	}
+	for _, w := range c.Weekdays {
+		if w < time.Sunday || w > time.Saturday {
+			return nil, fmt.Errorf("invalid weekday %d", w)
+		}
+	}
+	conditionCache.Store(expr, c)
+	return c, nil
+}
+
+func parseMinute(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, want HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Match 判断请求属性是否满足条件
+func (c *Condition) Match(attrs *RequestAttrs) bool {
+	if c == nil {
+		return true
+	}
+	if attrs == nil {
+		attrs = &RequestAttrs{}
+	}
+	now := attrs.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if len(c.nets) > 0 {
+		ip := net.ParseIP(attrs.ClientIp)
+		if ip == nil || !c.matchIp(ip) {
+			return false
+		}
+	}
+	if len(c.minutes) > 0 && !c.matchTime(now.Hour()*60+now.Minute()) {
+		return false
+	}
+	if len(c.Weekdays) > 0 && !c.matchWeekday(now.Weekday()) {
+		return false
+	}
+	for key, values := range c.Attrs {
+		value, ok := attrs.Extra[key]
+		if !ok || !contains(values, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Condition) matchIp(ip net.IP) bool {
+	for _, ipNet := range c.nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Condition) matchTime(minute int) bool {
+	for _, r := range c.minutes {
+		if r[0] <= r[1] && minute >= r[0] && minute < r[1] {
+			return true
+		}
+		if r[0] > r[1] && (minute >= r[0] || minute < r[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Condition) matchWeekday(weekday time.Weekday) bool {
+	for _, w := range c.Weekdays {
+		if w == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionMatchFunc 注册到匹配器中的 conditionMatch(p.cond, r.attrs)
+// 条件表达式不合法时按不匹配处理，避免错误配置放大权限
+func conditionMatchFunc(args ...interface{}) (interface{}, error) {
+	if len(args) != 2 {
+		return false, fmt.Errorf("conditionMatch expects 2 arguments, got %d", len(args))
+	}
+	expr, _ := args[0].(string)
+	if expr == "" {
+		return true, nil
+	}
+	c, err := ParseCondition(expr)
+	if err != nil {
+		return false, nil
+	}
+	attrs, _ := args[1].(*RequestAttrs)
+	return c.Match(attrs), nil
+}
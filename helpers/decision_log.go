@@ -0,0 +1,377 @@
+package helpers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	elastic "github.com/olivere/elastic/v7"
+	"permission/conf"
+	"permission/pkg/golib/v2/base"
+	"permission/pkg/golib/v2/env"
+	"permission/pkg/golib/v2/zlog"
+)
+
+// 鉴权决策日志：按采样比例异步记录鉴权结果。配置了es时经 BulkProcessor 批量写入按天划分的索引，否则按行写入本地文件。
+// 写入先进入有界队列，队列满时丢弃，不阻塞鉴权
+
+const (
+	defaultDecisionIndex         = "permission-decision"
+	defaultDecisionQueueSize     = 10000
+	defaultDecisionFlushInterval = time.Second
+	decisionBulkActions          = 1000
+)
+
+// Decision 一次鉴权的结果，Subject 和 Rule 为决定结果的权限组(或 user:<id>)及其命中的规则，未命中任何规则时为空
+type Decision struct {
+	Time         int64  `json:"time"`
+	UserId       int64  `json:"userId"`
+	Domain       string `json:"domain"`
+	Resource     string `json:"resource"`
+	Action       string `json:"action"`
+	Result       string `json:"result"` // allow/deny/error
+	Subject      string `json:"subject,omitempty"`
+	Rule         string `json:"rule,omitempty"`
+	LatencyUs    int64  `json:"latencyUs"`
+	Impersonator int64  `json:"impersonator,omitempty"` // 模拟鉴权时为发起模拟的管理员uid
+}
+
+// DecisionQuery 决策日志检索条件，时间为秒级时间戳，其余条件为0或为空时不参与筛选
+type DecisionQuery struct {
+	StartTime int64
+	EndTime   int64
+	UserId    int64
+	Domain    string
+	Resource  string
+	Result    string
+	Size      int
+}
+
+func (q DecisionQuery) match(d *Decision) bool {
+	return d.Time >= q.StartTime && d.Time <= q.EndTime &&
+		(q.UserId == 0 || d.UserId == q.UserId) &&
+		(q.Domain == "" || d.Domain == q.Domain) &&
+		(q.Resource == "" || d.Resource == q.Resource) &&
+		(q.Result == "" || d.Result == q.Result)
+}
+
+type decisionSink interface {
+	write(d *Decision)
+	// search 按时间倒序返回最多 q.Size 条，total 为命中总数
+	search(ctx context.Context, q DecisionQuery) (total int64, list []Decision, err error)
+	close()
+}
+
+var decisionLog struct {
+	sink  decisionSink
+	rate  float64
+	queue chan *Decision
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// InitDecisionLog esService 和 fileName 均未配置时不初始化，不记录决策日志
+func InitDecisionLog() {
+	c := conf.BasicConf.Permission.DecisionLog
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = defaultDecisionFlushInterval
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = defaultDecisionQueueSize
+	}
+	if c.Index == "" {
+		c.Index = defaultDecisionIndex
+	}
+	switch {
+	case c.EsService != "":
+		cfg, ok := conf.RConf.Elastic[c.EsService]
+		if !ok {
+			panic("decision log elastic not configured: " + c.EsService)
+		}
+		sink, err := newEsDecisionSink(cfg, c.Index, c.FlushInterval)
+		if err != nil {
+			panic("init decision log elastic error: " + err.Error())
+		}
+		decisionLog.sink = sink
+	case c.FileName != "":
+		sink, err := newFileDecisionSink(filepath.Join(env.GetLogDirPath(), c.FileName), c.FlushInterval)
+		if err != nil {
+			panic("init decision log file error: " + err.Error())
+		}
+		decisionLog.sink = sink
+	default:
+		return
+	}
+	decisionLog.rate = c.SampleRate
+	decisionLog.queue = make(chan *Decision, c.QueueSize)
+	decisionLog.stop = make(chan struct{})
+	decisionLog.done = make(chan struct{})
+	go runDecisionLog()
+}
+
+// CloseDecisionLog 写完队列中剩余的日志后关闭
+func CloseDecisionLog() {
+	if decisionLog.sink == nil {
+		return
+	}
+	close(decisionLog.stop)
+	<-decisionLog.done
+}
+
+func DecisionLogEnabled() bool {
+	return decisionLog.sink != nil
+}
+
+// RecordDecision 按采样比例记录一次鉴权结果
+func RecordDecision(d Decision) {
+	if decisionLog.sink == nil || rand.Float64() >= decisionLog.rate {
+		return
+	}
+	select {
+	case decisionLog.queue <- &d:
+	default:
+		DecisionDropCounter.Inc()
+	}
+}
+
+// SearchDecisions 检索决策日志，未启用时返回空结果
+func SearchDecisions(ctx context.Context, q DecisionQuery) (int64, []Decision, error) {
+	if decisionLog.sink == nil {
+		return 0, nil, nil
+	}
+	return decisionLog.sink.search(ctx, q)
+}
+
+func runDecisionLog() {
+	defer close(decisionLog.done)
+	for {
+		select {
+		case d := <-decisionLog.queue:
+			decisionLog.sink.write(d)
+		case <-decisionLog.stop:
+			for {
+				select {
+				case d := <-decisionLog.queue:
+					decisionLog.sink.write(d)
+				default:
+					decisionLog.sink.close()
+					return
+				}
+			}
+		}
+	}
+}
+
+// esDecisionSink 按天写入 <index>-yyyy.MM.dd，检索时查询 <index>-*
+type esDecisionSink struct {
+	client    *elastic.Client
+	processor *elastic.BulkProcessor
+	index     string
+}
+
+func newEsDecisionSink(cfg base.ElasticClientConfig, index string, flushInterval time.Duration) (*esDecisionSink, error) {
+	client, err := base.NewESClientV7(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// 字段均按精确值检索，用索引模板固定映射
+	keyword := map[string]interface{}{"type": "keyword"}
+	template := map[string]interface{}{
+		"index_patterns": []string{index + "-*"},
+		"mappings": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"time":         map[string]interface{}{"type": "long"},
+				"userId":       map[string]interface{}{"type": "long"},
+				"domain":       keyword,
+				"resource":     keyword,
+				"action":       keyword,
+				"result":       keyword,
+				"subject":      keyword,
+				"rule":         keyword,
+				"latencyUs":    map[string]interface{}{"type": "long"},
+				"impersonator": map[string]interface{}{"type": "long"},
+			},
+		},
+	}
+	if _, err := client.IndexPutTemplate(index).BodyJson(template).Do(context.Background()); err != nil {
+		zlog.Warnf(nil, "put decision log index template error: %v", err)
+	}
+	processor, err := client.BulkProcessor().
+		Name("permission-decision").
+		Workers(1).
+		BulkActions(decisionBulkActions).
+		FlushInterval(flushInterval).
+		After(func(_ int64, requests []elastic.BulkableRequest, response *elastic.BulkResponse, err error) {
+			if err != nil {
+				zlog.Warnf(nil, "write decision log error, requests:%d err:%v", len(requests), err)
+			} else if response != nil && response.Errors {
+				zlog.Warnf(nil, "write decision log partially failed, failed:%d", len(response.Failed()))
+			}
+		}).
+		Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return &esDecisionSink{client: client, processor: processor, index: index}, nil
+}
+
+func (s *esDecisionSink) write(d *Decision) {
+	index := s.index + "-" + time.Unix(d.Time, 0).Format("2006.01.02")
+	s.processor.Add(elastic.NewBulkIndexRequest().Index(index).Doc(d))
+}
+
+func (s *esDecisionSink) search(ctx context.Context, q DecisionQuery) (int64, []Decision, error) {
+	query := elastic.NewBoolQuery().Filter(elastic.NewRangeQuery("time").Gte(q.StartTime).Lte(q.EndTime))
+	if q.UserId > 0 {
+		query.Filter(elastic.NewTermQuery("userId", q.UserId))
+	}
+	for field, value := range map[string]string{"domain": q.Domain, "resource": q.Resource, "result": q.Result} {
+		if value != "" {
+			query.Filter(elastic.NewTermQuery(field, value))
+		}
+	}
+	res, err := s.client.Search(s.index+"-*").
+		Query(query).
+		Sort("time", false).
+		Size(q.Size).
+		TrackTotalHits(true).
+		Do(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	list := make([]Decision, 0, len(res.Hits.Hits))
+	for _, hit := range res.Hits.Hits {
+		var d Decision
+		if err := json.Unmarshal(hit.Source, &d); err != nil {
+			return 0, nil, err
+		}
+		list = append(list, d)
+	}
+	return res.TotalHits(), list, nil
+}
+
+func (s *esDecisionSink) close() {
+	if err := s.processor.Close(); err != nil {
+		zlog.Warnf(nil, "close decision log bulk processor error: %v", err)
+	}
+	s.client.Stop()
+}
+
+// fileDecisionSink 每行一条json，检索时顺序扫描当前文件，不包含已被切割走的历史文件。
+// 每次刷盘前检查文件是否已被切割(移走或删除)，是则重新打开 path 写入新文件
+type fileDecisionSink struct {
+	mu            sync.Mutex
+	path          string
+	file          *os.File
+	writer        *bufio.Writer
+	flushInterval time.Duration
+	flushedAt     time.Time
+}
+
+func newFileDecisionSink(path string, flushInterval time.Duration) (*fileDecisionSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &fileDecisionSink{
+		path:          path,
+		file:          file,
+		writer:        bufio.NewWriter(file),
+		flushInterval: flushInterval,
+		flushedAt:     time.Now(),
+	}, nil
+}
+
+func (s *fileDecisionSink) write(d *Decision) {
+	line, err := json.Marshal(d)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _ = s.writer.Write(append(line, '\n'))
+	if time.Since(s.flushedAt) >= s.flushInterval {
+		s.flush()
+	}
+}
+
+// flush 调用方需持有 mu
+func (s *fileDecisionSink) flush() {
+	if err := s.writer.Flush(); err != nil {
+		zlog.Warnf(nil, "flush decision log file error: %v", err)
+	}
+	s.flushedAt = time.Now()
+	s.reopenIfRotated()
+}
+
+// reopenIfRotated 缓冲区已写入原文件后再切换，调用方需持有 mu
+func (s *fileDecisionSink) reopenIfRotated() {
+	current, err := s.file.Stat()
+	if err != nil {
+		return
+	}
+	if latest, err := os.Stat(s.path); err == nil && os.SameFile(current, latest) {
+		return
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		zlog.Warnf(nil, "reopen decision log file error: %v", err)
+		return
+	}
+	_ = s.file.Close()
+	s.file = file
+	s.writer.Reset(file)
+}
+
+func (s *fileDecisionSink) search(ctx context.Context, q DecisionQuery) (int64, []Decision, error) {
+	s.mu.Lock()
+	s.flush()
+	s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer file.Close()
+	var total int64
+	var list []Decision
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var d Decision
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil || !q.match(&d) {
+			continue
+		}
+		total++
+		// 文件按写入顺序追加，只保留最新的 Size 条
+		list = append(list, d)
+		if len(list) > q.Size {
+			list = list[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, nil, fmt.Errorf("scan %s: %v", s.path, err)
+	}
+	for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+		list[i], list[j] = list[j], list[i]
+	}
+	return total, list, nil
+}
+
+func (s *fileDecisionSink) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.writer.Flush(); err != nil {
+		zlog.Warnf(nil, "flush decision log file error: %v", err)
+	}
+	_ = s.file.Close()
+}
@@ -38,11 +38,17 @@ func Clear() {
 func InitResource(engine *gin.Engine) {
 	// 初始化全局变量
 	InitMysql()
+	InitLocalCache()
+	RegistryBusinessMetrics()
 	InitCasbin()
+	InitKafkaProducer()
+	InitRocketMq()
+	InitDecisionLog()
 }
 
 func Release() {
 	CloseGPool()
-	// CloseKafkaProducer()
-	// CloseRocketMq()
+	CloseKafkaProducer()
+	CloseRocketMq()
+	CloseDecisionLog()
 }
@@ -0,0 +1,102 @@
+package helpers
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"permission/pkg/golib/v2/base"
+)
+
+const metricsNamespace = "permission"
+
+// 鉴权各阶段耗时的 stage 标签
+const (
+	StagePassport = "passport"
+	StageDb       = "db"
+	StageEnforce  = "enforce"
+	StageTotal    = "total"
+)
+
+// 业务指标，挂载在 golib 的 /runtime-metrics 采集接口上
+var (
+	// 鉴权请求次数，result: allow/deny/error
+	CheckCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "check_total",
+		Help:      "permission check requests by product, app and result",
+	}, []string{"product", "app", "result"})
+
+	// 鉴权各阶段耗时，stage: passport/db/enforce/total
+	CheckDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "check_duration_seconds",
+		Help:      "permission check latency split by stage",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"stage"})
+
+	// enforcer 重新加载规则次数，result: succ/fail
+	ReloadCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "enforcer_reload_total",
+		Help:      "casbin enforcer policy reloads by result",
+	}, []string{"result"})
+
+	ReloadDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "enforcer_reload_duration_seconds",
+		Help:      "casbin enforcer policy reload latency",
+		Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	})
+
+	// 内存中已加载的规则条数，domain: product:app
+	PolicyLoaded = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "policy_loaded",
+		Help:      "casbin policies loaded in memory per domain",
+	}, []string{"domain"})
+
+	// 按domain加载时已加载的domain，值恒为1，卸载后删除
+	DomainLoaded = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "domain_loaded",
+		Help:      "domains whose policies are loaded in memory",
+	}, []string{"domain"})
+
+	// 本地缓存命中情况，命中率 = hit / (hit + miss)
+	CacheCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "cache_requests_total",
+		Help:      "local cache lookups by cache name and result",
+	}, []string{"cache", "result"})
+
+	// 决策日志队列已满被丢弃的条数
+	DecisionDropCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "decision_log_dropped_total",
+		Help:      "decision log entries dropped because the queue was full",
+	})
+)
+
+// RegistryBusinessMetrics 需在 golib.Bootstraps 之后调用，此时 RuntimeMetricsRegister 已初始化
+func RegistryBusinessMetrics() {
+	registry := base.RuntimeMetricsRegister
+	if registry == nil {
+		// 未经过 Bootstraps 的场景（如任务脚本）注册到独立的注册器，仅保证打点不出错
+		registry = prometheus.NewRegistry()
+	}
+	registry.MustRegister(CheckCounter, CheckDuration, ReloadCounter, ReloadDuration, PolicyLoaded, DomainLoaded, CacheCounter, DecisionDropCounter)
+}
+
+// ObserveStage 记录某个阶段从 start 开始的耗时
+func ObserveStage(stage string, start time.Time) {
+	CheckDuration.WithLabelValues(stage).Observe(time.Since(start).Seconds())
+}
+
+// ObserveCache 记录一次缓存查询结果
+func ObserveCache(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	CacheCounter.WithLabelValues(cache, result).Inc()
+}
@@ -0,0 +1,51 @@
+package helpers
+
+import (
+	"permission/conf"
+
+	"permission/pkg/golib/v2/kafka"
+	"permission/pkg/golib/v2/rmq"
+	"permission/pkg/golib/v2/zlog"
+)
+
+// 权限变更通知使用的生产者，对应 resource.yaml 中 kafkapub 的 key 和 rmqv2.producer 的 service
+const (
+	NotifyKafkaService = "permission"
+	NotifyRmqService   = "permission-notify"
+)
+
+var KafkaProducer *kafka.PubClient
+
+// InitKafkaProducer 未配置时不初始化，通知只走已配置的通道
+func InitKafkaProducer() {
+	if c, ok := conf.RConf.KafkaPub[NotifyKafkaService]; ok {
+		KafkaProducer = kafka.InitKafkaPub(c)
+	}
+}
+
+func CloseKafkaProducer() {
+	if KafkaProducer != nil {
+		_ = KafkaProducer.CloseProducer()
+	}
+}
+
+// InitRocketMq 仅初始化通知使用的生产者
+func InitRocketMq() {
+	for _, producerConf := range conf.RConf.Rmq.Producer {
+		if producerConf.Service != NotifyRmqService {
+			continue
+		}
+		if err := rmq.InitProducer(producerConf); err != nil {
+			panic("init rmq producer error: " + err.Error())
+		}
+		if err := rmq.StartProducer(producerConf.Service); err != nil {
+			panic("start rmq producer error: " + err.Error())
+		}
+	}
+}
+
+func CloseRocketMq() {
+	if err := rmq.StopProducer(NotifyRmqService); err != nil && err != rmq.ErrRmqSvcNotRegiestered {
+		zlog.Warnf(nil, "stop rmq producer error: %v", err)
+	}
+}
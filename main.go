@@ -1,8 +1,10 @@
 package main
 
 import (
+	"os"
 	"permission/components"
 	"permission/conf"
+	"permission/controllers/http/probe"
 	"permission/helpers"
 	"permission/pkg/golib/v2"
 	"permission/router"
@@ -20,17 +22,30 @@ func main() {
 	helpers.PreInit()
 	defer helpers.Clear()
 
-	// ready 探针，支持业务重写
-	// base.RegReadyProbe(probe.Ready)
+	// ready、health 探针，检查各依赖是否可用
+	base.RegReadyProbe(probe.Ready)
+	base.RegHealthProbe(probe.Health)
 	golib.Bootstraps(engine, golib.BootstrapConf{
 		// 业务自定义recover handler
 		HandleRecovery: func(c *gin.Context, err interface{}) {
 			base.RenderJsonAbort(c, components.ErrorSystemError)
 		},
 	})
+	// 带参数启动时执行一次性任务，否则启动web服务
+	if len(os.Args) > 1 {
+		commandServer(engine, os.Args[1:])
+		return
+	}
 	httpServer(engine)
 }
 
+func commandServer(engine *gin.Engine, args []string) {
+	helpers.InitResource(engine)
+	defer helpers.Release()
+
+	router.Commands(engine, args)
+}
+
 func httpServer(engine *gin.Engine) {
 	// web 服务所需资源初始化
 	helpers.InitResource(engine)
@@ -39,6 +54,9 @@ func httpServer(engine *gin.Engine) {
 	// 初始化http服务路由
 	router.Http(engine)
 
+	// 周期任务
+	router.Tasks(engine)
+
 	// 启动web server
 	if err := http.Start(engine, conf.BasicConf.Server); err != nil {
 		panic(err.Error())
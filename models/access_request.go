@@ -0,0 +1,144 @@
+package models
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"permission/components"
+	"permission/helpers"
+)
+
+// AccessRequest 用户自助申请加入权限组或单个资源的申请单
+type AccessRequest struct {
+	ID              int64  `json:"id" gorm:"primary_key;column:id"`
+	ProductId       int64  `json:"productId" gorm:"column:product_id"`
+	AppId           int64  `json:"appId" gorm:"column:app_id"`
+	UserType        int8   `json:"userType" gorm:"column:user_type"`
+	UserId          int64  `json:"userId" gorm:"column:user_id"`
+	RequestType     int8   `json:"requestType" gorm:"column:request_type"` // 0:权限组 1:单个资源
+	GroupId         int64  `json:"groupId" gorm:"column:group_id"`
+	Resource        string `json:"resource" gorm:"column:resource"`
+	Reason          string `json:"reason" gorm:"column:reason"`
+	Duration        int64  `json:"duration" gorm:"column:duration"` // 申请的授权时长(秒)，0表示永久
+	Status          int8   `json:"status" gorm:"column:status"`
+	HandleUid       int64  `json:"handleUid" gorm:"column:handle_uid"`
+	HandleRemark    string `json:"handleRemark" gorm:"column:handle_remark"`
+	HandleTime      int64  `json:"handleTime" gorm:"column:handle_time"`
+	GrantExpireTime int64  `json:"grantExpireTime" gorm:"column:grant_expire_time"` // 审批通过后授权的到期时间，0表示永久
+	CreateTime      int64  `json:"createTime" gorm:"column:create_time"`
+	UpdateTime      int64  `json:"updateTime" gorm:"column:update_time"`
+}
+
+func (ar *AccessRequest) TableName() string {
+	return components.TABLE_PREX + "access_request"
+}
+
+func (ar *AccessRequest) InsertAccessRequest(ctx *gin.Context) (err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Create(ar).Error
+	if err != nil {
+		return components.ErrorDbInsert.Wrap(err)
+	}
+	return nil
+}
+
+// BatchInsertAccessRequest 批量写入申请单，用于复制用户权限时记录限时授权
+func (ar *AccessRequest) BatchInsertAccessRequest(ctx *gin.Context, requests []AccessRequest, db *gorm.DB) (rows int64, err error) {
+	if len(requests) == 0 {
+		return rows, err
+	}
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Create(requests)
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbInsert.Wrap(err)
+	}
+	return rows, nil
+}
+
+// UpdateAccessRequestByIdAndStatus 仅当申请单仍处于fromStatus时才更新，rows为0表示状态已被他人变更
+func (ar *AccessRequest) UpdateAccessRequestByIdAndStatus(ctx *gin.Context, id int64, fromStatus int8, fields map[string]interface{}) (rows int64, err error) {
+	db := helpers.MysqlClientPermission
+	result := db.WithContext(ctx).Model(&AccessRequest{}).Where("`id` = ? AND `status` = ?", id, fromStatus).Updates(fields)
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbUpdate.Wrap(err)
+	}
+	return rows, nil
+}
+
+// UpdateAccessRequestByConds 批量更新符合条件的申请单
+func (ar *AccessRequest) UpdateAccessRequestByConds(ctx *gin.Context, condition map[string]interface{}, fields map[string]interface{}, db *gorm.DB) (rows int64, err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Model(&AccessRequest{}).Where(condition).Updates(fields)
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbUpdate.Wrap(err)
+	}
+	return rows, nil
+}
+
+func (ar *AccessRequest) GetAccessRequestById(ctx *gin.Context, id int64) (request AccessRequest, err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Where("`id` = ?", id).Take(&request).Error
+	if err == gorm.ErrRecordNotFound {
+		err = nil
+	}
+	if err != nil {
+		return request, components.ErrorDbSelect.Wrap(err)
+	}
+	return request, nil
+}
+
+func (ar *AccessRequest) GetAccessRequestByConds(ctx *gin.Context, condition map[string]interface{}) (request AccessRequest, err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Where(condition).Find(&request).Error
+	if err != nil {
+		return request, components.ErrorDbSelect.Wrap(err)
+	}
+	return request, nil
+}
+
+func (ar *AccessRequest) GetAccessRequestListByConds(ctx *gin.Context, condition map[string]interface{}) (requests []AccessRequest, err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Where(condition).Order("id").Find(&requests).Error
+	if err != nil {
+		return requests, components.ErrorDbSelect.Wrap(err)
+	}
+	return requests, nil
+}
+
+// GetAccessRequestListBefore 查询某状态下指定时间字段早于deadline的申请单，用于过期任务
+func (ar *AccessRequest) GetAccessRequestListBefore(ctx *gin.Context, status int8, timeField string, deadline int64, limit int) (requests []AccessRequest, err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).
+		Where("`status` = ?", status).
+		Where("`"+timeField+"` > 0 AND `"+timeField+"` < ?", deadline).
+		Order("id").Limit(limit).Find(&requests).Error
+	if err != nil {
+		return requests, components.ErrorDbSelect.Wrap(err)
+	}
+	return requests, nil
+}
+
+func (ar *AccessRequest) GetAccessRequestListByPage(ctx *gin.Context, condition map[string]interface{}, option *Option, page *NormalPage) (requests []AccessRequest, cnt int, err error) {
+	if !option.IsNeedCnt && !option.IsNeedList {
+		return requests, cnt, nil
+	}
+	db := helpers.MysqlClientPermission.WithContext(ctx).Model(&AccessRequest{}).Where(condition)
+	if option.IsNeedCnt {
+		var c int64
+		db = db.Count(&c)
+		cnt = int(c)
+	}
+	if option.IsNeedList {
+		db = db.Scopes(NormalPaginate(page)).Find(&requests)
+	}
+	if db.Error != nil {
+		return requests, cnt, components.ErrorDbSelect.Wrap(db.Error)
+	}
+	return requests, cnt, nil
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"permission/components"
+	"permission/helpers"
+)
+
+// AuditLog 权限管理操作的审计记录
+type AuditLog struct {
+	ID         int64  `json:"id" gorm:"primary_key;column:id"`
+	ProductId  int64  `json:"productId" gorm:"column:product_id"`
+	AppId      int64  `json:"appId" gorm:"column:app_id"`
+	OperateUid int64  `json:"operateUid" gorm:"column:operate_uid"`
+	Action     string `json:"action" gorm:"column:action"`
+	TargetType string `json:"targetType" gorm:"column:target_type"` // group/user/...
+	TargetId   int64  `json:"targetId" gorm:"column:target_id"`
+	Detail     string `json:"detail" gorm:"column:detail"` // 操作详情json
+	CreateTime int64  `json:"createTime" gorm:"column:create_time"`
+}
+
+func (al *AuditLog) TableName() string {
+	return components.TABLE_PREX + "audit_log"
+}
+
+func (al *AuditLog) InsertAuditLog(ctx *gin.Context, db *gorm.DB) (err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	err = db.WithContext(ctx).Create(al).Error
+	if err != nil {
+		return components.ErrorDbInsert.Wrap(err)
+	}
+	return nil
+}
+
+func (al *AuditLog) GetAuditLogListByPage(ctx *gin.Context, condition map[string]interface{}, option *Option, page *NormalPage) (logs []AuditLog, cnt int, err error) {
+	if !option.IsNeedCnt && !option.IsNeedList {
+		return logs, cnt, nil
+	}
+	db := helpers.MysqlClientPermission.WithContext(ctx).Model(&AuditLog{}).Where(condition)
+	if option.IsNeedCnt {
+		var c int64
+		db = db.Count(&c)
+		cnt = int(c)
+	}
+	if option.IsNeedList {
+		db = db.Order("id desc").Scopes(NormalPaginate(page)).Find(&logs)
+	}
+	if db.Error != nil {
+		return logs, cnt, components.ErrorDbSelect.Wrap(db.Error)
+	}
+	return logs, cnt, nil
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"permission/components"
+	"permission/helpers"
+)
+
+// CasbinModel 注册的casbin模型，启用后才能绑定到domain，启用后不可修改
+type CasbinModel struct {
+	ID         int64  `json:"id" gorm:"primary_key;column:id"`
+	Name       string `json:"name" gorm:"column:name"`
+	Text       string `json:"text" gorm:"column:text"`
+	Status     int8   `json:"status" gorm:"column:status"`
+	Remark     string `json:"remark" gorm:"column:remark"`
+	CreateUid  int64  `json:"createUid" gorm:"column:create_uid"`
+	UpdateUid  int64  `json:"updateUid" gorm:"column:update_uid"`
+	CreateTime int64  `json:"createTime" gorm:"column:create_time"`
+	UpdateTime int64  `json:"updateTime" gorm:"column:update_time"`
+}
+
+func (cm *CasbinModel) TableName() string {
+	return components.TABLE_PREX + "casbin_model"
+}
+
+func (cm *CasbinModel) InsertCasbinModel(ctx *gin.Context) (err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Create(cm).Error
+	if err != nil {
+		return components.ErrorDbInsert.Wrap(err)
+	}
+	return nil
+}
+
+// UpdateCasbinModelByIdAndStatus 仅当模型仍处于fromStatus时才更新，rows为0表示状态已被他人变更
+func (cm *CasbinModel) UpdateCasbinModelByIdAndStatus(ctx *gin.Context, id int64, fromStatus int8, fields map[string]interface{}) (rows int64, err error) {
+	db := helpers.MysqlClientPermission
+	result := db.WithContext(ctx).Model(&CasbinModel{}).Where("`id` = ? AND `status` = ?", id, fromStatus).Updates(fields)
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbUpdate.Wrap(err)
+	}
+	return rows, nil
+}
+
+func (cm *CasbinModel) GetCasbinModelByName(ctx *gin.Context, name string) (casbinModel CasbinModel, err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Where("`name` = ?", name).Find(&casbinModel).Error
+	if err != nil {
+		return casbinModel, components.ErrorDbSelect.Wrap(err)
+	}
+	return casbinModel, nil
+}
+
+func (cm *CasbinModel) GetCasbinModelList(ctx *gin.Context) (casbinModels []CasbinModel, err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Order("id").Find(&casbinModels).Error
+	if err != nil {
+		return casbinModels, components.ErrorDbSelect.Wrap(err)
+	}
+	return casbinModels, nil
+}
+
+// DomainModel domain绑定的模型，未绑定的domain使用默认模型
+type DomainModel struct {
+	ID         int64  `json:"id" gorm:"primary_key;column:id"`
+	Domain     string `json:"domain" gorm:"column:domain"`
+	ModelName  string `json:"modelName" gorm:"column:model_name"`
+	UpdateUid  int64  `json:"updateUid" gorm:"column:update_uid"`
+	UpdateTime int64  `json:"updateTime" gorm:"column:update_time"`
+}
+
+func (dm *DomainModel) TableName() string {
+	return components.TABLE_PREX + "domain_model"
+}
+
+// UpsertDomainModel 按domain覆盖绑定的模型
+func (dm *DomainModel) UpsertDomainModel(ctx *gin.Context, db *gorm.DB) (rows int64, err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "domain"}},
+		DoUpdates: clause.AssignmentColumns([]string{"model_name", "update_uid", "update_time"}),
+	}).Create(dm)
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbUpsert.Wrap(err)
+	}
+	return rows, nil
+}
+
+// DeleteDomainModel 解除绑定，没有命中记录不视为错误
+func (dm *DomainModel) DeleteDomainModel(ctx *gin.Context, domain string, db *gorm.DB) (rows int64, err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Where("`domain` = ?", domain).Delete(&DomainModel{})
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbDelete.Wrap(err)
+	}
+	return rows, nil
+}
+
+func (dm *DomainModel) GetDomainModelList(ctx *gin.Context) (domainModels []DomainModel, err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Order("id").Find(&domainModels).Error
+	if err != nil {
+		return domainModels, components.ErrorDbSelect.Wrap(err)
+	}
+	return domainModels, nil
+}
@@ -16,13 +16,18 @@ type CasbinRule struct {
 	Resource        string `json:"resource" gorm:"column:v2"`       // furi:uri
 	PermissionType  string `json:"permissionType" gorm:"column:v3"` // read or write (当先统一为any， 后续根据具体进行划分)
 	Status          string `json:"status" gorm:"column:v4"`         //allow/deny
-	V5              string `gorm:"v5" default:""`
+	Condition       string `json:"condition" gorm:"column:v5"`      // 附加条件json，为空表示无条件
 }
 
 func (cr *CasbinRule) TableName() string {
 	return components.TABLE_PREX + "casbin_rule"
 }
 
+// PolicyRule 规则在 enforcer 中的取值，顺序与 policy_definition 一致
+func (cr *CasbinRule) PolicyRule() []string {
+	return []string{cr.GroupId, cr.ProductAppField, cr.Resource, cr.PermissionType, cr.Status, cr.Condition}
+}
+
 func (cr *CasbinRule) InsertCasbinRule(ctx *gin.Context) (err error) {
 	db := helpers.MysqlClientPermission
 	err = db.WithContext(ctx).Create(cr).Error
@@ -116,6 +121,35 @@ func (cr *CasbinRule) DeleteCasbinRuleByCondition(ctx *gin.Context, condition ma
 	return rows, err
 }
 
+// DeleteCasbinRulesByDomain 删除某个产线(v1)下全部校验规则，没有命中记录不视为错误
+func (cr *CasbinRule) DeleteCasbinRulesByDomain(ctx *gin.Context, domain string, db *gorm.DB) (rows int64, err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Where("v1 = ?", domain).Delete(CasbinRule{})
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbDelete.Wrap(err)
+	}
+	return rows, nil
+}
+
+// DeleteCasbinRuleByIds 按id批量删除校验规则，没有命中记录不视为错误
+func (cr *CasbinRule) DeleteCasbinRuleByIds(ctx *gin.Context, ids []int64, db *gorm.DB) (rows int64, err error) {
+	if len(ids) == 0 {
+		return rows, err
+	}
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Where("id IN ?", ids).Delete(CasbinRule{})
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbDelete.Wrap(err)
+	}
+	return rows, nil
+}
+
 func (cr *CasbinRule) GetCasbinRuleById(ctx *gin.Context, id int64) (rule CasbinRule, err error) {
 	db := helpers.MysqlClientPermission
 	err = db.WithContext(ctx).Where("`id` = ?", id).Take(&rule).Error
@@ -140,6 +174,15 @@ func (cr *CasbinRule) GetCasbinRulesListByConds(ctx *gin.Context, condition map[
 	return rules, nil
 }
 
+// GetCasbinRulesListForUpdate 在事务中对命中的规则加写锁读取
+func (cr *CasbinRule) GetCasbinRulesListForUpdate(ctx *gin.Context, condition map[string]interface{}, tx *gorm.DB) (rules []CasbinRule, err error) {
+	err = tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where(condition).Find(&rules).Error
+	if err != nil {
+		return rules, components.ErrorDbSelect.Wrap(err)
+	}
+	return rules, nil
+}
+
 func (cr *CasbinRule) GetCasbinRulesByConds(ctx *gin.Context, condition map[string]interface{}) (rule CasbinRule, err error) {
 	db := helpers.MysqlClientPermission
 	err = db.WithContext(ctx).Where(condition).Find(&rule).Error
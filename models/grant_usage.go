@@ -0,0 +1,55 @@
+package models
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"permission/components"
+	"permission/helpers"
+)
+
+// GrantUsage 权限组(或用户)对资源的授权被放行的次数和最近放行时间
+type GrantUsage struct {
+	ID           int64  `json:"id" gorm:"primary_key;column:id"`
+	ProductId    int64  `json:"productId" gorm:"column:product_id"`
+	AppId        int64  `json:"appId" gorm:"column:app_id"`
+	Subject      string `json:"subject" gorm:"column:subject"` // 权限组id或user:<用户id>
+	Resource     string `json:"resource" gorm:"column:resource"`
+	HitCount     int64  `json:"hitCount" gorm:"column:hit_count"`
+	LastUsedTime int64  `json:"lastUsedTime" gorm:"column:last_used_time"`
+}
+
+func (gu *GrantUsage) TableName() string {
+	return components.TABLE_PREX + "grant_usage"
+}
+
+// BatchUpsertGrantUsage 累加放行次数，最近放行时间取较大值
+func (gu *GrantUsage) BatchUpsertGrantUsage(ctx *gin.Context, usages []GrantUsage, db *gorm.DB) (rows int64, err error) {
+	if len(usages) == 0 {
+		return rows, err
+	}
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "product_id"}, {Name: "app_id"}, {Name: "subject"}, {Name: "resource"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"hit_count":      gorm.Expr("hit_count + VALUES(hit_count)"),
+			"last_used_time": gorm.Expr("GREATEST(last_used_time, VALUES(last_used_time))"),
+		}),
+	}).Create(usages)
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbUpsert.Wrap(err)
+	}
+	return rows, nil
+}
+
+func (gu *GrantUsage) GetGrantUsageListByConds(ctx *gin.Context, condition map[string]interface{}) (usages []GrantUsage, err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Where(condition).Find(&usages).Error
+	if err != nil {
+		return usages, components.ErrorDbSelect.Wrap(err)
+	}
+	return usages, nil
+}
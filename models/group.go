@@ -20,6 +20,7 @@ type Group struct {
 	UpdateUid  int64   `json:"updateUid" gorm:"column:update_uid" `
 	CreateTime int64   `json:"createTime" gorm:"column:create_time" `
 	UpdateTime int64   `json:"updateTime" gorm:"column:update_time" `
+	Version    int64   `json:"version" gorm:"column:version" ` // 乐观锁版本号，从1开始，每次编辑+1
 	Children   []Group `json:"children" gorm:"-"`
 }
 
@@ -53,6 +54,28 @@ func (g *Group) BatchInsertGroup(ctx *gin.Context, groups []Group, db *gorm.DB)
 	return rows, err
 }
 
+// BatchUpsertGroup 按主键覆盖写入，用于快照回滚等需要恢复原始数据的场景；已存在的记录版本号在当前值上+1，不恢复为写入值，
+// 避免持有回滚前版本号的请求通过乐观锁校验
+func (g *Group) BatchUpsertGroup(ctx *gin.Context, groups []Group, db *gorm.DB) (rows int64, err error) {
+	if len(groups) == 0 {
+		return rows, err
+	}
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	updates := clause.AssignmentColumns([]string{"product_id", "app_id", "group_name", "parent_id", "status", "update_uid", "update_time"})
+	updates = append(updates, clause.Assignment{Column: clause.Column{Name: "version"}, Value: gorm.Expr("`version` + 1")})
+	result := db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: updates,
+	}).Create(groups)
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbUpsert.Wrap(err)
+	}
+	return rows, nil
+}
+
 func (g *Group) UpsertGroup(ctx *gin.Context) (rows int64, err error) {
 	db := helpers.MysqlClientPermission
 	result := db.WithContext(ctx).Clauses(clause.OnConflict{
@@ -80,6 +103,32 @@ func (g *Group) UpdateGroupById(ctx *gin.Context, id int64, fields map[string]in
 	return rows, nil
 }
 
+// UpdateGroupByIdAndVersion 仅当版本号与expectVersion一致时更新，并将版本号+1；rows为0表示版本已过期
+func (g *Group) UpdateGroupByIdAndVersion(ctx *gin.Context, id int64, expectVersion int64, fields map[string]interface{}, db *gorm.DB) (rows int64, err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	fields["version"] = gorm.Expr("`version` + 1")
+	result := db.WithContext(ctx).Model(&Group{}).Where("`id` = ? AND `version` = ?", id, expectVersion).Updates(fields)
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbUpdate.Wrap(err)
+	}
+	return rows, nil
+}
+
+// GetGroupByIdForUpdate 在事务中对group行加写锁读取
+func (g *Group) GetGroupByIdForUpdate(ctx *gin.Context, id int64, tx *gorm.DB) (group Group, err error) {
+	err = tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("`id` = ?", id).Take(&group).Error
+	if err == gorm.ErrRecordNotFound {
+		err = nil
+	}
+	if err != nil {
+		return group, components.ErrorDbSelect.Wrap(err)
+	}
+	return group, nil
+}
+
 func (g *Group) GetGroupById(ctx *gin.Context, id int64) (group Group, err error) {
 	db := helpers.MysqlClientPermission
 	err = db.WithContext(ctx).Where("`id` = ?", id).Take(&group).Error
@@ -116,6 +165,15 @@ func (g *Group) GetGroupListByConds(ctx *gin.Context, condition map[string]inter
 	return groups, nil
 }
 
+// GetGroupListForUpdate 在事务中对命中的权限组加写锁读取
+func (g *Group) GetGroupListForUpdate(ctx *gin.Context, condition map[string]interface{}, tx *gorm.DB) (groups []Group, err error) {
+	err = tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where(condition).Find(&groups).Error
+	if err != nil {
+		return groups, components.ErrorDbSelect.Wrap(err)
+	}
+	return groups, nil
+}
+
 func (g *Group) GetGroupListByPage(ctx *gin.Context, option *Option, page *NormalPage) (groups []Group, cnt int, err error) {
 	if !option.IsNeedCnt && !option.IsNeedList {
 		return groups, cnt, nil
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"permission/components"
+	"permission/helpers"
+)
+
+// GroupApprover 权限组的审批人，负责审批加入该组或申请该组下资源的申请单
+type GroupApprover struct {
+	ID         int64 `json:"id" gorm:"primary_key;column:id"`
+	GroupId    int64 `json:"groupId" gorm:"column:group_id"`
+	UserId     int64 `json:"userId" gorm:"column:user_id"`
+	CreateUid  int64 `json:"createUid" gorm:"column:create_uid"`
+	CreateTime int64 `json:"createTime" gorm:"column:create_time"`
+}
+
+func (ga *GroupApprover) TableName() string {
+	return components.TABLE_PREX + "group_approver"
+}
+
+func (ga *GroupApprover) BatchInsertGroupApprover(ctx *gin.Context, approvers []GroupApprover, db *gorm.DB) (rows int64, err error) {
+	if len(approvers) == 0 {
+		return rows, err
+	}
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Create(approvers)
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbInsert.Wrap(err)
+	}
+	return rows, nil
+}
+
+func (ga *GroupApprover) DeleteGroupApproverByGroupId(ctx *gin.Context, groupId int64, db *gorm.DB) (rows int64, err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Where("group_id = ?", groupId).Delete(GroupApprover{})
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbDelete.Wrap(err)
+	}
+	return rows, nil
+}
+
+// DeleteGroupApproverByUserId 删除用户在全部权限组的审批人身份，没有命中记录不视为错误
+func (ga *GroupApprover) DeleteGroupApproverByUserId(ctx *gin.Context, userId int64, db *gorm.DB) (rows int64, err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Where("user_id = ?", userId).Delete(GroupApprover{})
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbDelete.Wrap(err)
+	}
+	return rows, nil
+}
+
+func (ga *GroupApprover) GetGroupApproverListByConds(ctx *gin.Context, condition map[string]interface{}) (approvers []GroupApprover, err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Where(condition).Find(&approvers).Error
+	if err != nil {
+		return approvers, components.ErrorDbSelect.Wrap(err)
+	}
+	return approvers, nil
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"permission/components"
+	"permission/helpers"
+)
+
+// GroupExclusion 互斥的两个权限组，同一产线下同一用户不能同时属于这两个组，GroupIdA < GroupIdB
+type GroupExclusion struct {
+	ID         int64  `json:"id" gorm:"primary_key;column:id"`
+	ProductId  int64  `json:"productId" gorm:"column:product_id"`
+	AppId      int64  `json:"appId" gorm:"column:app_id"`
+	GroupIdA   int64  `json:"groupIdA" gorm:"column:group_id_a"`
+	GroupIdB   int64  `json:"groupIdB" gorm:"column:group_id_b"`
+	Reason     string `json:"reason" gorm:"column:reason"`
+	CreateUid  int64  `json:"createUid" gorm:"column:create_uid"`
+	CreateTime int64  `json:"createTime" gorm:"column:create_time"`
+}
+
+func (ge *GroupExclusion) TableName() string {
+	return components.TABLE_PREX + "group_exclusion"
+}
+
+func (ge *GroupExclusion) InsertGroupExclusion(ctx *gin.Context) (err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Create(ge).Error
+	if err != nil {
+		return components.ErrorDbInsert.Wrap(err)
+	}
+	return nil
+}
+
+func (ge *GroupExclusion) DeleteGroupExclusionById(ctx *gin.Context, id int64) (rows int64, err error) {
+	db := helpers.MysqlClientPermission
+	result := db.WithContext(ctx).Where("`id` = ?", id).Delete(&GroupExclusion{})
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbDelete.Wrap(err)
+	}
+	return rows, nil
+}
+
+func (ge *GroupExclusion) GetGroupExclusionListByConds(ctx *gin.Context, condition map[string]interface{}) (exclusions []GroupExclusion, err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Where(condition).Order("id").Find(&exclusions).Error
+	if err != nil {
+		return exclusions, components.ErrorDbSelect.Wrap(err)
+	}
+	return exclusions, nil
+}
+
+// GetGroupExclusionListByGroupId 查询与某权限组互斥的全部约束
+func (ge *GroupExclusion) GetGroupExclusionListByGroupId(ctx *gin.Context, groupId int64, db *gorm.DB) (exclusions []GroupExclusion, err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	err = db.WithContext(ctx).Where("`group_id_a` = ? OR `group_id_b` = ?", groupId, groupId).Find(&exclusions).Error
+	if err != nil {
+		return exclusions, components.ErrorDbSelect.Wrap(err)
+	}
+	return exclusions, nil
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -9,10 +11,32 @@ import (
 )
 
 type GroupNode struct {
-	ID       int64 `json:"id" gorm:"primary_key;column:id" `
-	GroupId  int64 `json:"groupId" gorm:"column:group_id" `
-	NodeId   int64 `json:"nodeId" gorm:"column:node_id" `
-	NodeType int8  `json:"nodeType" gorm:"column:node_type"`
+	ID        int64  `json:"id" gorm:"primary_key;column:id" `
+	GroupId   int64  `json:"groupId" gorm:"column:group_id" `
+	NodeId    int64  `json:"nodeId" gorm:"column:node_id" `
+	NodeType  int8   `json:"nodeType" gorm:"column:node_type"`
+	DataScope string `json:"dataScope" gorm:"column:data_scope"` // 数据范围json，为空表示不限制
+	Implied   int8   `json:"implied" gorm:"column:implied"`      // 1:由页面依赖带出的接口，页面收回且无其他页面依赖时一并收回
+}
+
+// DataScope 数据范围，key 为维度(如 region、dept)，value 为该维度允许的取值，各维度之间为且的关系
+type DataScope map[string][]string
+
+// ParseDataScope 解析并校验数据范围，空字符串表示不限制
+func ParseDataScope(s string) (DataScope, error) {
+	if s == "" {
+		return nil, nil
+	}
+	scope := DataScope{}
+	if err := json.Unmarshal([]byte(s), &scope); err != nil {
+		return nil, fmt.Errorf("dataScope is not valid json: %v", err)
+	}
+	for dimension, values := range scope {
+		if dimension == "" || len(values) == 0 {
+			return nil, fmt.Errorf("dataScope dimension %q has no values", dimension)
+		}
+	}
+	return scope, nil
 }
 
 func (gn *GroupNode) TableName() string {
@@ -90,6 +114,57 @@ func (gn *GroupNode) BatchDeleteGroupNode(ctx *gin.Context, nodeIds []int64, db
 	return rows, err
 }
 
+// UpdateGroupNodeImplied 修改权限组下若干接口的来源标记
+func (gn *GroupNode) UpdateGroupNodeImplied(ctx *gin.Context, nodeIds []int64, implied int8, db *gorm.DB) (rows int64, err error) {
+	if len(nodeIds) == 0 {
+		return rows, err
+	}
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Model(GroupNode{}).
+		Where("group_id = ?", gn.GroupId).
+		Where("node_id IN ?", nodeIds).
+		Update("implied", implied)
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbUpdate.Wrap(err)
+	}
+	return rows, nil
+}
+
+// DeleteGroupNodeByGroupIds 删除若干权限组下全部映射关系，没有命中记录不视为错误
+func (gn *GroupNode) DeleteGroupNodeByGroupIds(ctx *gin.Context, groupIds []int64, db *gorm.DB) (rows int64, err error) {
+	if len(groupIds) == 0 {
+		return rows, err
+	}
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Where("group_id IN ?", groupIds).Delete(GroupNode{})
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbDelete.Wrap(err)
+	}
+	return rows, nil
+}
+
+// DeleteGroupNodeByIds 按id批量删除映射关系，没有命中记录不视为错误
+func (gn *GroupNode) DeleteGroupNodeByIds(ctx *gin.Context, ids []int64, db *gorm.DB) (rows int64, err error) {
+	if len(ids) == 0 {
+		return rows, err
+	}
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Where("id IN ?", ids).Delete(GroupNode{})
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbDelete.Wrap(err)
+	}
+	return rows, nil
+}
+
 func (gn *GroupNode) GetGroupNodeById(ctx *gin.Context, id int64) (groupNode GroupNode, err error) {
 	db := helpers.MysqlClientPermission
 	err = db.WithContext(ctx).Where("`id` = ?", id).Take(&groupNode).Error
@@ -111,6 +186,15 @@ func (gn *GroupNode) GetGroupNodeListByConds(ctx *gin.Context, condition map[str
 	return groupNodes, components.ErrorDbSelect.Wrap(err)
 }
 
+// GetGroupNodeListForUpdate 在事务中对命中的映射关系加写锁读取
+func (gn *GroupNode) GetGroupNodeListForUpdate(ctx *gin.Context, condition map[string]interface{}, tx *gorm.DB) (groupNodes []GroupNode, err error) {
+	err = tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where(condition).Find(&groupNodes).Error
+	if err != nil {
+		return groupNodes, components.ErrorDbSelect.Wrap(err)
+	}
+	return groupNodes, nil
+}
+
 func (gn *GroupNode) GetGroupNodeListByPage(ctx *gin.Context, option *Option, page *NormalPage) (groupNodeList []GroupNode, cnt int, err error) {
 	if !option.IsNeedCnt && !option.IsNeedList {
 		return groupNodeList, cnt, nil
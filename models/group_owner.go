@@ -0,0 +1,62 @@
+package models
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"permission/components"
+	"permission/helpers"
+)
+
+// GroupOwner 权限组负责人，可管理该组组员及其本人持有节点范围内的映射关系
+type GroupOwner struct {
+	ID         int64 `json:"id" gorm:"primary_key;column:id"`
+	GroupId    int64 `json:"groupId" gorm:"column:group_id"`
+	UserId     int64 `json:"userId" gorm:"column:user_id"`
+	CreateUid  int64 `json:"createUid" gorm:"column:create_uid"`
+	CreateTime int64 `json:"createTime" gorm:"column:create_time"`
+}
+
+func (gow *GroupOwner) TableName() string {
+	return components.TABLE_PREX + "group_owner"
+}
+
+func (gow *GroupOwner) InsertGroupOwner(ctx *gin.Context) (err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Create(gow).Error
+	if err != nil {
+		return components.ErrorDbInsert.Wrap(err)
+	}
+	return nil
+}
+
+func (gow *GroupOwner) DeleteGroupOwner(ctx *gin.Context, groupId, userId int64) (rows int64, err error) {
+	db := helpers.MysqlClientPermission
+	result := db.WithContext(ctx).Where("`group_id` = ? AND `user_id` = ?", groupId, userId).Delete(&GroupOwner{})
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbDelete.Wrap(err)
+	}
+	return rows, nil
+}
+
+// DeleteGroupOwnerByUserId 删除用户在全部权限组的负责人身份，没有命中记录不视为错误
+func (gow *GroupOwner) DeleteGroupOwnerByUserId(ctx *gin.Context, userId int64, db *gorm.DB) (rows int64, err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Where("`user_id` = ?", userId).Delete(&GroupOwner{})
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbDelete.Wrap(err)
+	}
+	return rows, nil
+}
+
+func (gow *GroupOwner) GetGroupOwnerListByConds(ctx *gin.Context, condition map[string]interface{}) (owners []GroupOwner, err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Where(condition).Order("id").Find(&owners).Error
+	if err != nil {
+		return owners, components.ErrorDbSelect.Wrap(err)
+	}
+	return owners, nil
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"permission/components"
+	"permission/helpers"
+)
+
+// MemberUsage 用户经某个权限组被放行的次数和最近放行时间
+type MemberUsage struct {
+	ID           int64 `json:"id" gorm:"primary_key;column:id"`
+	ProductId    int64 `json:"productId" gorm:"column:product_id"`
+	AppId        int64 `json:"appId" gorm:"column:app_id"`
+	UserId       int64 `json:"userId" gorm:"column:user_id"`
+	GroupId      int64 `json:"groupId" gorm:"column:group_id"`
+	HitCount     int64 `json:"hitCount" gorm:"column:hit_count"`
+	LastUsedTime int64 `json:"lastUsedTime" gorm:"column:last_used_time"`
+}
+
+func (mu *MemberUsage) TableName() string {
+	return components.TABLE_PREX + "member_usage"
+}
+
+// BatchUpsertMemberUsage 累加放行次数，最近放行时间取较大值
+func (mu *MemberUsage) BatchUpsertMemberUsage(ctx *gin.Context, usages []MemberUsage, db *gorm.DB) (rows int64, err error) {
+	if len(usages) == 0 {
+		return rows, err
+	}
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "group_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"hit_count":      gorm.Expr("hit_count + VALUES(hit_count)"),
+			"last_used_time": gorm.Expr("GREATEST(last_used_time, VALUES(last_used_time))"),
+		}),
+	}).Create(usages)
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbUpsert.Wrap(err)
+	}
+	return rows, nil
+}
+
+func (mu *MemberUsage) GetMemberUsageListByConds(ctx *gin.Context, condition map[string]interface{}) (usages []MemberUsage, err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Where(condition).Find(&usages).Error
+	if err != nil {
+		return usages, components.ErrorDbSelect.Wrap(err)
+	}
+	return usages, nil
+}
@@ -9,20 +9,26 @@ import (
 )
 
 type Node struct {
-	ID         int64  `json:"id" gorm:"primary_key;column:id" `
-	ProductID  int64  `json:"productId" gorm:"column:product_id" `
-	AppID      int64  `json:"appId" gorm:"column:app_id" `
-	Label      string `json:"label" gorm:"column:label" `
-	Resource   string `json:"resource" gorm:"column:resource" `
-	NodeType   int8   `json:"nodeType" gorm:"column:node_type"`
-	IsShow     int8   `json:"isShow" gorm:"column:is_show"`
-	ParentID   int64  `json:"parentId" gorm:"column:parent_id" `
-	CreateUid  int64  `json:"createUid" gorm:"column:create_uid" `
-	UpdateUid  int64  `json:"updateUid" gorm:"column:update_uid" `
-	CreateTime int64  `json:"createTime" gorm:"column:create_time" `
-	UpdateTime int64  `json:"updateTime" gorm:"column:update_time" `
-	Children   []Node `json:"children" gorm:"-"`
-	Selected   int8   `json:"selected" gorm:"-"`
+	ID           int64   `json:"id" gorm:"primary_key;column:id" `
+	ProductID    int64   `json:"productId" gorm:"column:product_id" `
+	AppID        int64   `json:"appId" gorm:"column:app_id" `
+	Label        string  `json:"label" gorm:"column:label" `
+	Resource     string  `json:"resource" gorm:"column:resource" `
+	NodeType     int8    `json:"nodeType" gorm:"column:node_type"`
+	IsShow       int8    `json:"isShow" gorm:"column:is_show"`
+	ParentID     int64   `json:"parentId" gorm:"column:parent_id" `
+	SortOrder    int     `json:"sortOrder" gorm:"column:sort_order"` // 同级节点按升序展示
+	Icon         string  `json:"icon" gorm:"column:icon"`
+	Route        string  `json:"route" gorm:"column:route"`      // 前端路由，仅页面节点使用
+	IsStale      int8    `json:"isStale" gorm:"column:is_stale"` // 1:路由清单中已不存在该接口
+	CreateUid    int64   `json:"createUid" gorm:"column:create_uid" `
+	UpdateUid    int64   `json:"updateUid" gorm:"column:update_uid" `
+	CreateTime   int64   `json:"createTime" gorm:"column:create_time" `
+	UpdateTime   int64   `json:"updateTime" gorm:"column:update_time" `
+	Children     []Node  `json:"children" gorm:"-"`
+	Selected     int8    `json:"selected" gorm:"-"`
+	Implied      int8    `json:"implied" gorm:"-"`                // 1:仅由已选页面的依赖带出
+	Dependencies []int64 `json:"dependencies,omitempty" gorm:"-"` // 页面依赖的接口节点id
 }
 
 func (n *Node) TableName() string {
@@ -70,8 +76,10 @@ func (n *Node) UpsertNode(ctx *gin.Context) (rows int64, err error) {
 	return rows, nil
 }
 
-func (n *Node) UpdateNodeById(ctx *gin.Context, id int64, fields map[string]interface{}) (rows int64, err error) {
-	db := helpers.MysqlClientPermission
+func (n *Node) UpdateNodeById(ctx *gin.Context, id int64, fields map[string]interface{}, db *gorm.DB) (rows int64, err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
 	result := db.WithContext(ctx).Model(&Node{}).Where("`id` = ?", id).Updates(fields)
 	rows, err = result.RowsAffected, result.Error
 	if err != nil {
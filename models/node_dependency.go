@@ -0,0 +1,60 @@
+package models
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"permission/components"
+	"permission/helpers"
+)
+
+// NodeDependency 页面节点依赖的接口节点，授予页面时一并授予
+type NodeDependency struct {
+	ID         int64 `json:"id" gorm:"primary_key;column:id"`
+	PageNodeId int64 `json:"pageNodeId" gorm:"column:page_node_id"`
+	ApiNodeId  int64 `json:"apiNodeId" gorm:"column:api_node_id"`
+	CreateUid  int64 `json:"createUid" gorm:"column:create_uid"`
+	CreateTime int64 `json:"createTime" gorm:"column:create_time"`
+}
+
+func (nd *NodeDependency) TableName() string {
+	return components.TABLE_PREX + "node_dependency"
+}
+
+func (nd *NodeDependency) BatchInsertNodeDependency(ctx *gin.Context, deps []NodeDependency, db *gorm.DB) (rows int64, err error) {
+	if len(deps) == 0 {
+		return rows, err
+	}
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Create(deps)
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbInsert.Wrap(err)
+	}
+	return rows, nil
+}
+
+// DeleteNodeDependencyByConds 没有命中记录不视为错误
+func (nd *NodeDependency) DeleteNodeDependencyByConds(ctx *gin.Context, condition map[string]interface{}, db *gorm.DB) (rows int64, err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Where(condition).Delete(NodeDependency{})
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbDelete.Wrap(err)
+	}
+	return rows, nil
+}
+
+func (nd *NodeDependency) GetNodeDependencyListByConds(ctx *gin.Context, condition map[string]interface{}, db *gorm.DB) (deps []NodeDependency, err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	err = db.WithContext(ctx).Where(condition).Order("id").Find(&deps).Error
+	if err != nil {
+		return deps, components.ErrorDbSelect.Wrap(err)
+	}
+	return deps, nil
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"permission/components"
+	"permission/helpers"
+)
+
+// ReviewCampaign 权限复核活动，GroupIds 为参与复核的权限组id json数组
+type ReviewCampaign struct {
+	ID         int64  `json:"id" gorm:"primary_key;column:id"`
+	ProductId  int64  `json:"productId" gorm:"column:product_id"`
+	AppId      int64  `json:"appId" gorm:"column:app_id"`
+	Name       string `json:"name" gorm:"column:name"`
+	GroupIds   string `json:"groupIds" gorm:"column:group_ids"`
+	Status     int8   `json:"status" gorm:"column:status"`     // 0:进行中 1:已结束
+	Deadline   int64  `json:"deadline" gorm:"column:deadline"` // 截止时间，届时未复核的组员资格收回
+	CreateUid  int64  `json:"createUid" gorm:"column:create_uid"`
+	CreateTime int64  `json:"createTime" gorm:"column:create_time"`
+	CloseUid   int64  `json:"closeUid" gorm:"column:close_uid"` // 提前结束的操作人，0表示到期自动结束
+	CloseTime  int64  `json:"closeTime" gorm:"column:close_time"`
+}
+
+func (rc *ReviewCampaign) TableName() string {
+	return components.TABLE_PREX + "review_campaign"
+}
+
+func (rc *ReviewCampaign) InsertReviewCampaign(ctx *gin.Context, db *gorm.DB) (err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	err = db.WithContext(ctx).Create(rc).Error
+	if err != nil {
+		return components.ErrorDbInsert.Wrap(err)
+	}
+	return nil
+}
+
+// UpdateReviewCampaignByIdAndStatus 仅在状态仍为 fromStatus 时更新，用于抢占状态流转
+func (rc *ReviewCampaign) UpdateReviewCampaignByIdAndStatus(ctx *gin.Context, id int64, fromStatus int8, fields map[string]interface{}, db *gorm.DB) (rows int64, err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Model(&ReviewCampaign{}).
+		Where("`id` = ? AND `status` = ?", id, fromStatus).
+		Updates(fields)
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbUpdate.Wrap(err)
+	}
+	return rows, nil
+}
+
+func (rc *ReviewCampaign) GetReviewCampaignById(ctx *gin.Context, id int64) (campaign ReviewCampaign, err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Where("`id` = ?", id).Take(&campaign).Error
+	if err == gorm.ErrRecordNotFound {
+		err = nil
+	}
+	if err != nil {
+		return campaign, components.ErrorDbSelect.Wrap(err)
+	}
+	return campaign, nil
+}
+
+// GetReviewCampaignListBefore 查询截止时间早于deadline的进行中活动，用于到期任务
+func (rc *ReviewCampaign) GetReviewCampaignListBefore(ctx *gin.Context, deadline int64, limit int) (campaigns []ReviewCampaign, err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).
+		Where("`status` = ?", components.REVIEW_CAMPAIGN_STATUS_OPEN).
+		Where("`deadline` < ?", deadline).
+		Order("id").Limit(limit).Find(&campaigns).Error
+	if err != nil {
+		return campaigns, components.ErrorDbSelect.Wrap(err)
+	}
+	return campaigns, nil
+}
+
+func (rc *ReviewCampaign) GetReviewCampaignListByPage(ctx *gin.Context, condition map[string]interface{}, option *Option, page *NormalPage) (campaigns []ReviewCampaign, cnt int, err error) {
+	if !option.IsNeedCnt && !option.IsNeedList {
+		return campaigns, cnt, nil
+	}
+	db := helpers.MysqlClientPermission.WithContext(ctx).Model(&ReviewCampaign{}).Where(condition)
+	if option.IsNeedCnt {
+		var c int64
+		db = db.Count(&c)
+		cnt = int(c)
+	}
+	if option.IsNeedList {
+		db = db.Scopes(NormalPaginate(page)).Find(&campaigns)
+	}
+	if db.Error != nil {
+		return campaigns, cnt, components.ErrorDbSelect.Wrap(db.Error)
+	}
+	return campaigns, cnt, nil
+}
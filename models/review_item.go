@@ -0,0 +1,91 @@
+package models
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"permission/components"
+	"permission/helpers"
+)
+
+// ReviewItem 复核活动创建时快照的一条组员资格及其复核结论
+type ReviewItem struct {
+	ID               int64  `json:"id" gorm:"primary_key;column:id"`
+	CampaignId       int64  `json:"campaignId" gorm:"column:campaign_id"`
+	GroupId          int64  `json:"groupId" gorm:"column:group_id"`
+	UserType         int8   `json:"userType" gorm:"column:user_type"`
+	UserId           int64  `json:"userId" gorm:"column:user_id"`
+	MemberCreateTime int64  `json:"memberCreateTime" gorm:"column:member_create_time"`
+	MemberExpireTime int64  `json:"memberExpireTime" gorm:"column:member_expire_time"` // 0表示永久
+	ReviewerUid      int64  `json:"reviewerUid" gorm:"column:reviewer_uid"`            // 0表示由超级管理员复核
+	Decision         int8   `json:"decision" gorm:"column:decision"`                   // 0:待复核 1:保留 2:收回 3:截止时未复核已收回
+	DecideUid        int64  `json:"decideUid" gorm:"column:decide_uid"`
+	DecideTime       int64  `json:"decideTime" gorm:"column:decide_time"`
+	Remark           string `json:"remark" gorm:"column:remark"`
+}
+
+func (ri *ReviewItem) TableName() string {
+	return components.TABLE_PREX + "review_item"
+}
+
+func (ri *ReviewItem) BatchInsertReviewItem(ctx *gin.Context, items []ReviewItem, db *gorm.DB) (rows int64, err error) {
+	if len(items) == 0 {
+		return rows, err
+	}
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).CreateInBatches(items, 500)
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbInsert.Wrap(err)
+	}
+	return rows, nil
+}
+
+// UpdateReviewItemByIds 仅更新结论仍为 fromDecision 的条目，调用方按返回行数判断是否被他人抢先处理
+func (ri *ReviewItem) UpdateReviewItemByIds(ctx *gin.Context, ids []int64, fromDecision int8, fields map[string]interface{}, db *gorm.DB) (rows int64, err error) {
+	if len(ids) == 0 {
+		return rows, err
+	}
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Model(&ReviewItem{}).
+		Where("`id` IN ? AND `decision` = ?", ids, fromDecision).
+		Updates(fields)
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbUpdate.Wrap(err)
+	}
+	return rows, nil
+}
+
+func (ri *ReviewItem) GetReviewItemListByConds(ctx *gin.Context, condition map[string]interface{}, db *gorm.DB) (items []ReviewItem, err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	err = db.WithContext(ctx).Where(condition).Order("id").Find(&items).Error
+	if err != nil {
+		return items, components.ErrorDbSelect.Wrap(err)
+	}
+	return items, nil
+}
+
+func (ri *ReviewItem) GetReviewItemListByPage(ctx *gin.Context, condition map[string]interface{}, option *Option, page *NormalPage) (items []ReviewItem, cnt int, err error) {
+	if !option.IsNeedCnt && !option.IsNeedList {
+		return items, cnt, nil
+	}
+	db := helpers.MysqlClientPermission.WithContext(ctx).Model(&ReviewItem{}).Where(condition)
+	if option.IsNeedCnt {
+		var c int64
+		db = db.Count(&c)
+		cnt = int(c)
+	}
+	if option.IsNeedList {
+		db = db.Scopes(NormalPaginate(page)).Find(&items)
+	}
+	if db.Error != nil {
+		return items, cnt, components.ErrorDbSelect.Wrap(db.Error)
+	}
+	return items, cnt, nil
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"permission/components"
+	"permission/helpers"
+)
+
+// Snapshot 某个产线(product:app)下完整权限数据的快照，Content为序列化后的权限组、映射关系、校验规则和组员
+type Snapshot struct {
+	ID         int64  `json:"id" gorm:"primary_key;column:id"`
+	ProductId  int64  `json:"productId" gorm:"column:product_id"`
+	AppId      int64  `json:"appId" gorm:"column:app_id"`
+	Trigger    int8   `json:"trigger" gorm:"column:trigger"` // 0:手动 1:大批量变更前自动 2:回滚前自动
+	Reason     string `json:"reason" gorm:"column:reason"`
+	Content    string `json:"-" gorm:"column:content"`
+	CreateUid  int64  `json:"createUid" gorm:"column:create_uid"`
+	CreateTime int64  `json:"createTime" gorm:"column:create_time"`
+}
+
+func (s *Snapshot) TableName() string {
+	return components.TABLE_PREX + "snapshot"
+}
+
+func (s *Snapshot) InsertSnapshot(ctx *gin.Context, db *gorm.DB) (err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	err = db.WithContext(ctx).Create(s).Error
+	if err != nil {
+		return components.ErrorDbInsert.Wrap(err)
+	}
+	return nil
+}
+
+func (s *Snapshot) GetSnapshotById(ctx *gin.Context, id int64) (snapshot Snapshot, err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Where("`id` = ?", id).Take(&snapshot).Error
+	if err == gorm.ErrRecordNotFound {
+		err = nil
+	}
+	if err != nil {
+		return snapshot, components.ErrorDbSelect.Wrap(err)
+	}
+	return snapshot, nil
+}
+
+// GetSnapshotListByPage 列表不返回快照内容，避免大字段拖慢查询
+func (s *Snapshot) GetSnapshotListByPage(ctx *gin.Context, condition map[string]interface{}, option *Option, page *NormalPage) (snapshots []Snapshot, cnt int, err error) {
+	if !option.IsNeedCnt && !option.IsNeedList {
+		return snapshots, cnt, nil
+	}
+	db := helpers.MysqlClientPermission.WithContext(ctx).Model(&Snapshot{}).Where(condition)
+	if option.IsNeedCnt {
+		var c int64
+		db = db.Count(&c)
+		cnt = int(c)
+	}
+	if option.IsNeedList {
+		db = db.Omit("content").Scopes(NormalPaginate(page)).Find(&snapshots)
+	}
+	if db.Error != nil {
+		return snapshots, cnt, components.ErrorDbSelect.Wrap(db.Error)
+	}
+	return snapshots, cnt, nil
+}
@@ -21,12 +21,18 @@ type UserGroup struct {
 	CreateUid  int64 `json:"createUid" gorm:"column:create_uid" `
 	UpdateUid  int64 `json:"updateUid" gorm:"column:update_uid" `
 	CreateTime int64 `json:"createTime" gorm:"column:create_time" `
-	UpdateTime int64 `json:"updateTime"gorm:"column:update_time" `
+	UpdateTime int64 `json:"updateTime" gorm:"column:update_time" `
+	ExpireTime int64 `json:"expireTime" gorm:"column:expire_time"` // 组员资格到期时间，0表示永久
 }
 
 func (ug *UserGroup) TableName() string {
 	fmt.Println(ug.UserId)
-	return components.TABLE_PREX + fmt.Sprintf("%s%d", "rel_user_group", ug.UserId%16)
+	return UserGroupTableName(ug.UserId % components.USER_GROUP_SHARD_NUM)
+}
+
+// UserGroupTableName 返回第shard个分表的表名
+func UserGroupTableName(shard int64) string {
+	return components.TABLE_PREX + fmt.Sprintf("%s%d", "rel_user_group", shard)
 }
 
 func (ug *UserGroup) InsertUserGroup(ctx *gin.Context) (err error) {
@@ -38,11 +44,13 @@ func (ug *UserGroup) InsertUserGroup(ctx *gin.Context) (err error) {
 	return nil
 }
 
-func (ug *UserGroup) UpsertUserGroup(ctx *gin.Context) (rows int64, err error) {
-	db := helpers.MysqlClientPermission
+func (ug *UserGroup) UpsertUserGroup(ctx *gin.Context, db *gorm.DB) (rows int64, err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
 	result := db.WithContext(ctx).Table(ug.TableName()).Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"app_id", "group_id", "user_type", "status", "update_uid", "update_time"}),
+		DoUpdates: clause.AssignmentColumns([]string{"app_id", "group_id", "user_type", "status", "update_uid", "update_time", "expire_time"}),
 	}).Create(ug)
 	err = result.Error
 	rows = result.RowsAffected
@@ -88,6 +96,28 @@ func (ug *UserGroup) GetUserGroupByCondition(ctx *gin.Context, condition map[str
 	return userGroup, nil
 }
 
+// GetValidUserGroupListByCondition 查询用户在产线下全部有效(启用且未过期)的组员资格
+func (ug *UserGroup) GetValidUserGroupListByCondition(ctx *gin.Context, condition map[string]interface{}) (userGroups []UserGroup, err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Table(ug.TableName()).Where(condition).
+		Where("`status` = ?", components.GROUP_STATUS_ACTIVE).
+		Where("(`expire_time` = 0 OR `expire_time` > ?)", time.Now().Unix()).
+		Order("id").Find(&userGroups).Error
+	if err != nil {
+		return userGroups, components.ErrorDbSelect.Wrap(err)
+	}
+	return userGroups, nil
+}
+
+// UserGroupIds 提取组员资格中的权限组id
+func UserGroupIds(userGroups []UserGroup) []int64 {
+	groupIds := make([]int64, 0, len(userGroups))
+	for _, v := range userGroups {
+		groupIds = append(groupIds, v.GroupId)
+	}
+	return groupIds
+}
+
 func (ug *UserGroup) GetUserGroupListByPage(ctx *gin.Context, option *Option, page *NormalPage) (userGroups []UserGroup, cnt int, err error) {
 	if !option.IsNeedCnt && !option.IsNeedList {
 		return userGroups, cnt, nil
@@ -106,3 +136,84 @@ func (ug *UserGroup) GetUserGroupListByPage(ctx *gin.Context, option *Option, pa
 	}
 	return userGroups, cnt, nil
 }
+
+// GetUserGroupListByShard 按条件查询指定分表，用于需要遍历全部分表的场景
+func (ug *UserGroup) GetUserGroupListByShard(ctx *gin.Context, shard int64, condition map[string]interface{}, db *gorm.DB) (userGroups []UserGroup, err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	err = db.WithContext(ctx).Table(UserGroupTableName(shard)).Where(condition).Order("id").Find(&userGroups).Error
+	if err != nil {
+		return userGroups, components.ErrorDbSelect.Wrap(err)
+	}
+	return userGroups, nil
+}
+
+// GetUserGroupListByShardForUpdate 在事务中对指定分表中命中的记录加写锁读取
+func (ug *UserGroup) GetUserGroupListByShardForUpdate(ctx *gin.Context, shard int64, condition map[string]interface{}, tx *gorm.DB) (userGroups []UserGroup, err error) {
+	err = tx.WithContext(ctx).Table(UserGroupTableName(shard)).Clauses(clause.Locking{Strength: "UPDATE"}).Where(condition).Order("id").Find(&userGroups).Error
+	if err != nil {
+		return userGroups, components.ErrorDbSelect.Wrap(err)
+	}
+	return userGroups, nil
+}
+
+// CountUserGroupByShard 统计指定分表中符合条件的记录数
+func (ug *UserGroup) CountUserGroupByShard(ctx *gin.Context, shard int64, condition map[string]interface{}) (cnt int64, err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Table(UserGroupTableName(shard)).Where(condition).Count(&cnt).Error
+	if err != nil {
+		return cnt, components.ErrorDbSelect.Wrap(err)
+	}
+	return cnt, nil
+}
+
+// GetUserGroupListByShardPage 按id升序分页查询指定分表，用于跨分表分页
+func (ug *UserGroup) GetUserGroupListByShardPage(ctx *gin.Context, shard int64, condition map[string]interface{}, offset, limit int) (userGroups []UserGroup, err error) {
+	db := helpers.MysqlClientPermission
+	err = db.WithContext(ctx).Table(UserGroupTableName(shard)).Where(condition).Order("id").Offset(offset).Limit(limit).Find(&userGroups).Error
+	if err != nil {
+		return userGroups, components.ErrorDbSelect.Wrap(err)
+	}
+	return userGroups, nil
+}
+
+// UpdateUserGroupByShard 按id更新指定分表中的记录
+func (ug *UserGroup) UpdateUserGroupByShard(ctx *gin.Context, shard int64, id int64, fields map[string]interface{}, db *gorm.DB) (rows int64, err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Table(UserGroupTableName(shard)).Where("`id` = ?", id).Updates(fields)
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbUpdate.Wrap(err)
+	}
+	return rows, nil
+}
+
+func (ug *UserGroup) DeleteUserGroupByShard(ctx *gin.Context, shard int64, condition map[string]interface{}, db *gorm.DB) (rows int64, err error) {
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Table(UserGroupTableName(shard)).Where(condition).Delete(&UserGroup{})
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbDelete.Wrap(err)
+	}
+	return rows, nil
+}
+
+func (ug *UserGroup) BatchInsertUserGroupByShard(ctx *gin.Context, shard int64, userGroups []UserGroup, db *gorm.DB) (rows int64, err error) {
+	if len(userGroups) == 0 {
+		return rows, err
+	}
+	if db == nil {
+		db = helpers.MysqlClientPermission
+	}
+	result := db.WithContext(ctx).Table(UserGroupTableName(shard)).Create(userGroups)
+	rows, err = result.RowsAffected, result.Error
+	if err != nil {
+		return rows, components.ErrorDbInsert.Wrap(err)
+	}
+	return rows, nil
+}
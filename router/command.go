@@ -0,0 +1,37 @@
+package router
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/controllers/command"
+	"permission/helpers"
+	golibCommand "permission/pkg/golib/v2/command"
+	"time"
+)
+
+// Tasks 随web服务启动的周期任务
+func Tasks(engine *gin.Engine) {
+	c := golibCommand.InitCycle(engine)
+	c.AddFunc(time.Minute, command.ExpireAccessRequests)
+	c.AddFunc(helpers.PolicyReloadInterval(), command.ReloadPolicy)
+	c.AddFunc(time.Minute, command.UnloadIdleDomains)
+	c.AddFunc(command.UsageFlushInterval(), command.FlushUsage)
+	c.AddFunc(time.Minute, command.ExpireReviewCampaigns)
+	c.Start()
+}
+
+// 一次性任务，通过启动参数指定，如 ./permission sodreport
+var jobs = map[string]func(*gin.Context, ...string) error{
+	"sodreport":   command.SodReport,
+	"consistency": command.ConsistencyCheck,
+}
+
+// Commands 执行启动参数指定的一次性任务
+func Commands(engine *gin.Engine, args []string) {
+	f, ok := jobs[args[0]]
+	if !ok {
+		fmt.Printf("unknown command %q\n", args[0])
+		return
+	}
+	golibCommand.NewJob(engine).RunWithRecovery(f, args[1:]...)
+}
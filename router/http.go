@@ -2,16 +2,26 @@ package router
 
 import (
 	"github.com/gin-gonic/gin"
+	"permission/controllers/http/access"
+	"permission/controllers/http/audit"
 	"permission/controllers/http/group"
 	"permission/controllers/http/node"
+	"permission/controllers/http/owner"
 	"permission/controllers/http/perm"
 	"permission/controllers/http/policy"
+	"permission/controllers/http/review"
+	"permission/controllers/http/snapshot"
+	"permission/controllers/http/sod"
+	"permission/controllers/http/usage"
 	"permission/controllers/http/user"
 	"permission/middleware"
+	"permission/pkg/golib/v2/base"
 	m "permission/pkg/golib/v2/middleware"
 )
 
 func Http(engine *gin.Engine) {
+	// 存活探针，就绪探针 /ready 由 golib 注册
+	engine.GET("/health", base.HealthProbe())
 
 	router := engine.Group("/permission")
 	//
@@ -24,6 +34,8 @@ func Http(engine *gin.Engine) {
 	checkGroup := router.Group("/request", m.AddNotice("customerNotice", "v1"))
 	{
 		checkGroup.POST("/checkpermission", perm.CheckPermission)
+		checkGroup.POST("/getdatascope", perm.GetDataScope)
+		checkGroup.POST("/geteffectivepermission", perm.GetEffectivePermission)
 	}
 
 	// 权限组设置
@@ -34,6 +46,10 @@ func Http(engine *gin.Engine) {
 		permGroup.POST("/updategroup", group.Updategroup)
 		permGroup.POST("/getgrouplist", group.GetGroupList)
 		permGroup.POST("/getmenunodelist", group.GetMenuNodeList)
+		permGroup.POST("/getusermenutree", group.GetUserMenuTree)
+		permGroup.POST("/setdatascope", group.SetDataScope)
+		permGroup.POST("/clonegroup", group.CloneGroup)
+		permGroup.POST("/diffgroup", group.DiffGroup)
 	}
 
 	// 校验规则管理
@@ -43,6 +59,10 @@ func Http(engine *gin.Engine) {
 		policyManager.POST("/stoppolicy", policy.StopPolicy)
 		policyManager.POST("/deletepolicy", policy.DeletePolicy)
 		policyManager.POST("/getpolicylist", policy.GetPolicyList)
+		policyManager.POST("/savemodel", policy.SaveModel)
+		policyManager.POST("/activatemodel", policy.ActivateModel)
+		policyManager.POST("/binddomainmodel", policy.BindDomainModel)
+		policyManager.POST("/getmodellist", policy.GetModelList)
 	}
 
 	// 路由页面、接口管理
@@ -52,11 +72,81 @@ func Http(engine *gin.Engine) {
 		nodeGroup.POST("/updatenode", node.UpdateNode)
 		nodeGroup.POST("/deletenode", node.DeleteNode)
 		nodeGroup.POST("/getnodelist", node.GetNodeList)
+		nodeGroup.POST("/syncroutes", node.SyncRoutes)
+		nodeGroup.POST("/setdependency", node.SetDependency)
+		nodeGroup.POST("/getdependency", node.GetDependency)
 	}
 
 	// 用户权限组设置
 	userPermGroup := router.Group("user", m.AddNotice("customerNotice", "v1"))
 	{
 		userPermGroup.POST("/addrelusergroup", user.CreateRelUserGroup)
+		userPermGroup.POST("/removerelusergroup", user.RemoveRelUserGroup)
+		userPermGroup.POST("/changerelusergroup", user.ChangeRelUserGroup)
+		userPermGroup.POST("/getgroupmemberlist", user.GetGroupMemberList)
+		userPermGroup.POST("/offboarduser", user.OffboardUser)
+		userPermGroup.POST("/importusergroup", user.ImportUserGroup)
+		userPermGroup.POST("/copyuser", user.CopyUser)
+	}
+
+	// 产线权限快照与回滚
+	snapshotGroup := router.Group("snapshot", m.AddNotice("customerNotice", "v1"))
+	{
+		snapshotGroup.POST("/createsnapshot", snapshot.CreateSnapshot)
+		snapshotGroup.POST("/getsnapshotlist", snapshot.GetSnapshotList)
+		snapshotGroup.POST("/diffsnapshot", snapshot.DiffSnapshot)
+		snapshotGroup.POST("/rollbacksnapshot", snapshot.RollbackSnapshot)
+	}
+
+	// 自助权限申请与审批
+	accessGroup := router.Group("access", m.AddNotice("customerNotice", "v1"))
+	{
+		accessGroup.POST("/createrequest", access.CreateRequest)
+		accessGroup.POST("/approverequest", access.ApproveRequest)
+		accessGroup.POST("/rejectrequest", access.RejectRequest)
+		accessGroup.POST("/cancelrequest", access.CancelRequest)
+		accessGroup.POST("/getrequestlist", access.GetRequestList)
+		accessGroup.POST("/setapprovers", access.SetApprovers)
+		accessGroup.POST("/getapproverlist", access.GetApproverList)
+	}
+
+	// 权限组互斥约束
+	sodGroup := router.Group("sod", m.AddNotice("customerNotice", "v1"))
+	{
+		sodGroup.POST("/createexclusion", sod.CreateExclusion)
+		sodGroup.POST("/deleteexclusion", sod.DeleteExclusion)
+		sodGroup.POST("/getexclusionlist", sod.GetExclusionList)
+	}
+
+	// 权限组负责人
+	ownerGroup := router.Group("owner", m.AddNotice("customerNotice", "v1"))
+	{
+		ownerGroup.POST("/addowner", owner.AddOwner)
+		ownerGroup.POST("/removeowner", owner.RemoveOwner)
+		ownerGroup.POST("/getownerlist", owner.GetOwnerList)
+	}
+
+	// 审计日志
+	auditGroup := router.Group("audit", m.AddNotice("customerNotice", "v1"))
+	{
+		auditGroup.POST("/getauditloglist", audit.GetAuditLogList)
+		auditGroup.POST("/searchdecisionlog", audit.SearchDecisionLog)
+	}
+
+	// 权限复核活动
+	reviewGroup := router.Group("review", m.AddNotice("customerNotice", "v1"))
+	{
+		reviewGroup.POST("/createcampaign", review.CreateCampaign)
+		reviewGroup.POST("/decideitems", review.DecideItems)
+		reviewGroup.POST("/closecampaign", review.CloseCampaign)
+		reviewGroup.POST("/getcampaignlist", review.GetCampaignList)
+		reviewGroup.POST("/getitemlist", review.GetItemList)
+		reviewGroup.POST("/getreport", review.GetReport)
+	}
+
+	// 授权使用情况
+	usageGroup := router.Group("usage", m.AddNotice("customerNotice", "v1"))
+	{
+		usageGroup.POST("/getunusedreport", usage.GetUnusedReport)
 	}
 }
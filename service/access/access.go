@@ -0,0 +1,284 @@
+package access
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/notify"
+	"permission/service/user"
+	"strconv"
+	"time"
+)
+
+// 权限申请相关的通知事件
+const (
+	EventRequestCreated   = "access_request_created"
+	EventRequestApproved  = "access_request_approved"
+	EventRequestRejected  = "access_request_rejected"
+	EventRequestCancelled = "access_request_cancelled"
+	EventRequestExpired   = "access_request_expired"
+)
+
+// 申请单状态机，key 为当前状态，value 为允许流转到的状态
+var transitions = map[int8][]int8{
+	components.ACCESS_REQUEST_STATUS_PENDING: {
+		components.ACCESS_REQUEST_STATUS_APPROVED,
+		components.ACCESS_REQUEST_STATUS_REJECTED,
+		components.ACCESS_REQUEST_STATUS_EXPIRED,
+		components.ACCESS_REQUEST_STATUS_CANCELLED,
+	},
+	components.ACCESS_REQUEST_STATUS_APPROVED: {
+		components.ACCESS_REQUEST_STATUS_EXPIRED,
+	},
+}
+
+func canTransit(from, to int8) bool {
+	for _, status := range transitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transit 以当前状态为条件更新申请单，保证并发审批时只有一方成功
+func transit(ctx *gin.Context, request *m.AccessRequest, to int8, fields map[string]interface{}) error {
+	if !canTransit(request.Status, to) {
+		return helpers.NewError(components.ErrorAccessRequestStatusInvalid, fmt.Sprintf("%d -> %d", request.Status, to))
+	}
+	fields["status"] = to
+	fields["update_time"] = time.Now().Unix()
+	rows, err := request.UpdateAccessRequestByIdAndStatus(ctx, request.ID, request.Status, fields)
+	if err != nil {
+		return err
+	}
+	if rows < 1 {
+		return helpers.NewError(components.ErrorAccessRequestStatusInvalid, "request has been handled by others")
+	}
+	request.Status = to
+	return nil
+}
+
+func domainOf(productId, appId int64) string {
+	return fmt.Sprintf("%d:%d", productId, appId)
+}
+
+func userSubject(userId int64) string {
+	return components.CASBIN_SUB_USER_PREFIX + strconv.FormatInt(userId, 10)
+}
+
+// approversOf 权限组申请由该组审批人审批；单个资源申请由持有该资源的权限组审批人审批
+func approversOf(ctx *gin.Context, request *m.AccessRequest) ([]int64, error) {
+	groupIds := []int64{request.GroupId}
+	if request.RequestType == components.ACCESS_REQUEST_TYPE_RESOURCE {
+		casbinRule := &m.CasbinRule{}
+		condition := map[string]interface{}{
+			"ptype": components.CASBIN_RULE_PTYPE,
+			"v1":    domainOf(request.ProductId, request.AppId),
+			"v2":    request.Resource,
+			"v4":    components.POLICY_STATUS_ALLOW,
+		}
+		rules, err := casbinRule.GetCasbinRulesListByConds(ctx, condition)
+		if err != nil {
+			return nil, err
+		}
+		groupIds = groupIds[:0]
+		for _, rule := range rules {
+			// 直接授予用户的规则不参与审批人计算
+			if groupId, err := strconv.ParseInt(rule.GroupId, 10, 64); err == nil {
+				groupIds = append(groupIds, groupId)
+			}
+		}
+	}
+	if len(groupIds) == 0 {
+		return nil, nil
+	}
+	approver := &m.GroupApprover{}
+	approvers, err := approver.GetGroupApproverListByConds(ctx, map[string]interface{}{"group_id": groupIds})
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int64]struct{}, len(approvers))
+	var uids []int64
+	for _, a := range approvers {
+		if _, ok := seen[a.UserId]; ok {
+			continue
+		}
+		seen[a.UserId] = struct{}{}
+		uids = append(uids, a.UserId)
+	}
+	return uids, nil
+}
+
+// grant 审批通过后授予申请的权限；用户已持有更长期或永久的同一权限时不缩短，申请单的到期时间改为已持有的到期时间
+func grant(ctx *gin.Context, request *m.AccessRequest) error {
+	held, expireTime, err := heldExpireTime(ctx, request)
+	if err != nil {
+		return helpers.NewError(components.ErrorDbSelect, "get held permission failure")
+	}
+	if held && (expireTime == 0 || (request.GrantExpireTime > 0 && expireTime >= request.GrantExpireTime)) {
+		fields := map[string]interface{}{"grant_expire_time": expireTime}
+		if _, err := request.UpdateAccessRequestByIdAndStatus(ctx, request.ID, components.ACCESS_REQUEST_STATUS_APPROVED, fields); err != nil {
+			return helpers.NewError(components.ErrorDbUpdate, "update access request failure")
+		}
+		request.GrantExpireTime = expireTime
+		return nil
+	}
+	if request.RequestType == components.ACCESS_REQUEST_TYPE_GROUP {
+		userGroupInput := &user.RCreateInput{
+			ProductId:  request.ProductId,
+			AppId:      request.AppId,
+			UserType:   request.UserType,
+			UserId:     request.UserId,
+			GroupId:    request.GroupId,
+			Status:     components.GROUP_STATUS_ACTIVE,
+			OperateUid: request.HandleUid,
+			ExpireTime: request.GrantExpireTime,
+			Append:     true,
+			System:     true,
+		}
+		_, err := userGroupInput.CreateUserGroup(ctx)
+		return err
+	}
+	rule := userRule(request)
+	if _, err := rule.BatchUpsertCasbinRule(ctx, []m.CasbinRule{rule}, nil); err != nil {
+		return helpers.NewError(components.ErrorDbInsert, "insert user policy failure")
+	}
+	if err := helpers.AddPolicies([][]string{rule.PolicyRule()}); err != nil {
+		zlog.Warnf(ctx, "casbin add policy failure, reload all", err)
+		helpers.ReloadPolicy()
+	}
+	return nil
+}
+
+// heldExpireTime 用户当前是否已持有申请的权限组/资源，以及已持有权限的到期时间，0表示永久。
+// 直接授予用户的规则本身没有到期时间，由已通过的申请单记录；没有对应申请单的规则视为永久授权
+func heldExpireTime(ctx *gin.Context, request *m.AccessRequest) (bool, int64, error) {
+	if request.RequestType == components.ACCESS_REQUEST_TYPE_GROUP {
+		userGroup := &m.UserGroup{UserId: request.UserId}
+		condition := map[string]interface{}{
+			"product_id": request.ProductId,
+			"app_id":     request.AppId,
+			"user_type":  request.UserType,
+			"user_id":    request.UserId,
+			"group_id":   request.GroupId,
+		}
+		info, err := userGroup.GetUserGroupByCondition(ctx, condition)
+		if err != nil {
+			return false, 0, err
+		}
+		if info.ID <= 0 || info.Status != components.GROUP_STATUS_ACTIVE ||
+			(info.ExpireTime > 0 && info.ExpireTime <= time.Now().Unix()) {
+			return false, 0, nil
+		}
+		return true, info.ExpireTime, nil
+	}
+	rule := userRule(request)
+	info, err := rule.GetCasbinRulesByConds(ctx, ruleCondition(rule))
+	if err != nil {
+		return false, 0, err
+	}
+	if info.ID <= 0 {
+		return false, 0, nil
+	}
+	other := &m.AccessRequest{}
+	condition := map[string]interface{}{
+		"product_id":   request.ProductId,
+		"app_id":       request.AppId,
+		"user_id":      request.UserId,
+		"request_type": components.ACCESS_REQUEST_TYPE_RESOURCE,
+		"resource":     request.Resource,
+		"status":       components.ACCESS_REQUEST_STATUS_APPROVED,
+	}
+	requests, err := other.GetAccessRequestListByConds(ctx, condition)
+	if err != nil {
+		return false, 0, err
+	}
+	var expireTime int64
+	for _, r := range requests {
+		if r.ID == request.ID {
+			continue
+		}
+		if r.GrantExpireTime == 0 {
+			return true, 0, nil
+		}
+		if r.GrantExpireTime > expireTime {
+			expireTime = r.GrantExpireTime
+		}
+	}
+	return true, expireTime, nil
+}
+
+// userRule 单个资源申请通过后直接授予用户的规则
+func userRule(request *m.AccessRequest) m.CasbinRule {
+	return m.CasbinRule{
+		Ptype:           components.CASBIN_RULE_PTYPE,
+		GroupId:         userSubject(request.UserId),
+		ProductAppField: domainOf(request.ProductId, request.AppId),
+		Resource:        request.Resource,
+		PermissionType:  components.CASBIN_ACT_ANY,
+		Status:          components.POLICY_STATUS_ALLOW,
+	}
+}
+
+func ruleCondition(rule m.CasbinRule) map[string]interface{} {
+	return map[string]interface{}{
+		"ptype": rule.Ptype,
+		"v0":    rule.GroupId,
+		"v1":    rule.ProductAppField,
+		"v2":    rule.Resource,
+		"v3":    rule.PermissionType,
+		"v4":    rule.Status,
+		"v5":    rule.Condition,
+	}
+}
+
+// revoke 限时授权到期后收回权限；期间被其他授权覆盖的不做处理
+func revoke(ctx *gin.Context, request *m.AccessRequest) error {
+	if request.RequestType == components.ACCESS_REQUEST_TYPE_GROUP {
+		userGroup := &m.UserGroup{}
+		condition := map[string]interface{}{
+			"product_id":  request.ProductId,
+			"app_id":      request.AppId,
+			"user_type":   request.UserType,
+			"user_id":     request.UserId,
+			"group_id":    request.GroupId,
+			"expire_time": request.GrantExpireTime,
+		}
+		_, err := userGroup.DeleteUserGroupByShard(ctx, request.UserId%components.USER_GROUP_SHARD_NUM, condition, nil)
+		return err
+	}
+	// 同一资源还有其他仍在有效期内的授权时保留规则
+	other := &m.AccessRequest{}
+	condition := map[string]interface{}{
+		"product_id":   request.ProductId,
+		"app_id":       request.AppId,
+		"user_id":      request.UserId,
+		"request_type": components.ACCESS_REQUEST_TYPE_RESOURCE,
+		"resource":     request.Resource,
+		"status":       components.ACCESS_REQUEST_STATUS_APPROVED,
+	}
+	info, err := other.GetAccessRequestByConds(ctx, condition)
+	if err != nil {
+		return err
+	}
+	if info.ID > 0 && info.ID != request.ID {
+		return nil
+	}
+	rule := userRule(request)
+	if _, err := rule.DeleteCasbinRuleByCondition(ctx, ruleCondition(rule), nil); err != nil {
+		return helpers.NewError(components.ErrorDbDelete, "delete user policy failure")
+	}
+	if err := helpers.RemovePolicies([][]string{rule.PolicyRule()}); err != nil {
+		zlog.Warnf(ctx, "casbin remove policy failure, reload all", err)
+		helpers.ReloadPolicy()
+	}
+	return nil
+}
+
+func publish(ctx *gin.Context, event string, request *m.AccessRequest, receivers []int64) {
+	notify.Publish(ctx, event, receivers, request)
+}
@@ -0,0 +1,109 @@
+package access
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/audit"
+	"permission/service/owner"
+	"time"
+)
+
+type AApproverInput struct {
+	GroupId    int64
+	UserIds    []int64
+	OperateUid int64
+}
+
+// SetApprovers 覆盖设置权限组的审批人，只有权限组负责人或超级管理员可以设置
+func (aa *AApproverInput) SetApprovers(ctx *gin.Context) (ok bool, err error) {
+	if aa.GroupId <= 0 {
+		return false, helpers.NewError(components.ErrorAccessRequestParamsInvalid, "groupId 不合法")
+	}
+	if aa.OperateUid <= 0 {
+		return false, helpers.NewError(components.ErrorAccessRequestParamsInvalid, "operateUid 不合法")
+	}
+	if _, err := owner.Authorize(ctx, aa.GroupId, aa.OperateUid); err != nil {
+		return false, err
+	}
+	group := &m.Group{}
+	groupInfo, err := group.GetGroupById(ctx, aa.GroupId)
+	if err != nil {
+		return false, helpers.NewError(components.ErrorDbSelect, "get group by id failure")
+	}
+	if groupInfo.ID <= 0 {
+		return false, helpers.NewError(components.ErrorAccessRequestParamsInvalid, fmt.Sprintf("groupId=%d 不存在", aa.GroupId))
+	}
+	seen := make(map[int64]struct{}, len(aa.UserIds))
+	userIds := make([]int64, 0, len(aa.UserIds))
+	approvers := make([]m.GroupApprover, 0, len(aa.UserIds))
+	for _, uid := range aa.UserIds {
+		if uid <= 0 {
+			return false, helpers.NewError(components.ErrorAccessRequestParamsInvalid, "userIds 不合法")
+		}
+		if _, ok := seen[uid]; ok {
+			continue
+		}
+		seen[uid] = struct{}{}
+		userIds = append(userIds, uid)
+		approvers = append(approvers, m.GroupApprover{
+			GroupId:    aa.GroupId,
+			UserId:     uid,
+			CreateUid:  aa.OperateUid,
+			CreateTime: time.Now().Unix(),
+		})
+	}
+
+	approver := &m.GroupApprover{}
+	var txFlowErr error
+	tx := helpers.MysqlClientPermission.Begin()
+	if err := tx.Error; err != nil {
+		zlog.Warnf(ctx, "DB错误 开启事务失败", err)
+		return false, helpers.NewError(components.ErrorDbError, err.Error())
+	}
+	defer func() {
+		if txFlowErr != nil {
+			if _err := tx.Rollback().Error; _err != nil {
+				zlog.Warnf(ctx, "DB错误 事务回滚失败", _err)
+			}
+			return
+		}
+		if _err := tx.Commit().Error; _err != nil {
+			zlog.Warnf(ctx, "DB错误 事务提交失败", _err)
+			ok, err = false, _err
+		}
+	}()
+	if _, txFlowErr = approver.DeleteGroupApproverByGroupId(ctx, aa.GroupId, tx); txFlowErr != nil {
+		return false, helpers.NewError(components.ErrorDbDelete, "delete group approver failure")
+	}
+	if _, txFlowErr = approver.BatchInsertGroupApprover(ctx, approvers, tx); txFlowErr != nil {
+		return false, helpers.NewError(components.ErrorDbInsert, "insert group approver failure")
+	}
+	audit.Record(ctx, audit.Entry{
+		ProductId:  groupInfo.ProductID,
+		AppId:      groupInfo.AppID,
+		OperateUid: aa.OperateUid,
+		Action:     audit.ActionApproverSet,
+		TargetType: audit.TargetGroup,
+		TargetId:   aa.GroupId,
+		Detail: map[string]interface{}{
+			"userIds": userIds,
+		},
+	}, tx)
+	return true, nil
+}
+
+func (aa *AApproverInput) GetApproverList(ctx *gin.Context) ([]m.GroupApprover, error) {
+	if aa.GroupId <= 0 {
+		return nil, helpers.NewError(components.ErrorAccessRequestParamsInvalid, "groupId 不合法")
+	}
+	approver := &m.GroupApprover{}
+	list, err := approver.GetGroupApproverListByConds(ctx, map[string]interface{}{"group_id": aa.GroupId})
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get group approver failure")
+	}
+	return list, nil
+}
@@ -0,0 +1,35 @@
+package access
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+)
+
+type ACancelInput struct {
+	RequestId int64
+	UserId    int64
+}
+
+// CancelRequest 申请人撤回自己待审批的申请
+func (ac *ACancelInput) CancelRequest(ctx *gin.Context) (bool, error) {
+	if ac.RequestId <= 0 || ac.UserId <= 0 {
+		return false, helpers.NewError(components.ErrorAccessRequestParamsInvalid, "requestId/userId 不合法")
+	}
+	request := &m.AccessRequest{}
+	info, err := request.GetAccessRequestById(ctx, ac.RequestId)
+	if err != nil {
+		return false, helpers.NewError(components.ErrorDbSelect, "get access request failure")
+	}
+	if info.ID <= 0 || info.UserId != ac.UserId {
+		return false, helpers.NewError(components.ErrorAccessRequestNotExist, fmt.Sprintf("id=%d", ac.RequestId))
+	}
+	if err := transit(ctx, &info, components.ACCESS_REQUEST_STATUS_CANCELLED, map[string]interface{}{}); err != nil {
+		return false, err
+	}
+	approvers, _ := approversOf(ctx, &info)
+	publish(ctx, EventRequestCancelled, &info, approvers)
+	return true, nil
+}
@@ -0,0 +1,140 @@
+package access
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/service/sod"
+	"time"
+)
+
+type ACreateInput struct {
+	ProductId   int64
+	AppId       int64
+	UserType    int8
+	UserId      int64
+	RequestType int8
+	GroupId     int64
+	Resource    string
+	Reason      string
+	Duration    int64
+}
+
+type ACreateOutput struct {
+	RequestId int64 `json:"requestId"`
+}
+
+func (ac *ACreateInput) CreateRequest(ctx *gin.Context) (ACreateOutput, error) {
+	if err := ac.checkParams(); err != nil {
+		return ACreateOutput{}, err
+	}
+	if ac.RequestType == components.ACCESS_REQUEST_TYPE_GROUP {
+		group := &m.Group{}
+		groupInfo, err := group.GetGroupById(ctx, ac.GroupId)
+		if err != nil {
+			return ACreateOutput{}, helpers.NewError(components.ErrorDbSelect, "get group by id failure")
+		}
+		if groupInfo.ID <= 0 || groupInfo.Status != components.GROUP_STATUS_ACTIVE ||
+			groupInfo.ProductID != ac.ProductId || groupInfo.AppID != ac.AppId {
+			return ACreateOutput{}, helpers.NewError(components.ErrorAccessRequestParamsInvalid, "权限组不存在或已停用")
+		}
+		// 提前校验互斥约束，避免审批通过后才授权失败
+		if err := sod.CheckAssignment(ctx, ac.ProductId, ac.AppId, ac.UserId, ac.GroupId); err != nil {
+			return ACreateOutput{}, err
+		}
+	}
+	// 已永久持有的权限无需申请，避免限时授权到期后收回原有的永久授权
+	request := &m.AccessRequest{
+		ProductId:   ac.ProductId,
+		AppId:       ac.AppId,
+		UserType:    ac.UserType,
+		UserId:      ac.UserId,
+		RequestType: ac.RequestType,
+		GroupId:     ac.GroupId,
+		Resource:    ac.Resource,
+	}
+	held, expireTime, err := heldExpireTime(ctx, request)
+	if err != nil {
+		return ACreateOutput{}, helpers.NewError(components.ErrorDbSelect, "get held permission failure")
+	}
+	if held && expireTime == 0 {
+		return ACreateOutput{}, helpers.NewError(components.ErrorAccessRequestParamsInvalid, "已永久持有该权限")
+	}
+	// 同一用户对同一权限组/资源只能有一个待审批的申请
+	condition := map[string]interface{}{
+		"product_id":   ac.ProductId,
+		"app_id":       ac.AppId,
+		"user_id":      ac.UserId,
+		"request_type": ac.RequestType,
+		"group_id":     ac.GroupId,
+		"resource":     ac.Resource,
+		"status":       components.ACCESS_REQUEST_STATUS_PENDING,
+	}
+	pending, err := request.GetAccessRequestByConds(ctx, condition)
+	if err != nil {
+		return ACreateOutput{}, helpers.NewError(components.ErrorDbSelect, "get pending access request failure")
+	}
+	if pending.ID > 0 {
+		return ACreateOutput{}, helpers.NewError(components.ErrorAccessRequestParamsInvalid, "已有待审批的申请")
+	}
+	request = &m.AccessRequest{
+		ProductId:   ac.ProductId,
+		AppId:       ac.AppId,
+		UserType:    ac.UserType,
+		UserId:      ac.UserId,
+		RequestType: ac.RequestType,
+		GroupId:     ac.GroupId,
+		Resource:    ac.Resource,
+		Reason:      ac.Reason,
+		Duration:    ac.Duration,
+		Status:      components.ACCESS_REQUEST_STATUS_PENDING,
+		CreateTime:  time.Now().Unix(),
+		UpdateTime:  time.Now().Unix(),
+	}
+	approvers, err := approversOf(ctx, request)
+	if err != nil {
+		return ACreateOutput{}, helpers.NewError(components.ErrorDbSelect, "get approvers failure")
+	}
+	if len(approvers) == 0 {
+		return ACreateOutput{}, helpers.NewError(components.ErrorAccessRequestParamsInvalid, "未配置审批人")
+	}
+	if err := request.InsertAccessRequest(ctx); err != nil {
+		return ACreateOutput{}, helpers.NewError(components.ErrorDbInsert, "insert access request failure")
+	}
+	publish(ctx, EventRequestCreated, request, approvers)
+	return ACreateOutput{RequestId: request.ID}, nil
+}
+
+func (ac *ACreateInput) checkParams() error {
+	if ac.ProductId <= 0 {
+		return helpers.NewError(components.ErrorAccessRequestParamsInvalid, "productId 不合法")
+	}
+	if ac.AppId <= 0 {
+		return helpers.NewError(components.ErrorAccessRequestParamsInvalid, "appId 不合法")
+	}
+	if ac.UserId <= 0 {
+		return helpers.NewError(components.ErrorAccessRequestParamsInvalid, "userId 不合法")
+	}
+	if ac.Duration < 0 {
+		return helpers.NewError(components.ErrorAccessRequestParamsInvalid, "duration 不合法")
+	}
+	if len(ac.Reason) <= 0 {
+		return helpers.NewError(components.ErrorAccessRequestParamsInvalid, "reason 不能为空")
+	}
+	switch ac.RequestType {
+	case components.ACCESS_REQUEST_TYPE_GROUP:
+		if ac.GroupId <= 0 {
+			return helpers.NewError(components.ErrorAccessRequestParamsInvalid, "groupId 不合法")
+		}
+		ac.Resource = ""
+	case components.ACCESS_REQUEST_TYPE_RESOURCE:
+		if len(ac.Resource) <= 0 {
+			return helpers.NewError(components.ErrorAccessRequestParamsInvalid, "resource 不合法")
+		}
+		ac.GroupId = 0
+	default:
+		return helpers.NewError(components.ErrorAccessRequestParamsInvalid, "requestType 不合法")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package access
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/conf"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"time"
+)
+
+const (
+	defaultAccessRequestTTL = 7 * 24 * time.Hour
+	expireBatchSize         = 200
+)
+
+// ExpireRequests 定时任务：超时未审批的申请置为过期，限时授权到期的收回权限后置为过期
+func ExpireRequests(ctx *gin.Context) error {
+	ttl := conf.BasicConf.Permission.AccessRequestTTL
+	if ttl <= 0 {
+		ttl = defaultAccessRequestTTL
+	}
+	now := time.Now()
+	request := &m.AccessRequest{}
+
+	pending, err := request.GetAccessRequestListBefore(ctx, components.ACCESS_REQUEST_STATUS_PENDING,
+		"create_time", now.Add(-ttl).Unix(), expireBatchSize)
+	if err != nil {
+		return err
+	}
+	for i := range pending {
+		if err := transit(ctx, &pending[i], components.ACCESS_REQUEST_STATUS_EXPIRED, map[string]interface{}{}); err != nil {
+			zlog.Warnf(ctx, "expire pending access request %d failure, err:%v", pending[i].ID, err)
+			continue
+		}
+		publish(ctx, EventRequestExpired, &pending[i], []int64{pending[i].UserId})
+	}
+
+	approved, err := request.GetAccessRequestListBefore(ctx, components.ACCESS_REQUEST_STATUS_APPROVED,
+		"grant_expire_time", now.Unix(), expireBatchSize)
+	if err != nil {
+		return err
+	}
+	for i := range approved {
+		// 先抢占状态再收回，避免多实例重复处理
+		if err := transit(ctx, &approved[i], components.ACCESS_REQUEST_STATUS_EXPIRED, map[string]interface{}{}); err != nil {
+			zlog.Warnf(ctx, "expire approved access request %d failure, err:%v", approved[i].ID, err)
+			continue
+		}
+		if err := revoke(ctx, &approved[i]); err != nil {
+			zlog.Errorf(ctx, "revoke access request %d failure, err:%v", approved[i].ID, err)
+			continue
+		}
+		publish(ctx, EventRequestExpired, &approved[i], []int64{approved[i].UserId})
+	}
+	return nil
+}
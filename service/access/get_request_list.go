@@ -0,0 +1,51 @@
+package access
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+)
+
+// AListInput 各筛选条件为0或为空时不参与筛选
+type AListInput struct {
+	ProductId int64
+	AppId     int64
+	UserId    int64
+	GroupId   int64
+	Statuses  []int8
+	PageNo    int
+	PageSize  int
+}
+
+type AListOutput struct {
+	Total       int               `json:"total"`
+	RequestList []m.AccessRequest `json:"requestList"`
+}
+
+func (al *AListInput) GetRequestList(ctx *gin.Context) (AListOutput, error) {
+	if al.ProductId <= 0 || al.AppId <= 0 {
+		return AListOutput{}, helpers.NewError(components.ErrorAccessRequestParamsInvalid, "productId/appId 不合法")
+	}
+	condition := map[string]interface{}{
+		"product_id": al.ProductId,
+		"app_id":     al.AppId,
+	}
+	if al.UserId > 0 {
+		condition["user_id"] = al.UserId
+	}
+	if al.GroupId > 0 {
+		condition["group_id"] = al.GroupId
+	}
+	if len(al.Statuses) > 0 {
+		condition["status"] = al.Statuses
+	}
+	request := &m.AccessRequest{}
+	option := &m.Option{IsNeedCnt: true, IsNeedList: true}
+	page := &m.NormalPage{No: al.PageNo, Size: al.PageSize}
+	list, cnt, err := request.GetAccessRequestListByPage(ctx, condition, option, page)
+	if err != nil {
+		return AListOutput{}, helpers.NewError(components.ErrorDbSelect, "get access request list failure")
+	}
+	return AListOutput{Total: cnt, RequestList: list}, nil
+}
@@ -0,0 +1,100 @@
+package access
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"time"
+)
+
+type AHandleInput struct {
+	RequestId int64
+	HandleUid int64
+	Remark    string
+}
+
+// ApproveRequest 审批通过并自动授予申请的权限
+func (ah *AHandleInput) ApproveRequest(ctx *gin.Context) (bool, error) {
+	request, err := ah.load(ctx)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now().Unix()
+	var grantExpireTime int64
+	if request.Duration > 0 {
+		grantExpireTime = now + request.Duration
+	}
+	fields := map[string]interface{}{
+		"handle_uid":        ah.HandleUid,
+		"handle_remark":     ah.Remark,
+		"handle_time":       now,
+		"grant_expire_time": grantExpireTime,
+	}
+	if err := transit(ctx, request, components.ACCESS_REQUEST_STATUS_APPROVED, fields); err != nil {
+		return false, err
+	}
+	request.HandleUid, request.HandleRemark, request.HandleTime = ah.HandleUid, ah.Remark, now
+	request.GrantExpireTime = grantExpireTime
+	if err := grant(ctx, request); err != nil {
+		// 授权失败时退回待审批，允许重新审批
+		zlog.Errorf(ctx, "grant access request %d failure, err:%v", request.ID, err)
+		reverted := map[string]interface{}{"status": components.ACCESS_REQUEST_STATUS_PENDING, "grant_expire_time": 0}
+		if _, _err := request.UpdateAccessRequestByIdAndStatus(ctx, request.ID, components.ACCESS_REQUEST_STATUS_APPROVED, reverted); _err != nil {
+			zlog.Errorf(ctx, "revert access request %d failure, err:%v", request.ID, _err)
+		}
+		return false, err
+	}
+	publish(ctx, EventRequestApproved, request, []int64{request.UserId})
+	return true, nil
+}
+
+func (ah *AHandleInput) RejectRequest(ctx *gin.Context) (bool, error) {
+	request, err := ah.load(ctx)
+	if err != nil {
+		return false, err
+	}
+	fields := map[string]interface{}{
+		"handle_uid":    ah.HandleUid,
+		"handle_remark": ah.Remark,
+		"handle_time":   time.Now().Unix(),
+	}
+	if err := transit(ctx, request, components.ACCESS_REQUEST_STATUS_REJECTED, fields); err != nil {
+		return false, err
+	}
+	publish(ctx, EventRequestRejected, request, []int64{request.UserId})
+	return true, nil
+}
+
+// load 读取申请单并校验操作人是审批人，申请人不能审批自己的申请
+func (ah *AHandleInput) load(ctx *gin.Context) (*m.AccessRequest, error) {
+	if ah.RequestId <= 0 {
+		return nil, helpers.NewError(components.ErrorAccessRequestParamsInvalid, "requestId 不合法")
+	}
+	if ah.HandleUid <= 0 {
+		return nil, helpers.NewError(components.ErrorAccessRequestParamsInvalid, "handleUid 不合法")
+	}
+	request := &m.AccessRequest{}
+	info, err := request.GetAccessRequestById(ctx, ah.RequestId)
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get access request failure")
+	}
+	if info.ID <= 0 {
+		return nil, helpers.NewError(components.ErrorAccessRequestNotExist, fmt.Sprintf("id=%d", ah.RequestId))
+	}
+	if info.UserId == ah.HandleUid {
+		return nil, helpers.NewError(components.ErrorAccessRequestNotApprover, fmt.Sprintf("uid=%d 不能审批本人的申请", ah.HandleUid))
+	}
+	approvers, err := approversOf(ctx, &info)
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get approvers failure")
+	}
+	for _, uid := range approvers {
+		if uid == ah.HandleUid {
+			return &info, nil
+		}
+	}
+	return nil, helpers.NewError(components.ErrorAccessRequestNotApprover, fmt.Sprintf("uid=%d", ah.HandleUid))
+}
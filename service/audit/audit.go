@@ -0,0 +1,67 @@
+package audit
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"time"
+)
+
+// 审计动作
+const (
+	ActionOwnerAdd         = "owner_add"
+	ActionOwnerRemove      = "owner_remove"
+	ActionMemberAdd        = "member_add"
+	ActionMemberRemove     = "member_remove"
+	ActionMemberChange     = "member_change"
+	ActionUserOffboard     = "user_offboard"
+	ActionUserCopy         = "user_copy"
+	ActionGroupUpdate      = "group_update"
+	ActionGroupClone       = "group_clone"
+	ActionModelBind        = "model_bind"
+	ActionReviewCreate     = "review_create"
+	ActionReviewClose      = "review_close"
+	ActionReviewKeep       = "review_keep"
+	ActionImpersonate      = "impersonate"
+	ActionDataScopeSet     = "data_scope_set"
+	ActionApproverSet      = "approver_set"
+	ActionSnapshotCreate   = "snapshot_create"
+	ActionSnapshotRollback = "snapshot_rollback"
+)
+
+const (
+	TargetGroup  = "group"
+	TargetUser   = "user"
+	TargetDomain = "domain"
+	TargetReview = "review"
+)
+
+type Entry struct {
+	ProductId  int64
+	AppId      int64
+	OperateUid int64
+	Action     string
+	TargetType string
+	TargetId   int64
+	Detail     interface{}
+}
+
+// Record 写入审计记录，db 不为空时随业务事务一起提交；写入失败只记日志，不影响业务
+func Record(ctx *gin.Context, entry Entry, db *gorm.DB) {
+	detail, _ := json.Marshal(entry.Detail)
+	log := &m.AuditLog{
+		ProductId:  entry.ProductId,
+		AppId:      entry.AppId,
+		OperateUid: entry.OperateUid,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetId:   entry.TargetId,
+		Detail:     string(detail),
+		CreateTime: time.Now().Unix(),
+	}
+	if err := log.InsertAuditLog(ctx, db); err != nil {
+		zlog.Errorf(ctx, "record audit log %s failure, err:%v", entry.Action, err)
+	}
+}
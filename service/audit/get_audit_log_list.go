@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+)
+
+// AListInput 各筛选条件为0或为空时不参与筛选
+type AListInput struct {
+	ProductId  int64
+	AppId      int64
+	OperateUid int64
+	Action     string
+	TargetType string
+	TargetId   int64
+	PageNo     int
+	PageSize   int
+}
+
+type AListOutput struct {
+	Total   int          `json:"total"`
+	LogList []m.AuditLog `json:"logList"`
+}
+
+func (al *AListInput) GetAuditLogList(ctx *gin.Context) (AListOutput, error) {
+	if al.ProductId <= 0 || al.AppId <= 0 {
+		return AListOutput{}, helpers.NewError(components.ErrorAuditParamsInvalid, "productId/appId 不合法")
+	}
+	condition := map[string]interface{}{
+		"product_id": al.ProductId,
+		"app_id":     al.AppId,
+	}
+	if al.OperateUid > 0 {
+		condition["operate_uid"] = al.OperateUid
+	}
+	if al.Action != "" {
+		condition["action"] = al.Action
+	}
+	if al.TargetType != "" {
+		condition["target_type"] = al.TargetType
+	}
+	if al.TargetId > 0 {
+		condition["target_id"] = al.TargetId
+	}
+	auditLog := &m.AuditLog{}
+	option := &m.Option{IsNeedCnt: true, IsNeedList: true}
+	page := &m.NormalPage{No: al.PageNo, Size: al.PageSize}
+	list, cnt, err := auditLog.GetAuditLogListByPage(ctx, condition, option, page)
+	if err != nil {
+		return AListOutput{}, helpers.NewError(components.ErrorDbSelect, "get audit log list failure")
+	}
+	return AListOutput{Total: cnt, LogList: list}, nil
+}
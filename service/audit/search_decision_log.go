@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	"permission/pkg/golib/v2/zlog"
+	"time"
+)
+
+const (
+	defaultDecisionSize = 100
+	maxDecisionSize     = 1000
+)
+
+// ADecisionInput 时间为秒级时间戳，EndTime 为0时取当前时间；其余条件为0或为空时不参与筛选，productId/appId 需同时给出
+type ADecisionInput struct {
+	StartTime int64
+	EndTime   int64
+	UserId    int64
+	ProductId int64
+	AppId     int64
+	Resource  string
+	Result    string
+	Size      int
+}
+
+type ADecisionOutput struct {
+	Total int64              `json:"total"`
+	List  []helpers.Decision `json:"list"`
+}
+
+// SearchDecisionLog 按时间倒序检索采样记录的鉴权决策
+func (ad *ADecisionInput) SearchDecisionLog(ctx *gin.Context) (ADecisionOutput, error) {
+	if !helpers.DecisionLogEnabled() {
+		return ADecisionOutput{}, helpers.NewError(components.ErrorDecisionLogDisabled, "decisionLog 未配置")
+	}
+	if err := ad.checkParams(); err != nil {
+		return ADecisionOutput{}, err
+	}
+	query := helpers.DecisionQuery{
+		StartTime: ad.StartTime,
+		EndTime:   ad.EndTime,
+		UserId:    ad.UserId,
+		Resource:  ad.Resource,
+		Result:    ad.Result,
+		Size:      ad.Size,
+	}
+	if ad.ProductId > 0 {
+		query.Domain = fmt.Sprintf("%d:%d", ad.ProductId, ad.AppId)
+	}
+	total, list, err := helpers.SearchDecisions(ctx, query)
+	if err != nil {
+		zlog.Errorf(ctx, "search decision log fail, err:%v", err)
+		return ADecisionOutput{}, helpers.NewError(components.ErrorEsQuery, err.Error())
+	}
+	if list == nil {
+		list = []helpers.Decision{}
+	}
+	return ADecisionOutput{Total: total, List: list}, nil
+}
+
+func (ad *ADecisionInput) checkParams() error {
+	if ad.EndTime == 0 {
+		ad.EndTime = time.Now().Unix()
+	}
+	if ad.StartTime <= 0 || ad.EndTime < ad.StartTime {
+		return helpers.NewError(components.ErrorAuditParamsInvalid, "startTime/endTime 不合法")
+	}
+	if (ad.ProductId > 0) != (ad.AppId > 0) {
+		return helpers.NewError(components.ErrorAuditParamsInvalid, "productId/appId 需同时给出")
+	}
+	switch ad.Result {
+	case "", "allow", "deny", "error":
+	default:
+		return helpers.NewError(components.ErrorAuditParamsInvalid, "result 不合法")
+	}
+	if ad.Size <= 0 {
+		ad.Size = defaultDecisionSize
+	}
+	if ad.Size > maxDecisionSize {
+		ad.Size = maxDecisionSize
+	}
+	return nil
+}
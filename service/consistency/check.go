@@ -0,0 +1,266 @@
+package consistency
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	m "permission/models"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 不一致问题的类型
+const (
+	KindOrphanRule      = "orphan_rule"      // 规则对应的权限组不存在、已删除或不属于该产线
+	KindStaleRule       = "stale_rule"       // 规则的资源在产线下已没有对应的接口节点
+	KindDuplicateRule   = "duplicate_rule"   // 与其他规则完全相同，保留id最小的一条
+	KindDanglingBinding = "dangling_binding" // 权限组与节点的映射指向不存在的权限组或节点
+	KindDeletedMember   = "deleted_member"   // 组员资格所属的权限组不存在或已删除
+	KindMissingGrant    = "missing_grant"    // 已通过的单个资源申请对应的用户规则不存在，仅报告
+	KindExpiredGrant    = "expired_grant"    // 用户规则对应的申请均已过期，可能是到期收回失败，也可能是之后另行授予的永久规则，仅报告
+)
+
+// Issue 一条不一致的记录，Shard 仅对组员资格有效
+type Issue struct {
+	Kind   string `json:"kind"`
+	Table  string `json:"table"`
+	Id     int64  `json:"id"`
+	Shard  int64  `json:"shard"`
+	Detail string `json:"detail"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s %s id=%d %s", i.Kind, i.Table, i.Id, i.Detail)
+}
+
+// Check 交叉比对校验规则、映射关系、节点、权限组和全部组员分表，找出互相矛盾的数据
+func Check(ctx *gin.Context) ([]Issue, error) {
+	group := &m.Group{}
+	groups, err := group.GetGroupListByConds(ctx, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	groupMap := make(map[int64]m.Group, len(groups))
+	for _, g := range groups {
+		groupMap[g.ID] = g
+	}
+	node := &m.Node{}
+	nodes, err := node.GetNodeListByCondition(ctx, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	nodeIdSet := make(map[int64]struct{}, len(nodes))
+	// 产线下全部接口节点的资源，key 为 product:app
+	resources := make(map[string]map[string]struct{})
+	for _, n := range nodes {
+		nodeIdSet[n.ID] = struct{}{}
+		if n.NodeType != components.NODE_TYPE_API {
+			continue
+		}
+		domain := fmt.Sprintf("%d:%d", n.ProductID, n.AppID)
+		if resources[domain] == nil {
+			resources[domain] = make(map[string]struct{})
+		}
+		resources[domain][n.Resource] = struct{}{}
+	}
+
+	issues, err := checkRules(ctx, groupMap, resources)
+	if err != nil {
+		return nil, err
+	}
+	bindingIssues, err := checkBindings(ctx, groupMap, nodeIdSet)
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, bindingIssues...)
+	memberIssues, err := checkMembers(ctx, groupMap)
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, memberIssues...)
+	grantIssues, err := checkUserGrants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(issues, grantIssues...), nil
+}
+
+// checkRules 每条规则只归入一类问题，按 孤立 > 重复 > 资源失效 的顺序判断
+func checkRules(ctx *gin.Context, groupMap map[int64]m.Group, resources map[string]map[string]struct{}) ([]Issue, error) {
+	casbinRule := &m.CasbinRule{}
+	rules, err := casbinRule.GetCasbinRulesListByConds(ctx, map[string]interface{}{
+		"ptype": components.CASBIN_RULE_PTYPE,
+	})
+	if err != nil {
+		return nil, err
+	}
+	table := casbinRule.TableName()
+	var issues []Issue
+	seen := make(map[string]int64, len(rules))
+	for _, r := range rules {
+		if !validSubject(r, groupMap) {
+			issues = append(issues, Issue{
+				Kind:   KindOrphanRule,
+				Table:  table,
+				Id:     r.ID,
+				Detail: fmt.Sprintf("group=%s domain=%s", r.GroupId, r.ProductAppField),
+			})
+			continue
+		}
+		key := strings.Join([]string{r.GroupId, r.ProductAppField, r.Resource, r.PermissionType, r.Status, r.Condition}, "\x00")
+		if firstId, ok := seen[key]; ok {
+			issues = append(issues, Issue{
+				Kind:   KindDuplicateRule,
+				Table:  table,
+				Id:     r.ID,
+				Detail: fmt.Sprintf("same as id=%d", firstId),
+			})
+			continue
+		}
+		seen[key] = r.ID
+		if _, ok := resources[r.ProductAppField][r.Resource]; !ok {
+			issues = append(issues, Issue{
+				Kind:   KindStaleRule,
+				Table:  table,
+				Id:     r.ID,
+				Detail: fmt.Sprintf("domain=%s resource=%s", r.ProductAppField, r.Resource),
+			})
+		}
+	}
+	return issues, nil
+}
+
+// validSubject 直接授予用户的规则只校验 user:<id> 格式，其余规则需对应该产线下未删除的权限组
+func validSubject(r m.CasbinRule, groupMap map[int64]m.Group) bool {
+	if strings.HasPrefix(r.GroupId, components.CASBIN_SUB_USER_PREFIX) {
+		userId, err := strconv.ParseInt(strings.TrimPrefix(r.GroupId, components.CASBIN_SUB_USER_PREFIX), 10, 64)
+		return err == nil && userId > 0
+	}
+	groupId, _ := strconv.ParseInt(r.GroupId, 10, 64)
+	g, ok := groupMap[groupId]
+	return ok && g.Status != components.GROUP_STATUS_DELETED && fmt.Sprintf("%d:%d", g.ProductID, g.AppID) == r.ProductAppField
+}
+
+func checkBindings(ctx *gin.Context, groupMap map[int64]m.Group, nodeIdSet map[int64]struct{}) ([]Issue, error) {
+	groupNode := &m.GroupNode{}
+	groupNodes, err := groupNode.GetGroupNodeListByConds(ctx, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	table := groupNode.TableName()
+	var issues []Issue
+	for _, gn := range groupNodes {
+		_, groupOk := groupMap[gn.GroupId]
+		_, nodeOk := nodeIdSet[gn.NodeId]
+		if groupOk && nodeOk {
+			continue
+		}
+		var missing []string
+		if !groupOk {
+			missing = append(missing, fmt.Sprintf("group=%d", gn.GroupId))
+		}
+		if !nodeOk {
+			missing = append(missing, fmt.Sprintf("node=%d", gn.NodeId))
+		}
+		issues = append(issues, Issue{
+			Kind:   KindDanglingBinding,
+			Table:  table,
+			Id:     gn.ID,
+			Detail: "missing " + strings.Join(missing, " "),
+		})
+	}
+	return issues, nil
+}
+
+func checkMembers(ctx *gin.Context, groupMap map[int64]m.Group) ([]Issue, error) {
+	var issues []Issue
+	userGroup := &m.UserGroup{}
+	for shard := int64(0); shard < components.USER_GROUP_SHARD_NUM; shard++ {
+		userGroups, err := userGroup.GetUserGroupListByShard(ctx, shard, map[string]interface{}{}, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, ug := range userGroups {
+			g, ok := groupMap[ug.GroupId]
+			if ok && g.Status != components.GROUP_STATUS_DELETED {
+				continue
+			}
+			issues = append(issues, Issue{
+				Kind:   KindDeletedMember,
+				Table:  m.UserGroupTableName(shard),
+				Id:     ug.ID,
+				Shard:  shard,
+				Detail: fmt.Sprintf("user=%d group=%d", ug.UserId, ug.GroupId),
+			})
+		}
+	}
+	return issues, nil
+}
+
+// checkUserGrants 比对单个资源申请单与直接授予用户的规则：已通过的申请应有对应规则，只剩过期申请的规则应已收回
+func checkUserGrants(ctx *gin.Context) ([]Issue, error) {
+	accessRequest := &m.AccessRequest{}
+	requests, err := accessRequest.GetAccessRequestListByConds(ctx, map[string]interface{}{
+		"request_type": components.ACCESS_REQUEST_TYPE_RESOURCE,
+		"status":       []int8{components.ACCESS_REQUEST_STATUS_APPROVED, components.ACCESS_REQUEST_STATUS_EXPIRED},
+	})
+	if err != nil {
+		return nil, err
+	}
+	casbinRule := &m.CasbinRule{}
+	rules, err := casbinRule.GetCasbinRulesListByConds(ctx, map[string]interface{}{
+		"ptype": components.CASBIN_RULE_PTYPE,
+		"v3":    components.CASBIN_ACT_ANY,
+		"v4":    components.POLICY_STATUS_ALLOW,
+		"v5":    "",
+	})
+	if err != nil {
+		return nil, err
+	}
+	// key 为 domain、sub、resource，与申请通过时授予的规则形式一致
+	ruleIds := make(map[[3]string]int64)
+	for _, r := range rules {
+		if strings.HasPrefix(r.GroupId, components.CASBIN_SUB_USER_PREFIX) {
+			ruleIds[[3]string{r.ProductAppField, r.GroupId, r.Resource}] = r.ID
+		}
+	}
+	approved := make(map[[3]string]bool)
+	expired := make(map[[3]string][]int64)
+	var issues []Issue
+	for _, r := range requests {
+		key := [3]string{fmt.Sprintf("%d:%d", r.ProductId, r.AppId), components.CASBIN_SUB_USER_PREFIX + strconv.FormatInt(r.UserId, 10), r.Resource}
+		if r.Status == components.ACCESS_REQUEST_STATUS_EXPIRED {
+			expired[key] = append(expired[key], r.ID)
+			continue
+		}
+		approved[key] = true
+		if _, ok := ruleIds[key]; !ok {
+			issues = append(issues, Issue{
+				Kind:   KindMissingGrant,
+				Table:  accessRequest.TableName(),
+				Id:     r.ID,
+				Detail: fmt.Sprintf("domain=%s sub=%s resource=%s", key[0], key[1], key[2]),
+			})
+		}
+	}
+	for key, requestIds := range expired {
+		ruleId, ok := ruleIds[key]
+		if !ok || approved[key] {
+			continue
+		}
+		issues = append(issues, Issue{
+			Kind:   KindExpiredGrant,
+			Table:  casbinRule.TableName(),
+			Id:     ruleId,
+			Detail: fmt.Sprintf("domain=%s sub=%s resource=%s expiredRequests=%v", key[0], key[1], key[2], requestIds),
+		})
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Kind != issues[j].Kind {
+			return issues[i].Kind < issues[j].Kind
+		}
+		return issues[i].Id < issues[j].Id
+	})
+	return issues, nil
+}
@@ -0,0 +1,77 @@
+package consistency
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+)
+
+// Repair 在同一事务中删除 Check 找出的问题记录，成功后重新加载校验规则；申请单与用户规则的不一致不自动修复
+func Repair(ctx *gin.Context, issues []Issue) (rows int64, err error) {
+	var ruleIds, bindingIds []int64
+	memberIds := make(map[int64][]int64)
+	for _, i := range issues {
+		switch i.Kind {
+		case KindOrphanRule, KindStaleRule, KindDuplicateRule:
+			ruleIds = append(ruleIds, i.Id)
+		case KindDanglingBinding:
+			bindingIds = append(bindingIds, i.Id)
+		case KindDeletedMember:
+			memberIds[i.Shard] = append(memberIds[i.Shard], i.Id)
+		}
+	}
+	if len(ruleIds)+len(bindingIds)+len(memberIds) == 0 {
+		return 0, nil
+	}
+
+	var txFlowErr error
+	tx := helpers.MysqlClientPermission.Begin()
+	if err := tx.Error; err != nil {
+		zlog.Warnf(ctx, "DB错误 开启事务失败", err)
+		return 0, helpers.NewError(components.ErrorDbError, err.Error())
+	}
+	defer func() {
+		if txFlowErr != nil {
+			if _err := tx.Rollback().Error; _err != nil {
+				zlog.Warnf(ctx, "DB错误 事务回滚失败", _err)
+			}
+			rows, err = 0, txFlowErr
+			return
+		}
+		if _err := tx.Commit().Error; _err != nil {
+			zlog.Warnf(ctx, "DB错误 事务提交失败", _err)
+			rows, err = 0, _err
+			return
+		}
+		if len(ruleIds) > 0 {
+			helpers.ReloadPolicy()
+		}
+	}()
+
+	casbinRule := &m.CasbinRule{}
+	n, txFlowErr := casbinRule.DeleteCasbinRuleByIds(ctx, ruleIds, tx)
+	if txFlowErr != nil {
+		zlog.Errorf(ctx, "delete inconsistent casbin rules fail, err:%v", txFlowErr)
+		return 0, txFlowErr
+	}
+	rows += n
+	groupNode := &m.GroupNode{}
+	n, txFlowErr = groupNode.DeleteGroupNodeByIds(ctx, bindingIds, tx)
+	if txFlowErr != nil {
+		zlog.Errorf(ctx, "delete dangling group nodes fail, err:%v", txFlowErr)
+		return 0, txFlowErr
+	}
+	rows += n
+	userGroup := &m.UserGroup{}
+	for shard, ids := range memberIds {
+		n, txFlowErr = userGroup.DeleteUserGroupByShard(ctx, shard, map[string]interface{}{"id": ids}, tx)
+		if txFlowErr != nil {
+			zlog.Errorf(ctx, "delete members of deleted groups fail, shard:%d err:%v", shard, txFlowErr)
+			return 0, txFlowErr
+		}
+		rows += n
+	}
+	return rows, nil
+}
@@ -0,0 +1,322 @@
+package group
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/audit"
+	"permission/service/owner"
+	"strings"
+	"time"
+)
+
+type GCloneInput struct {
+	GroupId     int64 // 源权限组
+	ProductId   int64 // 目标产线，为0时与源权限组相同
+	AppId       int64
+	GroupName   string
+	UserId      int64
+	ResourceMap map[string]string // 跨产线复制时的资源前缀替换，旧前缀 -> 新前缀
+}
+
+type GCloneOutput struct {
+	GroupId int64    `json:"groupId"`
+	Nodes   int      `json:"nodes"`
+	Menus   int      `json:"menus"`
+	Rules   int      `json:"rules"`
+	Skipped []string `json:"skipped"` // 目标产线下找不到对应节点的资源，对应的映射关系和校验规则均不复制
+}
+
+// CloneGroup 以源权限组的节点、菜单和校验规则创建新权限组，可复制到其他产线并按前缀替换资源
+func (gc *GCloneInput) CloneGroup(ctx *gin.Context) (GCloneOutput, error) {
+	if err := gc.checkParams(); err != nil {
+		return GCloneOutput{}, err
+	}
+	isSuper, err := owner.Authorize(ctx, gc.GroupId, gc.UserId)
+	if err != nil {
+		return GCloneOutput{}, err
+	}
+	group := &m.Group{}
+	source, err := group.GetGroupById(ctx, gc.GroupId)
+	if err != nil {
+		return GCloneOutput{}, helpers.NewError(components.ErrorDbSelect, "get group by id failure")
+	}
+	if source.ID <= 0 || source.Status == components.GROUP_STATUS_DELETED {
+		return GCloneOutput{}, helpers.NewError(components.ErrorGroupParamsInvalid, "group 不存在")
+	}
+	if gc.ProductId == 0 && gc.AppId == 0 {
+		gc.ProductId, gc.AppId = source.ProductID, source.AppID
+	}
+	crossDomain := gc.ProductId != source.ProductID || gc.AppId != source.AppID
+	// 负责人只能在本产线内复制，跨产线需超级管理员
+	if crossDomain && !isSuper {
+		return GCloneOutput{}, helpers.NewError(components.ErrorNotSuperAdmin, fmt.Sprintf("uid=%d", gc.UserId))
+	}
+	exist, err := group.GetGroupByConds(ctx, map[string]interface{}{
+		"product_id": gc.ProductId,
+		"app_id":     gc.AppId,
+		"group_name": gc.GroupName,
+		"status":     components.GROUP_STATUS_ACTIVE,
+	})
+	if err != nil {
+		return GCloneOutput{}, helpers.NewError(components.ErrorDbSelect, "get group by conds failure")
+	}
+	if exist.ID > 0 {
+		return GCloneOutput{}, helpers.NewError(components.ErrorDbInsert, "权限组已存在")
+	}
+
+	out := GCloneOutput{Skipped: []string{}}
+	groupNodes, err := gc.cloneNodes(ctx, source, crossDomain, &out)
+	if err != nil {
+		return GCloneOutput{}, err
+	}
+	casbinRule := &m.CasbinRule{}
+	rules, err := casbinRule.GetCasbinRulesListByConds(ctx, map[string]interface{}{
+		"ptype": components.CASBIN_RULE_PTYPE,
+		"v0":    fmt.Sprintf("%d", source.ID),
+		"v1":    fmt.Sprintf("%d:%d", source.ProductID, source.AppID),
+	})
+	if err != nil {
+		return GCloneOutput{}, helpers.NewError(components.ErrorDbSelect, "get casbin rules failure")
+	}
+	if rules, err = gc.cloneRules(ctx, rules, &out); err != nil {
+		return GCloneOutput{}, err
+	}
+	out.Rules = len(rules)
+	return gc.create(ctx, source, groupNodes, rules, out)
+}
+
+// cloneRules 替换规则的产线和资源，目标产线下没有对应接口节点的规则不复制，资源记入 Skipped
+func (gc *GCloneInput) cloneRules(ctx *gin.Context, rules []m.CasbinRule, out *GCloneOutput) ([]m.CasbinRule, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	resources := make([]string, 0, len(rules))
+	for i := range rules {
+		resources = append(resources, gc.remap(rules[i].Resource))
+	}
+	node := &m.Node{}
+	targetNodes, err := node.GetNodeListByCondition(ctx, map[string]interface{}{
+		"product_id": gc.ProductId,
+		"app_id":     gc.AppId,
+		"node_type":  components.NODE_TYPE_API,
+		"resource":   resources,
+	})
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get node list failure")
+	}
+	exists := make(map[string]bool, len(targetNodes))
+	for _, n := range targetNodes {
+		exists[n.Resource] = true
+	}
+	skipped := make(map[string]bool, len(out.Skipped))
+	for _, resource := range out.Skipped {
+		skipped[resource] = true
+	}
+	cloned := make([]m.CasbinRule, 0, len(rules))
+	for i, r := range rules {
+		if !exists[resources[i]] {
+			if !skipped[resources[i]] {
+				skipped[resources[i]] = true
+				out.Skipped = append(out.Skipped, resources[i])
+			}
+			continue
+		}
+		r.ID = 0
+		r.ProductAppField = fmt.Sprintf("%d:%d", gc.ProductId, gc.AppId)
+		r.Resource = resources[i]
+		cloned = append(cloned, r)
+	}
+	return cloned, nil
+}
+
+// cloneNodes 计算新权限组的节点映射关系，跨产线时按替换后的资源匹配目标产线下的同类型节点
+func (gc *GCloneInput) cloneNodes(ctx *gin.Context, source m.Group, crossDomain bool, out *GCloneOutput) ([]m.GroupNode, error) {
+	groupNode := &m.GroupNode{}
+	bindings, err := groupNode.GetGroupNodeListByConds(ctx, map[string]interface{}{"group_id": source.ID})
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get groupNodeListByConds failure")
+	}
+	if len(bindings) == 0 {
+		return nil, nil
+	}
+	var targets map[int64]int64
+	if crossDomain {
+		if targets, err = gc.mapNodes(ctx, bindings, out); err != nil {
+			return nil, err
+		}
+	}
+	var groupNodes []m.GroupNode
+	for _, b := range bindings {
+		nodeId := b.NodeId
+		if crossDomain {
+			var ok bool
+			if nodeId, ok = targets[b.NodeId]; !ok {
+				continue
+			}
+		}
+		groupNodes = append(groupNodes, m.GroupNode{
+			NodeId:    nodeId,
+			NodeType:  b.NodeType,
+			DataScope: b.DataScope,
+			Implied:   b.Implied,
+		})
+		if b.NodeType == components.NODE_TYPE_PAGE {
+			out.Menus++
+		} else {
+			out.Nodes++
+		}
+	}
+	return groupNodes, nil
+}
+
+// mapNodes 返回源节点id到目标产线节点id的映射，找不到的资源记入 Skipped
+func (gc *GCloneInput) mapNodes(ctx *gin.Context, bindings []m.GroupNode, out *GCloneOutput) (map[int64]int64, error) {
+	node := &m.Node{}
+	sourceNodes, err := node.GetNodeListByCondition(ctx, map[string]interface{}{"id": ConvertId2Slice(bindings)})
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get node list failure")
+	}
+	resources := make([]string, 0, len(sourceNodes))
+	for _, n := range sourceNodes {
+		resources = append(resources, gc.remap(n.Resource))
+	}
+	targetNodes, err := node.GetNodeListByCondition(ctx, map[string]interface{}{
+		"product_id": gc.ProductId,
+		"app_id":     gc.AppId,
+		"resource":   resources,
+	})
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get node list failure")
+	}
+	byResource := make(map[string]int64, len(targetNodes))
+	for _, n := range targetNodes {
+		byResource[fmt.Sprintf("%d:%s", n.NodeType, n.Resource)] = n.ID
+	}
+	targets := make(map[int64]int64, len(sourceNodes))
+	for _, n := range sourceNodes {
+		resource := gc.remap(n.Resource)
+		if id, ok := byResource[fmt.Sprintf("%d:%s", n.NodeType, resource)]; ok {
+			targets[n.ID] = id
+		} else {
+			out.Skipped = append(out.Skipped, resource)
+		}
+	}
+	return targets, nil
+}
+
+// remap 按最长匹配的前缀替换资源
+func (gc *GCloneInput) remap(resource string) string {
+	var from string
+	for prefix := range gc.ResourceMap {
+		if strings.HasPrefix(resource, prefix) && len(prefix) > len(from) {
+			from = prefix
+		}
+	}
+	if from == "" {
+		return resource
+	}
+	return gc.ResourceMap[from] + strings.TrimPrefix(resource, from)
+}
+
+func (gc *GCloneInput) create(ctx *gin.Context, source m.Group, groupNodes []m.GroupNode, rules []m.CasbinRule, counts GCloneOutput) (out GCloneOutput, err error) {
+	out = counts
+	var txFlowErr error
+	tx := helpers.MysqlClientPermission.Begin()
+	if err := tx.Error; err != nil {
+		zlog.Warnf(ctx, "DB错误 开启事务失败", err)
+		return GCloneOutput{}, helpers.NewError(components.ErrorDbError, err.Error())
+	}
+	defer func() {
+		if txFlowErr != nil {
+			if _err := tx.Rollback().Error; _err != nil {
+				zlog.Warnf(ctx, "DB错误 事务回滚失败", _err)
+			}
+			return
+		}
+		if _err := tx.Commit().Error; _err != nil {
+			zlog.Warnf(ctx, "DB错误 事务提交失败", _err)
+			out, err = GCloneOutput{}, _err
+			return
+		}
+		policies := make([][]string, 0, len(rules))
+		for i := range rules {
+			policies = append(policies, rules[i].PolicyRule())
+		}
+		if _err := helpers.AddPolicies(policies); _err != nil {
+			zlog.Warnf(ctx, "casbin add cloned policy failure, reload all", _err)
+			helpers.ReloadPolicy()
+		}
+	}()
+	now := time.Now().Unix()
+	group := &m.Group{}
+	groups := []m.Group{{
+		ProductID:  gc.ProductId,
+		AppID:      gc.AppId,
+		GroupName:  gc.GroupName,
+		Status:     components.GROUP_STATUS_ACTIVE,
+		Version:    1,
+		CreateUid:  gc.UserId,
+		UpdateUid:  gc.UserId,
+		CreateTime: now,
+		UpdateTime: now,
+	}}
+	if _, txFlowErr = group.BatchInsertGroup(ctx, groups, tx); txFlowErr != nil {
+		zlog.Errorf(ctx, "insert cloned group fail, err:%v", txFlowErr)
+		return GCloneOutput{}, txFlowErr
+	}
+	out.GroupId = groups[0].ID
+	for i := range groupNodes {
+		groupNodes[i].GroupId = out.GroupId
+	}
+	groupNode := &m.GroupNode{}
+	if _, txFlowErr = groupNode.BatchInsertGroupNode(ctx, groupNodes, tx); txFlowErr != nil {
+		zlog.Errorf(ctx, "batch create cloned group node fail, err:%v", txFlowErr)
+		return GCloneOutput{}, txFlowErr
+	}
+	for i := range rules {
+		rules[i].GroupId = fmt.Sprintf("%d", out.GroupId)
+	}
+	casbinRule := &m.CasbinRule{}
+	if _, txFlowErr = casbinRule.BatchInsertCasbinRule(ctx, rules, tx); txFlowErr != nil {
+		zlog.Errorf(ctx, "batch create cloned casbin rule fail, err:%v", txFlowErr)
+		return GCloneOutput{}, txFlowErr
+	}
+	audit.Record(ctx, audit.Entry{
+		ProductId:  gc.ProductId,
+		AppId:      gc.AppId,
+		OperateUid: gc.UserId,
+		Action:     audit.ActionGroupClone,
+		TargetType: audit.TargetGroup,
+		TargetId:   out.GroupId,
+		Detail: map[string]interface{}{
+			"sourceGroupId": source.ID,
+			"sourceDomain":  fmt.Sprintf("%d:%d", source.ProductID, source.AppID),
+			"resourceMap":   gc.ResourceMap,
+			"skipped":       out.Skipped,
+		},
+	}, tx)
+	return out, nil
+}
+
+func (gc *GCloneInput) checkParams() error {
+	if gc.GroupId <= 0 {
+		return helpers.NewError(components.ErrorGroupParamsInvalid, "groupId 不合法")
+	}
+	if len(gc.GroupName) <= 0 {
+		return helpers.NewError(components.ErrorGroupParamsInvalid, "groupName 不合法")
+	}
+	if gc.UserId <= 0 {
+		return helpers.NewError(components.ErrorGroupParamsInvalid, "userId 不合法")
+	}
+	if gc.ProductId < 0 || gc.AppId < 0 {
+		return helpers.NewError(components.ErrorGroupParamsInvalid, "productId/appId 不合法")
+	}
+	if (gc.ProductId == 0) != (gc.AppId == 0) {
+		return helpers.NewError(components.ErrorGroupParamsInvalid, "productId/appId 需同时指定或同时为0")
+	}
+	return nil
+}
@@ -27,6 +27,7 @@ func (gi *GCreateInput) CreateGroup(ctx *gin.Context) (bool, error) {
 		CreateUid:  gi.UserId,
 		UpdateUid:  gi.UserId,
 		ParentId:   gi.ParentId,
+		Version:    1,
 		CreateTime: time.Now().Unix(),
 		UpdateTime: time.Now().Unix(),
 	}
@@ -0,0 +1,115 @@
+package group
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"sort"
+)
+
+type GDiffInput struct {
+	GroupId      int64
+	OtherGroupId int64
+}
+
+// GroupOnly 只存在于一侧权限组的资源和菜单
+type GroupOnly struct {
+	Resources []string `json:"resources"`
+	Menus     []m.Node `json:"menus"`
+}
+
+type GDiffOutput struct {
+	OnlyInGroup GroupOnly `json:"onlyInGroup"`
+	OnlyInOther GroupOnly `json:"onlyInOther"`
+}
+
+// groupContent 权限组的校验规则资源和菜单，菜单以资源为键，便于跨产线比较
+type groupContent struct {
+	resources map[string]struct{}
+	menus     map[string]m.Node
+}
+
+// DiffGroup 对比两个权限组，返回只在其中一个权限组中的资源和菜单
+func (gd *GDiffInput) DiffGroup(ctx *gin.Context) (GDiffOutput, error) {
+	if gd.GroupId <= 0 || gd.OtherGroupId <= 0 {
+		return GDiffOutput{}, helpers.NewError(components.ErrorGroupParamsInvalid, "groupId/otherGroupId 不合法")
+	}
+	group, err := loadGroupContent(ctx, gd.GroupId)
+	if err != nil {
+		return GDiffOutput{}, err
+	}
+	other, err := loadGroupContent(ctx, gd.OtherGroupId)
+	if err != nil {
+		return GDiffOutput{}, err
+	}
+	return GDiffOutput{
+		OnlyInGroup: group.subtract(other),
+		OnlyInOther: other.subtract(group),
+	}, nil
+}
+
+func loadGroupContent(ctx *gin.Context, groupId int64) (groupContent, error) {
+	group := &m.Group{}
+	g, err := group.GetGroupById(ctx, groupId)
+	if err != nil {
+		return groupContent{}, helpers.NewError(components.ErrorDbSelect, "get group by id failure")
+	}
+	if g.ID <= 0 || g.Status == components.GROUP_STATUS_DELETED {
+		return groupContent{}, helpers.NewError(components.ErrorGroupParamsInvalid, fmt.Sprintf("group %d 不存在", groupId))
+	}
+	content := groupContent{
+		resources: make(map[string]struct{}),
+		menus:     make(map[string]m.Node),
+	}
+	casbinRule := &m.CasbinRule{}
+	rules, err := casbinRule.GetCasbinRulesListByConds(ctx, map[string]interface{}{
+		"ptype": components.CASBIN_RULE_PTYPE,
+		"v0":    fmt.Sprintf("%d", g.ID),
+		"v1":    fmt.Sprintf("%d:%d", g.ProductID, g.AppID),
+	})
+	if err != nil {
+		return groupContent{}, helpers.NewError(components.ErrorDbSelect, "get casbin rules failure")
+	}
+	for _, r := range rules {
+		content.resources[r.Resource] = struct{}{}
+	}
+	groupNode := &m.GroupNode{}
+	menus, err := groupNode.GetGroupNodeListByConds(ctx, map[string]interface{}{
+		"group_id":  g.ID,
+		"node_type": components.NODE_TYPE_PAGE,
+	})
+	if err != nil {
+		return groupContent{}, helpers.NewError(components.ErrorDbSelect, "get groupMenuListByConds failure")
+	}
+	if len(menus) == 0 {
+		return content, nil
+	}
+	node := &m.Node{}
+	nodes, err := node.GetNodeListByCondition(ctx, map[string]interface{}{"id": ConvertId2Slice(menus)})
+	if err != nil {
+		return groupContent{}, helpers.NewError(components.ErrorDbSelect, "get node list failure")
+	}
+	for _, n := range nodes {
+		content.menus[n.Resource] = n
+	}
+	return content, nil
+}
+
+func (gc groupContent) subtract(other groupContent) GroupOnly {
+	only := GroupOnly{Resources: []string{}, Menus: []m.Node{}}
+	for resource := range gc.resources {
+		if _, ok := other.resources[resource]; !ok {
+			only.Resources = append(only.Resources, resource)
+		}
+	}
+	for resource, n := range gc.menus {
+		if _, ok := other.menus[resource]; !ok {
+			only.Menus = append(only.Menus, n)
+		}
+	}
+	sort.Strings(only.Resources)
+	sort.Slice(only.Menus, func(i, j int) bool { return only.Menus[i].ID < only.Menus[j].ID })
+	return only
+}
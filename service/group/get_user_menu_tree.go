@@ -0,0 +1,148 @@
+package group
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	h "permission/helpers"
+	m "permission/models"
+	"permission/service/perm"
+	"sort"
+)
+
+type MenuTreeInput struct {
+	AppId        int64
+	ProductId    int64
+	UserId       int64
+	Impersonator int64 // 大于0时为该管理员以 UserId 的身份查看菜单，需持有模拟权限
+}
+
+type MenuTreeOutput struct {
+	MenuList      []m.Node            `json:"menuList"`
+	Impersonation *perm.Impersonation `json:"impersonation,omitempty"`
+}
+
+// GetUserMenuTree 返回用户可见的菜单树：仅包含已授权且展示的页面节点及其祖先节点，同级按 sortOrder 排序
+func (mi *MenuTreeInput) GetUserMenuTree(ctx *gin.Context) (MenuTreeOutput, error) {
+	if mi.ProductId <= 0 || mi.AppId <= 0 || mi.UserId <= 0 {
+		return MenuTreeOutput{}, h.NewError(components.ErrorGroupParamsInvalid, "productId/appId/userId 不合法")
+	}
+	out := MenuTreeOutput{MenuList: []m.Node{}}
+	if mi.Impersonator > 0 {
+		var err error
+		if out.Impersonation, err = perm.Impersonate(ctx, mi.ProductId, mi.AppId, mi.Impersonator, mi.UserId, perm.ImpersonateApiMenuTree, ""); err != nil {
+			return MenuTreeOutput{}, err
+		}
+	}
+	granted, err := mi.getGrantedNodeIds(ctx)
+	if err != nil {
+		return MenuTreeOutput{}, err
+	}
+	if len(granted) == 0 {
+		return out, nil
+	}
+
+	node := &m.Node{}
+	condition := map[string]interface{}{
+		"product_id": mi.ProductId,
+		"app_id":     mi.AppId,
+		"node_type":  components.NODE_TYPE_PAGE,
+	}
+	nodeList, err := node.GetNodeListByCondition(ctx, condition)
+	if err != nil {
+		return MenuTreeOutput{}, h.NewError(components.ErrorDbSelect, "get node list failure")
+	}
+	nodeMap := make(map[int64]m.Node, len(nodeList))
+	for _, n := range nodeList {
+		nodeMap[n.ID] = n
+	}
+
+	// 已授权节点连同其祖先节点一起保留，祖先中有隐藏节点时整条链路不展示
+	keep := make(map[int64]bool)
+	for id := range granted {
+		var chain []int64
+		visible := true
+		for cur, ok := nodeMap[id]; ok; cur, ok = nodeMap[cur.ParentID] {
+			if cur.IsShow != components.NODE_SHOW || len(chain) > len(nodeMap) {
+				visible = false
+				break
+			}
+			chain = append(chain, cur.ID)
+		}
+		if !visible {
+			continue
+		}
+		for _, cid := range chain {
+			keep[cid] = true
+		}
+	}
+
+	treeMap := make(map[int64][]m.Node)
+	for _, n := range nodeList {
+		if !keep[n.ID] {
+			continue
+		}
+		// 父节点不在树中（已删除或跨产线）时挂到根上
+		parentId := n.ParentID
+		if !keep[parentId] {
+			parentId = 0
+		}
+		treeMap[parentId] = append(treeMap[parentId], n)
+	}
+	out.MenuList = buildMenuTree(0, treeMap)
+	return out, nil
+}
+
+func buildMenuTree(parentId int64, treeMap map[int64][]m.Node) []m.Node {
+	nodes := treeMap[parentId]
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].SortOrder != nodes[j].SortOrder {
+			return nodes[i].SortOrder < nodes[j].SortOrder
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+	for i := range nodes {
+		nodes[i].Children = buildMenuTree(nodes[i].ID, treeMap)
+	}
+	return nodes
+}
+
+// getGrantedNodeIds 用户全部有效组员资格对应的启用权限组下绑定的页面节点
+func (mi *MenuTreeInput) getGrantedNodeIds(ctx *gin.Context) (map[int64]struct{}, error) {
+	userGroup := &m.UserGroup{UserId: mi.UserId}
+	condition := map[string]interface{}{
+		"product_id": mi.ProductId,
+		"app_id":     mi.AppId,
+		"user_id":    mi.UserId,
+	}
+	userGroups, err := userGroup.GetValidUserGroupListByCondition(ctx, condition)
+	if err != nil {
+		return nil, h.NewError(components.ErrorDbSelect, "get userGroup by condition error")
+	}
+	granted := make(map[int64]struct{})
+	var groupIds []int64
+	group := &m.Group{}
+	for _, v := range userGroups {
+		groupInfo, err := group.GetGroupById(ctx, v.GroupId)
+		if err != nil {
+			return nil, h.NewError(components.ErrorDbSelect, "get group by id failure")
+		}
+		if groupInfo.ID > 0 && groupInfo.Status == components.GROUP_STATUS_ACTIVE {
+			groupIds = append(groupIds, v.GroupId)
+		}
+	}
+	if len(groupIds) == 0 {
+		return granted, nil
+	}
+	groupNode := &m.GroupNode{}
+	groupNodes, err := groupNode.GetGroupNodeListByConds(ctx, map[string]interface{}{
+		"group_id":  groupIds,
+		"node_type": components.NODE_TYPE_PAGE,
+	})
+	if err != nil {
+		return nil, h.NewError(components.ErrorDbSelect, "get group node failure")
+	}
+	for _, gn := range groupNodes {
+		granted[gn.NodeId] = struct{}{}
+	}
+	return granted, nil
+}
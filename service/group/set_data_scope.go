@@ -0,0 +1,82 @@
+package group
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/service/audit"
+	"permission/service/owner"
+)
+
+type DataScopeInput struct {
+	GroupId   int64
+	NodeId    int64
+	DataScope string
+	UserId    int64
+}
+
+// SetDataScope 设置权限组与接口节点映射关系上的数据范围，空字符串表示不限制；非超级管理员的负责人只能设置其本人持有的节点
+func (di *DataScopeInput) SetDataScope(ctx *gin.Context) (bool, error) {
+	if di.GroupId <= 0 || di.NodeId <= 0 || di.UserId <= 0 {
+		return false, helpers.NewError(components.ErrorDataScopeParamsInvalid, "groupId/nodeId/userId 不合法")
+	}
+	if _, err := m.ParseDataScope(di.DataScope); err != nil {
+		return false, helpers.NewError(components.ErrorDataScopeParamsInvalid, err.Error())
+	}
+	group := &m.Group{}
+	groupInfo, err := group.GetGroupById(ctx, di.GroupId)
+	if err != nil {
+		return false, helpers.NewError(components.ErrorDbSelect, "get group by id failure")
+	}
+	if groupInfo.ID <= 0 {
+		return false, helpers.NewError(components.ErrorDataScopeParamsInvalid, fmt.Sprintf("group %d not exist", di.GroupId))
+	}
+	isSuper, err := owner.Authorize(ctx, di.GroupId, di.UserId)
+	if err != nil {
+		return false, err
+	}
+	if !isSuper {
+		held, err := owner.HeldNodeIds(ctx, groupInfo.ProductID, groupInfo.AppID, di.UserId)
+		if err != nil {
+			return false, err
+		}
+		if err := owner.CheckHeld(held, []int64{di.NodeId}); err != nil {
+			return false, err
+		}
+	}
+	groupNode := &m.GroupNode{}
+	condition := map[string]interface{}{
+		"group_id":  di.GroupId,
+		"node_id":   di.NodeId,
+		"node_type": components.NODE_TYPE_API,
+	}
+	groupNodes, err := groupNode.GetGroupNodeListByConds(ctx, condition)
+	if err != nil {
+		return false, helpers.NewError(components.ErrorDbSelect, "get group node failure")
+	}
+	if len(groupNodes) == 0 {
+		return false, helpers.NewError(components.ErrorDataScopeParamsInvalid, fmt.Sprintf("node %d is not bound to group %d", di.NodeId, di.GroupId))
+	}
+	for i := range groupNodes {
+		if _, err := groupNodes[i].UpdateGroupNodeById(ctx, map[string]interface{}{"data_scope": di.DataScope}); err != nil {
+			return false, helpers.NewError(components.ErrorDbUpdate, "update group node data scope failure")
+		}
+	}
+	audit.Record(ctx, audit.Entry{
+		ProductId:  groupInfo.ProductID,
+		AppId:      groupInfo.AppID,
+		OperateUid: di.UserId,
+		Action:     audit.ActionDataScopeSet,
+		TargetType: audit.TargetGroup,
+		TargetId:   di.GroupId,
+		Detail: map[string]interface{}{
+			"nodeId":    di.NodeId,
+			"before":    groupNodes[0].DataScope,
+			"dataScope": di.DataScope,
+			"delegated": !isSuper,
+		},
+	}, nil)
+	return true, nil
+}
@@ -3,10 +3,16 @@ package group
 import (
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"permission/components"
 	"permission/helpers"
 	m "permission/models"
 	"permission/pkg/golib/v2/zlog"
+	"permission/service/audit"
+	"permission/service/node"
+	"permission/service/owner"
+	"permission/service/snapshot"
+	"sort"
 	"time"
 )
 
@@ -17,8 +23,13 @@ type GUpdateInput struct {
 	UserId      int64
 	GroupName   string
 	GroupStatus int8
-	NodeList    []int64
+	NodeList    []int64 // 直接授予的接口，页面依赖带出的接口由 MenuList 决定，不需要回传
 	MenuList    []int64
+	Version     int64 // 客户端读取到的group版本号，必填，版本号从1开始
+
+	held       map[int64]struct{} // 负责人持有的节点，为nil时不限制
+	nodeSource map[int64]int8     // 更新后各接口的来源
+	reSourced  map[int8][]int64   // 来源发生变化的已有接口
 }
 
 func (gu *GUpdateInput) UpdateGroup(ctx *gin.Context) (bool, error) {
@@ -30,38 +41,97 @@ func (gu *GUpdateInput) UpdateGroup(ctx *gin.Context) (bool, error) {
 		GroupName: gu.GroupName,
 		Status:    gu.GroupStatus,
 		UpdateUid: gu.UserId,
+		Version:   gu.Version,
 	}
 	//groupNode
 	groupNode := &m.GroupNode{
 		GroupId: gu.GroupId,
 	}
+	// 非超级管理员的负责人只能增删其本人持有的节点
+	isSuper, err := owner.Authorize(ctx, gu.GroupId, gu.UserId)
+	if err != nil {
+		return false, err
+	}
+	if !isSuper {
+		if gu.held, err = owner.HeldNodeIds(ctx, gu.ProductId, gu.AppId, gu.UserId); err != nil {
+			return false, err
+		}
+	}
+	return gu.update(ctx, group, groupNode)
+}
+
+// loadDiff 在事务内加锁读取当前的node、menu映射关系，计算需要新增和删除的id
+func (gu *GUpdateInput) loadDiff(ctx *gin.Context, groupNode *m.GroupNode, tx *gorm.DB) (insertNodeIdList, deleteNodeIdList, insertMenuIdList, deleteMenuIdList []int64, err error) {
 	condition := map[string]interface{}{
 		"group_id":  gu.GroupId,
 		"node_type": components.NODE_TYPE_API,
 	}
-	groupNodeList, err := groupNode.GetGroupNodeListByConds(ctx, condition)
+	groupNodeList, err := groupNode.GetGroupNodeListForUpdate(ctx, condition, tx)
 	if err != nil {
-		return false, helpers.NewError(components.ErrorDbSelect, "get groupNodeListByConds failure")
+		return nil, nil, nil, nil, helpers.NewError(components.ErrorDbSelect, "get groupNodeListByConds failure")
 	}
 	condition = map[string]interface{}{
 		"group_id":  gu.GroupId,
 		"node_type": components.NODE_TYPE_PAGE,
 	}
-	groupMenuList, err := groupNode.GetGroupNodeListByConds(ctx, condition)
+	groupMenuList, err := groupNode.GetGroupNodeListForUpdate(ctx, condition, tx)
+	if err != nil {
+		return nil, nil, nil, nil, helpers.NewError(components.ErrorDbSelect, "get groupMenuListByConds failure")
+	}
+	newNodeList, err := gu.resolveNodeList(ctx, groupNodeList, tx)
 	if err != nil {
-		return false, helpers.NewError(components.ErrorDbSelect, "get groupMenuListByConds failure")
+		return nil, nil, nil, nil, helpers.NewError(components.ErrorDbSelect, "get node dependency failure")
 	}
 	oldNodeList := ConvertId2Slice(groupNodeList)
 	oldMenuList := ConvertId2Slice(groupMenuList)
-	insertNodeIdList, deleteNodeIdList := gu.filtrateId(oldNodeList, gu.NodeList)
-	insertMenuIdList, deleteMenuIdList := gu.filtrateId(oldMenuList, gu.MenuList)
-	return gu.update(ctx, group, groupNode, insertNodeIdList, deleteNodeIdList, insertMenuIdList, deleteMenuIdList)
+	insertNodeIdList, deleteNodeIdList = gu.filtrateId(oldNodeList, newNodeList)
+	insertMenuIdList, deleteMenuIdList = gu.filtrateId(oldMenuList, gu.MenuList)
+	return insertNodeIdList, deleteNodeIdList, insertMenuIdList, deleteMenuIdList, nil
+}
+
+// resolveNodeList 合并直接授予的接口和页面依赖的接口。nodeList 中的接口均视为直接授予，原先由依赖带出的改为直接授予；
+// 只由依赖带出的接口在页面收回且没有其他页面依赖时一并收回
+func (gu *GUpdateInput) resolveNodeList(ctx *gin.Context, groupNodeList []m.GroupNode, tx *gorm.DB) ([]int64, error) {
+	deps, err := node.DependentApiIds(ctx, gu.MenuList, tx)
+	if err != nil {
+		return nil, err
+	}
+	gu.nodeSource = make(map[int64]int8, len(gu.NodeList)+len(deps))
+	var nodeList []int64
+	for _, id := range gu.NodeList {
+		if _, ok := gu.nodeSource[id]; ok {
+			continue
+		}
+		gu.nodeSource[id] = components.GROUP_NODE_EXPLICIT
+		nodeList = append(nodeList, id)
+	}
+	var depIds []int64
+	for id := range deps {
+		if _, ok := gu.nodeSource[id]; !ok {
+			depIds = append(depIds, id)
+		}
+	}
+	sort.Slice(depIds, func(i, j int) bool { return depIds[i] < depIds[j] })
+	for _, id := range depIds {
+		gu.nodeSource[id] = components.GROUP_NODE_IMPLIED
+		nodeList = append(nodeList, id)
+	}
+	gu.reSourced = make(map[int8][]int64)
+	for _, gn := range groupNodeList {
+		if source, ok := gu.nodeSource[gn.NodeId]; ok && source != gn.Implied {
+			gu.reSourced[source] = append(gu.reSourced[source], gn.NodeId)
+		}
+	}
+	return nodeList, nil
 }
 
-func (gu *GUpdateInput) update(ctx *gin.Context, group *m.Group, groupNode *m.GroupNode, insertNodeIdList []int64, deleteNodeIdList []int64, insertMenuIdList []int64, deleteMenuIdList []int64) (bool, error) {
+func (gu *GUpdateInput) update(ctx *gin.Context, group *m.Group, groupNode *m.GroupNode) (bool, error) {
 	result, err := func() (bool, error) {
 		node := &m.Node{}
 		var txFlowErr error
+		// 事务提交后增量应用到 enforcer 的规则变更
+		var addedRules [][]string
+		var removedResources []string
 		// 开始事务
 		var tx = helpers.MysqlClientPermission.Begin()
 		if err := tx.Error; err != nil {
@@ -83,21 +153,62 @@ func (gu *GUpdateInput) update(ctx *gin.Context, group *m.Group, groupNode *m.Gr
 					txFlowErr = _err
 					return
 				}
-				helpers.Enforcer.LoadPolicy() //加载新的校验规则
+				gu.applyPolicies(ctx, addedRules, removedResources) //校验规则增量生效
 			}
 		}()
-		// 1.更新group的信息
+		// 0.锁定group行并校验版本号，版本不一致说明已被他人修改
+		current, err := group.GetGroupByIdForUpdate(ctx, group.ID, tx)
+		if err != nil {
+			txFlowErr = err
+			zlog.Errorf(ctx, "lock group fail, err:%v", err)
+			return false, err
+		}
+		if current.ID <= 0 {
+			txFlowErr = helpers.NewError(components.ErrorGroupParamsInvalid, "group 不存在")
+			return false, txFlowErr
+		}
+		if current.Version != group.Version {
+			txFlowErr = helpers.NewError(components.ErrorGroupVersionConflict, fmt.Sprintf("current version %d", current.Version))
+			return false, txFlowErr
+		}
+		insertNodeIdList, deleteNodeIdList, insertMenuIdList, deleteMenuIdList, err := gu.loadDiff(ctx, groupNode, tx)
+		if err != nil {
+			txFlowErr = err
+			return false, err
+		}
+		if gu.held != nil {
+			if err := owner.CheckHeld(gu.held, insertNodeIdList, deleteNodeIdList, insertMenuIdList, deleteMenuIdList); err != nil {
+				txFlowErr = err
+				return false, err
+			}
+		}
+		// 大批量变更前自动为产线打快照，便于回滚
+		changed := len(insertNodeIdList) + len(deleteNodeIdList) + len(insertMenuIdList) + len(deleteMenuIdList)
+		if changed >= components.SNAPSHOT_AUTO_THRESHOLD {
+			reason := fmt.Sprintf("update group %d, %d bindings changed", gu.GroupId, changed)
+			if _, err := snapshot.TakeSnapshot(ctx, gu.ProductId, gu.AppId, gu.UserId, components.SNAPSHOT_TRIGGER_AUTO, reason, tx); err != nil {
+				txFlowErr = err
+				zlog.Errorf(ctx, "take snapshot before update group fail, err:%v", err)
+				return false, err
+			}
+		}
+		// 1.更新group的信息，同时版本号+1
 		updatesFields := map[string]interface{}{
 			"group_name":  group.GroupName,
 			"status":      group.Status,
 			"update_uid":  group.UpdateUid,
 			"update_time": time.Now().Unix(),
 		}
-		if _, err := group.UpdateGroupById(ctx, group.ID, updatesFields, tx); err != nil {
+		rows, err := group.UpdateGroupByIdAndVersion(ctx, group.ID, group.Version, updatesFields, tx)
+		if err != nil {
 			txFlowErr = err
 			zlog.Errorf(ctx, "update group fail, err:%v", err)
 			return false, err
 		}
+		if rows < 1 {
+			txFlowErr = helpers.NewError(components.ErrorGroupVersionConflict, fmt.Sprintf("version %d is stale", group.Version))
+			return false, txFlowErr
+		}
 		// 2.批量插入新的nodeId映射关系
 		var insertNodeList []m.GroupNode
 		var insertCasbinRules []m.CasbinRule
@@ -107,6 +218,7 @@ func (gu *GUpdateInput) update(ctx *gin.Context, group *m.Group, groupNode *m.Gr
 					GroupId:  gu.GroupId,
 					NodeId:   v,
 					NodeType: components.NODE_TYPE_API,
+					Implied:  gu.nodeSource[v],
 				})
 				nodeInfo, err := node.GetNodeById(ctx, v)
 				if err != nil {
@@ -135,6 +247,16 @@ func (gu *GUpdateInput) update(ctx *gin.Context, group *m.Group, groupNode *m.Gr
 				zlog.Errorf(ctx, "batch create casbin rule fail, err:%v", err)
 				return false, err
 			}
+			for i := range insertCasbinRules {
+				addedRules = append(addedRules, insertCasbinRules[i].PolicyRule())
+			}
+		}
+		for source, nodeIds := range gu.reSourced {
+			if _, err := groupNode.UpdateGroupNodeImplied(ctx, nodeIds, source, tx); err != nil {
+				txFlowErr = err
+				zlog.Errorf(ctx, "update rel group node source fail, err:%v", err)
+				return false, err
+			}
 		}
 		if len(deleteNodeIdList) > 0 {
 			// 3.批量删除被删除的nodeId映射关系
@@ -163,6 +285,7 @@ func (gu *GUpdateInput) update(ctx *gin.Context, group *m.Group, groupNode *m.Gr
 					zlog.Errorf(ctx, "batch delete casbin rule fail, err:%v", err)
 					return false, err
 				}
+				removedResources = append(removedResources, nodeInfo.Resource)
 			}
 		}
 		if len(insertMenuIdList) > 0 {
@@ -188,11 +311,43 @@ func (gu *GUpdateInput) update(ctx *gin.Context, group *m.Group, groupNode *m.Gr
 				return false, err
 			}
 		}
+		audit.Record(ctx, audit.Entry{
+			ProductId:  gu.ProductId,
+			AppId:      gu.AppId,
+			OperateUid: gu.UserId,
+			Action:     audit.ActionGroupUpdate,
+			TargetType: audit.TargetGroup,
+			TargetId:   gu.GroupId,
+			Detail: map[string]interface{}{
+				"insertNodes": insertNodeIdList,
+				"deleteNodes": deleteNodeIdList,
+				"insertMenus": insertMenuIdList,
+				"deleteMenus": deleteMenuIdList,
+				"delegated":   gu.held != nil,
+			},
+		}, tx)
 		return true, txFlowErr
 	}()
 	return result, err
 }
 
+// applyPolicies 将已提交的规则变更应用到 enforcer，失败时全量加载兜底
+func (gu *GUpdateInput) applyPolicies(ctx *gin.Context, addedRules [][]string, removedResources []string) {
+	groupId := fmt.Sprintf("%d", gu.GroupId)
+	domain := fmt.Sprintf("%d:%d", gu.ProductId, gu.AppId)
+	err := helpers.AddPolicies(addedRules)
+	for _, resource := range removedResources {
+		if err != nil {
+			break
+		}
+		err = helpers.RemoveFilteredPolicy(groupId, domain, resource)
+	}
+	if err != nil {
+		zlog.Warnf(ctx, "apply policy diff failure, reload all, err:%v", err)
+		helpers.ReloadPolicy()
+	}
+}
+
 func ConvertId2Slice(groupNodeList []m.GroupNode) (oldNodeList []int64) {
 	for _, v := range groupNodeList {
 		oldNodeList = append(oldNodeList, v.NodeId)
@@ -216,5 +371,8 @@ func (gu *GUpdateInput) checkParams() error {
 	if gu.GroupStatus != components.GROUP_STATUS_ACTIVE && gu.GroupStatus != components.GROUP_STATUS_CLOSE {
 		return helpers.NewError(components.ErrorGroupParamsInvalid, "groupStatus 不合法")
 	}
+	if gu.Version <= 0 {
+		return helpers.NewError(components.ErrorGroupParamsInvalid, "version 不合法")
+	}
 	return nil
 }
@@ -17,6 +17,9 @@ type NCreateInput struct {
 	UserId    int64
 	IsShow    int8
 	NodeType  int8
+	SortOrder int
+	Icon      string
+	Route     string
 }
 
 func (nc *NCreateInput) CreateNode(ctx *gin.Context) (bool, error) {
@@ -31,6 +34,9 @@ func (nc *NCreateInput) CreateNode(ctx *gin.Context) (bool, error) {
 		IsShow:     nc.IsShow,
 		ParentID:   nc.ParentId,
 		NodeType:   nc.NodeType,
+		SortOrder:  nc.SortOrder,
+		Icon:       nc.Icon,
+		Route:      nc.Route,
 		CreateUid:  nc.UserId,
 		UpdateUid:  nc.UserId,
 		CreateTime: time.Now().Unix(),
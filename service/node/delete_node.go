@@ -16,5 +16,12 @@ func DeleteNode(ctx *gin.Context, id int64) (bool, error) {
 	if err != nil {
 		return false, helpers.NewError(components.ErrorDbDelete, "delete node by id failure")
 	}
+	// 节点删除后不再参与页面依赖
+	dependency := &m.NodeDependency{}
+	for _, field := range []string{"page_node_id", "api_node_id"} {
+		if _, err := dependency.DeleteNodeDependencyByConds(ctx, map[string]interface{}{field: id}, nil); err != nil {
+			return false, helpers.NewError(components.ErrorDbDelete, "delete node dependency failure")
+		}
+	}
 	return true, nil
 }
@@ -0,0 +1,287 @@
+package node
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"time"
+)
+
+type NDependencyInput struct {
+	NodeId     int64 // 页面节点
+	ApiNodeIds []int64
+	UserId     int64
+}
+
+// DependentApiIds 返回若干页面依赖的接口节点id
+func DependentApiIds(ctx *gin.Context, pageIds []int64, db *gorm.DB) (map[int64]struct{}, error) {
+	apiIds := make(map[int64]struct{})
+	if len(pageIds) == 0 {
+		return apiIds, nil
+	}
+	dependency := &m.NodeDependency{}
+	deps, err := dependency.GetNodeDependencyListByConds(ctx, map[string]interface{}{"page_node_id": pageIds}, db)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deps {
+		apiIds[d.ApiNodeId] = struct{}{}
+	}
+	return apiIds, nil
+}
+
+// SetDependencies 覆盖页面依赖的接口，并同步已授予该页面的权限组
+func (nd *NDependencyInput) SetDependencies(ctx *gin.Context) (ok bool, err error) {
+	if err := nd.checkParams(); err != nil {
+		return false, err
+	}
+	node := &m.Node{}
+	page, err := node.GetNodeById(ctx, nd.NodeId)
+	if err != nil {
+		return false, helpers.NewError(components.ErrorDbSelect, "get node by id failure")
+	}
+	if page.ID <= 0 || page.NodeType != components.NODE_TYPE_PAGE {
+		return false, helpers.NewError(components.ErrorNodeParamsInvalid, "nodeId 不是页面节点")
+	}
+	var apiIds []int64
+	seen := make(map[int64]struct{}, len(nd.ApiNodeIds))
+	for _, id := range nd.ApiNodeIds {
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			apiIds = append(apiIds, id)
+		}
+	}
+	var apis []m.Node
+	if len(apiIds) > 0 {
+		if apis, err = node.GetNodeListByCondition(ctx, map[string]interface{}{
+			"id":         apiIds,
+			"product_id": page.ProductID,
+			"app_id":     page.AppID,
+			"node_type":  components.NODE_TYPE_API,
+		}); err != nil {
+			return false, helpers.NewError(components.ErrorDbSelect, "get node list failure")
+		}
+	}
+	if len(apis) != len(apiIds) {
+		return false, helpers.NewError(components.ErrorNodeParamsInvalid, "apiNodeIds 需为同产线下的接口节点")
+	}
+
+	var txFlowErr error
+	var sync groupSync
+	tx := helpers.MysqlClientPermission.Begin()
+	if err := tx.Error; err != nil {
+		zlog.Warnf(ctx, "DB错误 开启事务失败", err)
+		return false, helpers.NewError(components.ErrorDbError, err.Error())
+	}
+	defer func() {
+		if txFlowErr != nil {
+			if _err := tx.Rollback().Error; _err != nil {
+				zlog.Warnf(ctx, "DB错误 事务回滚失败", _err)
+			}
+			return
+		}
+		if _err := tx.Commit().Error; _err != nil {
+			zlog.Warnf(ctx, "DB错误 事务提交失败", _err)
+			ok, err = false, _err
+			return
+		}
+		sync.apply(ctx)
+	}()
+	dependency := &m.NodeDependency{}
+	if _, txFlowErr = dependency.DeleteNodeDependencyByConds(ctx, map[string]interface{}{"page_node_id": page.ID}, tx); txFlowErr != nil {
+		zlog.Errorf(ctx, "delete node dependency fail, err:%v", txFlowErr)
+		return false, txFlowErr
+	}
+	now := time.Now().Unix()
+	deps := make([]m.NodeDependency, 0, len(apiIds))
+	for _, apiId := range apiIds {
+		deps = append(deps, m.NodeDependency{PageNodeId: page.ID, ApiNodeId: apiId, CreateUid: nd.UserId, CreateTime: now})
+	}
+	if _, txFlowErr = dependency.BatchInsertNodeDependency(ctx, deps, tx); txFlowErr != nil {
+		zlog.Errorf(ctx, "insert node dependency fail, err:%v", txFlowErr)
+		return false, txFlowErr
+	}
+	if txFlowErr = sync.syncGroups(ctx, page, tx); txFlowErr != nil {
+		zlog.Errorf(ctx, "sync group dependency fail, err:%v", txFlowErr)
+		return false, txFlowErr
+	}
+	return true, nil
+}
+
+// groupSync 依赖变更后各权限组需要增删的规则，事务提交后应用到 enforcer
+type groupSync struct {
+	added   [][]string
+	removed [][]string // groupId, domain, resource
+}
+
+// syncGroups 重新计算已授予该页面的权限组由页面依赖带出的接口，补齐缺少的、收回不再需要的
+func (gs *groupSync) syncGroups(ctx *gin.Context, page m.Node, tx *gorm.DB) error {
+	groupNode := &m.GroupNode{}
+	bindings, err := groupNode.GetGroupNodeListForUpdate(ctx, map[string]interface{}{
+		"node_id":   page.ID,
+		"node_type": components.NODE_TYPE_PAGE,
+	}, tx)
+	if err != nil {
+		return err
+	}
+	domain := fmt.Sprintf("%d:%d", page.ProductID, page.AppID)
+	for _, b := range bindings {
+		if err := gs.syncGroup(ctx, b.GroupId, domain, tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (gs *groupSync) syncGroup(ctx *gin.Context, groupId int64, domain string, tx *gorm.DB) error {
+	groupNode := &m.GroupNode{GroupId: groupId}
+	pages, err := groupNode.GetGroupNodeListForUpdate(ctx, map[string]interface{}{
+		"group_id":  groupId,
+		"node_type": components.NODE_TYPE_PAGE,
+	}, tx)
+	if err != nil {
+		return err
+	}
+	pageIds := make([]int64, 0, len(pages))
+	for _, p := range pages {
+		pageIds = append(pageIds, p.NodeId)
+	}
+	needed, err := DependentApiIds(ctx, pageIds, tx)
+	if err != nil {
+		return err
+	}
+	apis, err := groupNode.GetGroupNodeListForUpdate(ctx, map[string]interface{}{
+		"group_id":  groupId,
+		"node_type": components.NODE_TYPE_API,
+	}, tx)
+	if err != nil {
+		return err
+	}
+	bound := make(map[int64]struct{}, len(apis))
+	var removeIds []int64
+	for _, a := range apis {
+		bound[a.NodeId] = struct{}{}
+		if _, ok := needed[a.NodeId]; !ok && a.Implied == components.GROUP_NODE_IMPLIED {
+			removeIds = append(removeIds, a.NodeId)
+		}
+	}
+	var addIds []int64
+	for apiId := range needed {
+		if _, ok := bound[apiId]; !ok {
+			addIds = append(addIds, apiId)
+		}
+	}
+	if len(addIds) == 0 && len(removeIds) == 0 {
+		return nil
+	}
+	node := &m.Node{}
+	nodes, err := node.GetNodeListByCondition(ctx, map[string]interface{}{"id": append(append([]int64{}, addIds...), removeIds...)})
+	if err != nil {
+		return err
+	}
+	resources := make(map[int64]string, len(nodes))
+	for _, n := range nodes {
+		resources[n.ID] = n.Resource
+	}
+	subject := fmt.Sprintf("%d", groupId)
+	var inserts []m.GroupNode
+	var rules []m.CasbinRule
+	for _, apiId := range addIds {
+		inserts = append(inserts, m.GroupNode{
+			GroupId:  groupId,
+			NodeId:   apiId,
+			NodeType: components.NODE_TYPE_API,
+			Implied:  components.GROUP_NODE_IMPLIED,
+		})
+		rules = append(rules, m.CasbinRule{
+			Ptype:           components.CASBIN_RULE_PTYPE,
+			GroupId:         subject,
+			ProductAppField: domain,
+			Resource:        resources[apiId],
+			PermissionType:  components.CASBIN_ACT_ANY,
+			Status:          components.POLICY_STATUS_ALLOW,
+		})
+	}
+	if _, err := groupNode.BatchInsertGroupNode(ctx, inserts, tx); err != nil {
+		return err
+	}
+	casbinRule := &m.CasbinRule{}
+	if _, err := casbinRule.BatchUpsertCasbinRule(ctx, rules, tx); err != nil {
+		return err
+	}
+	for i := range rules {
+		gs.added = append(gs.added, rules[i].PolicyRule())
+	}
+	if _, err := groupNode.BatchDeleteGroupNode(ctx, removeIds, tx); err != nil {
+		return err
+	}
+	for _, apiId := range removeIds {
+		if _, err := casbinRule.DeleteCasbinRuleByCondition(ctx, map[string]interface{}{
+			"ptype": components.CASBIN_RULE_PTYPE,
+			"v0":    subject,
+			"v1":    domain,
+			"v2":    resources[apiId],
+		}, tx); err != nil {
+			return err
+		}
+		gs.removed = append(gs.removed, []string{subject, domain, resources[apiId]})
+	}
+	return nil
+}
+
+func (gs *groupSync) apply(ctx *gin.Context) {
+	err := helpers.AddPolicies(gs.added)
+	for _, fieldValues := range gs.removed {
+		if err != nil {
+			break
+		}
+		err = helpers.RemoveFilteredPolicy(fieldValues...)
+	}
+	if err != nil {
+		zlog.Warnf(ctx, "apply dependency policy failure, reload all, err:%v", err)
+		helpers.ReloadPolicy()
+	}
+}
+
+// GetDependencies 返回页面依赖的接口节点
+func (nd *NDependencyInput) GetDependencies(ctx *gin.Context) ([]m.Node, error) {
+	if nd.NodeId <= 0 {
+		return nil, helpers.NewError(components.ErrorNodeParamsInvalid, "nodeId 不合法")
+	}
+	apiIds, err := DependentApiIds(ctx, []int64{nd.NodeId}, nil)
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get node dependency failure")
+	}
+	nodes := []m.Node{}
+	if len(apiIds) == 0 {
+		return nodes, nil
+	}
+	ids := make([]int64, 0, len(apiIds))
+	for id := range apiIds {
+		ids = append(ids, id)
+	}
+	node := &m.Node{}
+	if nodes, err = node.GetNodeListByCondition(ctx, map[string]interface{}{"id": ids}); err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get node list failure")
+	}
+	return nodes, nil
+}
+
+func (nd *NDependencyInput) checkParams() error {
+	if nd.NodeId <= 0 {
+		return helpers.NewError(components.ErrorNodeParamsInvalid, "nodeId 不合法")
+	}
+	if nd.UserId <= 0 {
+		return helpers.NewError(components.ErrorNodeParamsInvalid, "userId 不合法")
+	}
+	for _, id := range nd.ApiNodeIds {
+		if id <= 0 {
+			return helpers.NewError(components.ErrorNodeParamsInvalid, "apiNodeIds 不合法")
+		}
+	}
+	return nil
+}
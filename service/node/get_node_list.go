@@ -43,11 +43,16 @@ func (li *NodeListInput) GetNodeList(ctx *gin.Context) (ret ListOutput, err erro
 }
 
 func updateCheckedStatus(node *m.Node, groupNodes []m.GroupNode) {
+	explicit := false
 	for _, v := range groupNodes {
 		if node.ID == v.NodeId {
 			node.Selected = 1
+			explicit = explicit || v.Implied != components.GROUP_NODE_IMPLIED
 		}
 	}
+	if node.Selected == 1 && !explicit {
+		node.Implied = 1
+	}
 	children := node.Children
 	if children != nil {
 		for i := 0; i < len(children); i++ {
@@ -63,6 +68,14 @@ func (li *NodeListInput) getNodeTreeMap(ctx *gin.Context) (err error, treeMap ma
 		"node_type": li.NodeType,
 	}
 	nodeList, err := node.GetNodeListByCondition(ctx, condition)
+	if err != nil {
+		return err, nil
+	}
+	if li.NodeType == components.NODE_TYPE_PAGE {
+		if err = attachDependencies(ctx, nodeList); err != nil {
+			return err, nil
+		}
+	}
 	treeMap = make(map[int64][]m.Node)
 	for _, v := range nodeList {
 		treeMap[v.ParentID] = append(treeMap[v.ParentID], v)
@@ -70,6 +83,30 @@ func (li *NodeListInput) getNodeTreeMap(ctx *gin.Context) (err error, treeMap ma
 	return err, treeMap
 }
 
+// attachDependencies 为页面节点填充其依赖的接口节点id
+func attachDependencies(ctx *gin.Context, pages []m.Node) error {
+	if len(pages) == 0 {
+		return nil
+	}
+	pageIds := make([]int64, 0, len(pages))
+	for _, p := range pages {
+		pageIds = append(pageIds, p.ID)
+	}
+	dependency := &m.NodeDependency{}
+	deps, err := dependency.GetNodeDependencyListByConds(ctx, map[string]interface{}{"page_node_id": pageIds}, nil)
+	if err != nil {
+		return err
+	}
+	apiIds := make(map[int64][]int64)
+	for _, d := range deps {
+		apiIds[d.PageNodeId] = append(apiIds[d.PageNodeId], d.ApiNodeId)
+	}
+	for i := range pages {
+		pages[i].Dependencies = apiIds[pages[i].ID]
+	}
+	return nil
+}
+
 func (li *NodeListInput) getChildrenList(node *m.Node, treeMap map[int64][]m.Node) (err error) {
 	node.Children = treeMap[node.ID]
 	for i := 0; i < len(node.Children); i++ {
@@ -90,8 +127,9 @@ func (li *NodeListInput) checkParams() error {
 
 func (li *NodeListInput) getCheckedNodes(ctx *gin.Context) (err error, nodes []m.GroupNode) {
 	// 获取已选node
+	groupIds := []int64{li.GroupId}
 	if li.UserId > 0 && li.GroupId == 0 {
-		// userId -> groupId
+		// userId -> groupIds，用户可同时属于多个权限组
 		userGroup := &m.UserGroup{
 			UserId: li.UserId,
 		}
@@ -101,15 +139,15 @@ func (li *NodeListInput) getCheckedNodes(ctx *gin.Context) (err error, nodes []m
 			"user_id":    li.UserId,
 			"user_type":  components.USER_TYPE_OUTER,
 		}
-		userGroupInfo, _ := userGroup.GetUserGroupByCondition(ctx, condition)
-		li.GroupId = userGroupInfo.GroupId
+		userGroups, _ := userGroup.GetValidUserGroupListByCondition(ctx, condition)
+		groupIds = m.UserGroupIds(userGroups)
 	}
 	groupNode := m.GroupNode{
 		GroupId: li.GroupId,
 	}
-	if li.GroupId > 0 {
+	if len(groupIds) > 0 && groupIds[0] > 0 {
 		condition := map[string]interface{}{
-			"group_id":  li.GroupId,
+			"group_id":  groupIds,
 			"node_type": li.NodeType,
 		}
 		nodes, err = groupNode.GetGroupNodeListByConds(ctx, condition)
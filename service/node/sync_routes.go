@@ -0,0 +1,242 @@
+package node
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v2"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/owner"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Route 路由清单中的一条路由，与 gin.RouteInfo 的 Method、Path 对应
+type Route struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Label  string `json:"label"` // 可选，为空时使用 "METHOD path"
+}
+
+// NSyncInput 路由清单与 OpenApi 文档二选一，同一 path 的多个 method 合并为一个接口节点
+type NSyncInput struct {
+	ProductId int64
+	AppId     int64
+	ParentId  int64
+	UserId    int64
+	Routes    []Route
+	OpenApi   string // OpenApi 3 文档，json 或 yaml
+	DryRun    bool
+}
+
+type NSyncOutput struct {
+	Added   []m.Node `json:"added"`
+	Updated []m.Node `json:"updated"`
+	Stale   []m.Node `json:"stale"`   // 清单中已不存在、本次标记为过期的节点
+	Revived []m.Node `json:"revived"` // 之前标记过期、本次重新出现的节点
+}
+
+var openApiParam = regexp.MustCompile(`\{([^}/]+)\}`)
+
+var httpMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+// SyncRoutes 将路由清单同步为 parentId 下的接口节点：新增缺失的节点、更新名称、标记已下线的节点。
+// 节点不归属于权限组，只有超级管理员可以同步
+func (ns *NSyncInput) SyncRoutes(ctx *gin.Context) (NSyncOutput, error) {
+	if err := ns.checkParams(); err != nil {
+		return NSyncOutput{}, err
+	}
+	if !owner.IsSuperAdmin(ns.UserId) {
+		return NSyncOutput{}, helpers.NewError(components.ErrorNotSuperAdmin, fmt.Sprintf("uid=%d", ns.UserId))
+	}
+	routes := ns.Routes
+	if ns.OpenApi != "" {
+		var err error
+		if routes, err = parseOpenApi(ns.OpenApi); err != nil {
+			return NSyncOutput{}, helpers.NewError(components.ErrorManifestParamsInvalid, err.Error())
+		}
+	}
+	if ns.ParentId > 0 {
+		parent, err := (&m.Node{}).GetNodeById(ctx, ns.ParentId)
+		if err != nil {
+			return NSyncOutput{}, helpers.NewError(components.ErrorDbSelect, "get parent node failure")
+		}
+		if parent.ID <= 0 || parent.ProductID != ns.ProductId || parent.AppID != ns.AppId {
+			return NSyncOutput{}, helpers.NewError(components.ErrorManifestParamsInvalid, "parentId 不存在或不属于该产线")
+		}
+	}
+	wanted, err := mergeRoutes(routes)
+	if err != nil {
+		return NSyncOutput{}, helpers.NewError(components.ErrorManifestParamsInvalid, err.Error())
+	}
+	// 空清单会把 parentId 下的全部接口节点标记为过期，视为清单有误
+	if len(wanted) == 0 {
+		return NSyncOutput{}, helpers.NewError(components.ErrorManifestParamsInvalid, "清单中没有任何路由")
+	}
+
+	node := &m.Node{}
+	existing, err := node.GetNodeListByCondition(ctx, map[string]interface{}{
+		"product_id": ns.ProductId,
+		"app_id":     ns.AppId,
+		"parent_id":  ns.ParentId,
+		"node_type":  components.NODE_TYPE_API,
+	})
+	if err != nil {
+		return NSyncOutput{}, helpers.NewError(components.ErrorDbSelect, "get node list failure")
+	}
+	out := ns.diff(existing, wanted)
+	if ns.DryRun {
+		return out, nil
+	}
+	if err := ns.apply(ctx, out); err != nil {
+		return NSyncOutput{}, err
+	}
+	return out, nil
+}
+
+func (ns *NSyncInput) diff(existing []m.Node, wanted map[string]string) NSyncOutput {
+	out := NSyncOutput{Added: []m.Node{}, Updated: []m.Node{}, Stale: []m.Node{}, Revived: []m.Node{}}
+	now := time.Now().Unix()
+	seen := make(map[string]struct{}, len(existing))
+	for _, n := range existing {
+		seen[n.Resource] = struct{}{}
+		label, ok := wanted[n.Resource]
+		switch {
+		case !ok && n.IsStale == 0:
+			n.IsStale = 1
+			out.Stale = append(out.Stale, n)
+		case ok && n.IsStale == 1:
+			n.IsStale, n.Label = 0, label
+			out.Revived = append(out.Revived, n)
+		case ok && n.Label != label:
+			n.Label = label
+			out.Updated = append(out.Updated, n)
+		}
+	}
+	resources := make([]string, 0, len(wanted))
+	for resource := range wanted {
+		if _, ok := seen[resource]; !ok {
+			resources = append(resources, resource)
+		}
+	}
+	sort.Strings(resources)
+	for _, resource := range resources {
+		out.Added = append(out.Added, m.Node{
+			ProductID:  ns.ProductId,
+			AppID:      ns.AppId,
+			Label:      wanted[resource],
+			Resource:   resource,
+			NodeType:   components.NODE_TYPE_API,
+			ParentID:   ns.ParentId,
+			CreateUid:  ns.UserId,
+			UpdateUid:  ns.UserId,
+			CreateTime: now,
+			UpdateTime: now,
+		})
+	}
+	return out
+}
+
+func (ns *NSyncInput) apply(ctx *gin.Context, out NSyncOutput) (err error) {
+	node := &m.Node{}
+	tx := helpers.MysqlClientPermission.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit().Error
+		}
+	}()
+	if _, err = node.BatchInsertNode(ctx, out.Added, tx); err != nil {
+		zlog.Errorf(ctx, "batch insert synced nodes fail, err:%v", err)
+		return helpers.NewError(components.ErrorDbInsert, "insert node failure")
+	}
+	changed := append(append(append([]m.Node{}, out.Updated...), out.Stale...), out.Revived...)
+	for _, n := range changed {
+		fields := map[string]interface{}{
+			"label":       n.Label,
+			"is_stale":    n.IsStale,
+			"update_uid":  ns.UserId,
+			"update_time": time.Now().Unix(),
+		}
+		if _, err = node.UpdateNodeById(ctx, n.ID, fields, tx); err != nil {
+			zlog.Errorf(ctx, "update synced node %d fail, err:%v", n.ID, err)
+			return helpers.NewError(components.ErrorDbUpdate, "update node failure")
+		}
+	}
+	return nil
+}
+
+// mergeRoutes 按 path 合并路由，返回 resource -> label
+func mergeRoutes(routes []Route) (map[string]string, error) {
+	methods := make(map[string][]string)
+	labels := make(map[string]string)
+	for _, r := range routes {
+		path := strings.TrimSpace(r.Path)
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid path %q", r.Path)
+		}
+		methods[path] = append(methods[path], strings.ToUpper(r.Method))
+		if r.Label != "" && labels[path] == "" {
+			labels[path] = r.Label
+		}
+	}
+	wanted := make(map[string]string, len(methods))
+	for path, ms := range methods {
+		if label := labels[path]; label != "" {
+			wanted[path] = label
+			continue
+		}
+		sort.Strings(ms)
+		wanted[path] = strings.Join(ms, ",") + " " + path
+	}
+	return wanted, nil
+}
+
+// parseOpenApi 解析 OpenApi 3 文档的 paths，路径参数 {id} 转换为 gin 风格的 :id
+func parseOpenApi(doc string) ([]Route, error) {
+	var spec struct {
+		OpenApi string                            `yaml:"openapi"`
+		Paths   map[string]map[string]interface{} `yaml:"paths"`
+	}
+	if err := yaml.Unmarshal([]byte(doc), &spec); err != nil {
+		return nil, fmt.Errorf("openapi document is invalid: %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenApi, "3.") {
+		return nil, fmt.Errorf("only openapi 3.x is supported, got %q", spec.OpenApi)
+	}
+	var routes []Route
+	for path, item := range spec.Paths {
+		ginPath := openApiParam.ReplaceAllString(path, ":$1")
+		for method, operation := range item {
+			if !httpMethods[strings.ToLower(method)] {
+				continue
+			}
+			// path item 中还可能有 parameters 等非操作字段，已在上面按 method 过滤
+			op, _ := operation.(map[interface{}]interface{})
+			summary, _ := op["summary"].(string)
+			routes = append(routes, Route{Method: method, Path: ginPath, Label: summary})
+		}
+	}
+	return routes, nil
+}
+
+func (ns *NSyncInput) checkParams() error {
+	if ns.ProductId <= 0 || ns.AppId <= 0 {
+		return helpers.NewError(components.ErrorManifestParamsInvalid, "productId/appId 不合法")
+	}
+	if ns.ParentId < 0 || ns.UserId <= 0 {
+		return helpers.NewError(components.ErrorManifestParamsInvalid, "parentId/userId 不合法")
+	}
+	if (len(ns.Routes) == 0) == (ns.OpenApi == "") {
+		return helpers.NewError(components.ErrorManifestParamsInvalid, "routes 与 openApi 需且仅需提供一个")
+	}
+	return nil
+}
@@ -9,11 +9,14 @@ import (
 )
 
 type NUpdateInput struct {
-	Id       int64
-	Label    string
-	Resource string
-	ParentId int64
-	UserId   int64
+	Id        int64
+	Label     string
+	Resource  string
+	ParentId  int64
+	UserId    int64
+	SortOrder int
+	Icon      string
+	Route     string
 }
 
 func (nu *NUpdateInput) UpdateNode(ctx *gin.Context) (bool, error) {
@@ -27,10 +30,13 @@ func (nu *NUpdateInput) UpdateNode(ctx *gin.Context) (bool, error) {
 		"label":       nu.Label,
 		"parent_id":   nu.ParentId,
 		"resource":    nu.Resource,
+		"sort_order":  nu.SortOrder,
+		"icon":        nu.Icon,
+		"route":       nu.Route,
 		"update_uid":  nu.UserId,
 		"update_time": time.Now().Unix(),
 	}
-	_, err := node.UpdateNodeById(ctx, nu.Id, updatedFields)
+	_, err := node.UpdateNodeById(ctx, nu.Id, updatedFields, nil)
 	if err != nil {
 		return false, helpers.NewError(components.ErrorDbInsert, "update node by id failure")
 	}
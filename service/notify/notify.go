@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"permission/conf"
+	"permission/helpers"
+	"permission/pkg/golib/v2/rmq"
+	"permission/pkg/golib/v2/zlog"
+	"time"
+)
+
+// Message 权限变更通知的消息体，Event 同时作为rmq消息的tag
+type Message struct {
+	Event     string      `json:"event"`
+	Receivers []int64     `json:"receivers"`
+	Data      interface{} `json:"data"`
+	Time      int64       `json:"time"`
+}
+
+// Publish 通过已配置的kafka/rmq生产者发送通知，通知失败只记录日志不影响主流程
+func Publish(ctx *gin.Context, event string, receivers []int64, data interface{}) {
+	msg := Message{
+		Event:     event,
+		Receivers: receivers,
+		Data:      data,
+		Time:      time.Now().Unix(),
+	}
+	if helpers.KafkaProducer != nil && conf.BasicConf.Permission.NotifyTopic != "" {
+		if err := helpers.KafkaProducer.Pub(ctx, conf.BasicConf.Permission.NotifyTopic, msg); err != nil {
+			zlog.Warnf(ctx, "kafka publish notify failure, event:%s err:%v", event, err)
+		}
+	}
+	content, err := json.Marshal(msg)
+	if err != nil {
+		zlog.Warnf(ctx, "marshal notify failure, event:%s err:%v", event, err)
+		return
+	}
+	rmqMsg, err := rmq.NewMessage(helpers.NotifyRmqService, content)
+	if err != nil {
+		// 未配置rmq生产者
+		return
+	}
+	if _, err := rmqMsg.WithTag(event).Send(ctx); err != nil {
+		zlog.Warnf(ctx, "rmq publish notify failure, event:%s err:%v", event, err)
+	}
+}
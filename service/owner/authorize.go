@@ -0,0 +1,85 @@
+package owner
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/conf"
+	"permission/helpers"
+	m "permission/models"
+)
+
+// IsSuperAdmin 超级管理员由 superAdmins 配置，启动时已校验不为空
+func IsSuperAdmin(uid int64) bool {
+	for _, v := range conf.BasicConf.Permission.SuperAdmins {
+		if v == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize 校验 uid 能否管理权限组，返回 uid 是否为超级管理员；负责人只能管理其本人持有的节点
+func Authorize(ctx *gin.Context, groupId, uid int64) (bool, error) {
+	if IsSuperAdmin(uid) {
+		return true, nil
+	}
+	ok, err := IsOwner(ctx, groupId, uid)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, helpers.NewError(components.ErrorNotGroupOwner, fmt.Sprintf("uid=%d groupId=%d", uid, groupId))
+	}
+	return false, nil
+}
+
+func IsOwner(ctx *gin.Context, groupId, uid int64) (bool, error) {
+	groupOwner := &m.GroupOwner{}
+	owners, err := groupOwner.GetGroupOwnerListByConds(ctx, map[string]interface{}{
+		"group_id": groupId,
+		"user_id":  uid,
+	})
+	if err != nil {
+		return false, helpers.NewError(components.ErrorDbSelect, "get group owner failure")
+	}
+	return len(owners) > 0, nil
+}
+
+// HeldNodeIds uid 在产线下通过有效组员资格持有的节点(接口和页面)
+func HeldNodeIds(ctx *gin.Context, productId, appId, uid int64) (map[int64]struct{}, error) {
+	userGroup := &m.UserGroup{UserId: uid}
+	userGroups, err := userGroup.GetValidUserGroupListByCondition(ctx, map[string]interface{}{
+		"product_id": productId,
+		"app_id":     appId,
+		"user_id":    uid,
+	})
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get userGroup by condition error")
+	}
+	held := make(map[int64]struct{})
+	if len(userGroups) == 0 {
+		return held, nil
+	}
+	groupNode := &m.GroupNode{}
+	groupNodes, err := groupNode.GetGroupNodeListByConds(ctx, map[string]interface{}{"group_id": m.UserGroupIds(userGroups)})
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get group node failure")
+	}
+	for _, gn := range groupNodes {
+		held[gn.NodeId] = struct{}{}
+	}
+	return held, nil
+}
+
+// CheckHeld 负责人增删的节点必须是其本人持有的节点
+func CheckHeld(held map[int64]struct{}, nodeIdLists ...[]int64) error {
+	for _, nodeIds := range nodeIdLists {
+		for _, id := range nodeIds {
+			if _, ok := held[id]; !ok {
+				return helpers.NewError(components.ErrorNodeNotHeld, fmt.Sprintf("nodeId=%d", id))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package owner
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/service/audit"
+	"time"
+)
+
+type OwnerInput struct {
+	GroupId    int64
+	UserId     int64 // 负责人uid
+	OperateUid int64
+}
+
+// AddOwner 超级管理员或该组现有负责人可以添加负责人
+func (oi *OwnerInput) AddOwner(ctx *gin.Context) (bool, error) {
+	group, err := oi.prepare(ctx)
+	if err != nil {
+		return false, err
+	}
+	ok, err := IsOwner(ctx, oi.GroupId, oi.UserId)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		return true, nil
+	}
+	groupOwner := &m.GroupOwner{
+		GroupId:    oi.GroupId,
+		UserId:     oi.UserId,
+		CreateUid:  oi.OperateUid,
+		CreateTime: time.Now().Unix(),
+	}
+	if err := groupOwner.InsertGroupOwner(ctx); err != nil {
+		return false, helpers.NewError(components.ErrorDbInsert, "insert group owner failure")
+	}
+	oi.record(ctx, group, audit.ActionOwnerAdd)
+	return true, nil
+}
+
+func (oi *OwnerInput) RemoveOwner(ctx *gin.Context) (bool, error) {
+	group, err := oi.prepare(ctx)
+	if err != nil {
+		return false, err
+	}
+	groupOwner := &m.GroupOwner{}
+	rows, err := groupOwner.DeleteGroupOwner(ctx, oi.GroupId, oi.UserId)
+	if err != nil {
+		return false, helpers.NewError(components.ErrorDbDelete, "delete group owner failure")
+	}
+	if rows > 0 {
+		oi.record(ctx, group, audit.ActionOwnerRemove)
+	}
+	return true, nil
+}
+
+func (oi *OwnerInput) GetOwnerList(ctx *gin.Context) ([]m.GroupOwner, error) {
+	if oi.GroupId <= 0 {
+		return nil, helpers.NewError(components.ErrorOwnerParamsInvalid, "groupId 不合法")
+	}
+	groupOwner := &m.GroupOwner{}
+	owners, err := groupOwner.GetGroupOwnerListByConds(ctx, map[string]interface{}{"group_id": oi.GroupId})
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get group owner failure")
+	}
+	return owners, nil
+}
+
+func (oi *OwnerInput) prepare(ctx *gin.Context) (m.Group, error) {
+	if oi.GroupId <= 0 || oi.UserId <= 0 || oi.OperateUid <= 0 {
+		return m.Group{}, helpers.NewError(components.ErrorOwnerParamsInvalid, "groupId/userId/operateUid 不合法")
+	}
+	group := &m.Group{}
+	groupInfo, err := group.GetGroupById(ctx, oi.GroupId)
+	if err != nil {
+		return m.Group{}, helpers.NewError(components.ErrorDbSelect, "get group by id failure")
+	}
+	if groupInfo.ID <= 0 || groupInfo.Status == components.GROUP_STATUS_DELETED {
+		return m.Group{}, helpers.NewError(components.ErrorOwnerParamsInvalid, fmt.Sprintf("groupId=%d 不存在", oi.GroupId))
+	}
+	if _, err := Authorize(ctx, oi.GroupId, oi.OperateUid); err != nil {
+		return m.Group{}, err
+	}
+	return groupInfo, nil
+}
+
+func (oi *OwnerInput) record(ctx *gin.Context, group m.Group, action string) {
+	audit.Record(ctx, audit.Entry{
+		ProductId:  group.ProductID,
+		AppId:      group.AppID,
+		OperateUid: oi.OperateUid,
+		Action:     action,
+		TargetType: audit.TargetGroup,
+		TargetId:   oi.GroupId,
+		Detail:     map[string]int64{"ownerUid": oi.UserId},
+	}, nil)
+}
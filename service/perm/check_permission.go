@@ -5,33 +5,156 @@ import (
 	"github.com/gin-gonic/gin"
 	"permission/api"
 	"permission/components"
+	"permission/conf"
 	"permission/helpers"
 	m "permission/models"
 	"permission/pkg/golib/v2/zlog"
+	"permission/service/usage"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type CheckInput struct {
-	ProductId int64
-	AppId     int64
-	UserId    int64
-	Resource  string
+	ProductId     int64
+	AppId         int64
+	UserId        int64
+	Resource      string
+	ClientIp      string
+	Attrs         map[string]string
+	WithDataScope bool  // 为 true 时在通过的结果中附带数据范围
+	Impersonator  int64 // 大于0时为该管理员以 UserId 的身份鉴权，需持有模拟权限
+
+	impersonateApi string // 模拟鉴权审计中记录的接口，为空时为 checkpermission
 }
 
 type CheckOutput struct {
-	Allow bool `json:"allow"`
+	Allow         bool             `json:"allow"`
+	DataScope     *MergedDataScope `json:"dataScope,omitempty"`
+	Impersonation *Impersonation   `json:"impersonation,omitempty"`
 }
 
-func (ci *CheckInput) CheckPermission(ctx *gin.Context) (CheckOutput, error) {
-	err := ci.checkParams()
+func (ci *CheckInput) CheckPermission(ctx *gin.Context) (out CheckOutput, err error) {
+	start := time.Now()
+	dom := fmt.Sprintf("%d:%d", ci.ProductId, ci.AppId)
+	act := components.CASBIN_ACT_ANY
+	// 决定结果的主体和规则：通过时为首个放行的权限组(或用户)，拒绝时为首个命中的拒绝规则
+	var allowBy, denyBy decidedBy
+	var allowGroupIds []int64
+	defer func() {
+		helpers.ObserveStage(helpers.StageTotal, start)
+		result := "deny"
+		by := denyBy
+		if err != nil {
+			result, by = "error", decidedBy{}
+		} else if out.Allow {
+			result, by = "allow", allowBy
+		}
+		helpers.CheckCounter.WithLabelValues(fmt.Sprintf("%d", ci.ProductId), fmt.Sprintf("%d", ci.AppId), result).Inc()
+		// 模拟鉴权不计入目标用户的授权使用情况
+		if result == "allow" && ci.Impersonator == 0 {
+			ci.recordUsage(allowBy.subject, allowGroupIds, start.Unix())
+		}
+		helpers.RecordDecision(helpers.Decision{
+			Time:         start.Unix(),
+			UserId:       ci.UserId,
+			Domain:       dom,
+			Resource:     ci.Resource,
+			Action:       act,
+			Result:       result,
+			Subject:      by.subject,
+			Rule:         by.rule,
+			LatencyUs:    time.Since(start).Microseconds(),
+			Impersonator: ci.Impersonator,
+		})
+	}()
+	err = ci.checkParams()
+	if err != nil {
+		return CheckOutput{Allow: false}, err
+	}
+	var impersonation *Impersonation
+	if ci.Impersonator > 0 {
+		impersonateApi := ci.impersonateApi
+		if impersonateApi == "" {
+			impersonateApi = ImpersonateApiCheck
+		}
+		if impersonation, err = Impersonate(ctx, ci.ProductId, ci.AppId, ci.Impersonator, ci.UserId, impersonateApi, ci.Resource); err != nil {
+			return CheckOutput{Allow: false}, err
+		}
+	}
+	served, err := helpers.EnsureDomain(dom)
+	if err != nil {
+		zlog.Errorf(ctx, "load domain policy failure, domain:%s err:%v", dom, err)
+		return CheckOutput{Allow: false}, helpers.NewError(components.ErrorDomainLoad, err.Error())
+	}
+	if !served {
+		return CheckOutput{Allow: false}, helpers.NewError(components.ErrorDomainNotServed, dom)
+	}
+	userGroups, err := ci.validUserGroups(ctx)
 	if err != nil {
 		return CheckOutput{Allow: false}, err
 	}
+	obj := ci.Resource
+	attrs := &helpers.RequestAttrs{ClientIp: ci.ClientIp, Extra: ci.Attrs, Now: time.Now()}
+	e := helpers.EnforcerFor(dom)
+	// 判断策略中是否存在，用户所在的任一权限组通过即通过
+	enforceStart := time.Now()
+	var result bool
+	for _, v := range userGroups {
+		sub := fmt.Sprintf("%d", v.GroupId)
+		allow, explain, _err := e.EnforceEx(sub, dom, obj, act, attrs)
+		if _err != nil {
+			err = _err
+			break
+		}
+		if allow {
+			result = true
+			allowBy.set(sub, explain)
+			allowGroupIds = append(allowGroupIds, v.GroupId)
+			if !ci.WithDataScope {
+				break
+			}
+		} else {
+			denyBy.set(sub, explain)
+		}
+	}
+	if err == nil && !result {
+		// 权限组未命中时，再校验直接授予该用户的规则
+		sub := components.CASBIN_SUB_USER_PREFIX + fmt.Sprintf("%d", ci.UserId)
+		var explain []string
+		result, explain, err = e.EnforceEx(sub, dom, obj, act, attrs)
+		if result {
+			allowBy.set(sub, explain)
+		} else {
+			denyBy.set(sub, explain)
+		}
+	}
+	helpers.ObserveStage(helpers.StageEnforce, enforceStart)
+	if err != nil {
+		zlog.Errorf(ctx, "casbin check machine does not work err:%s", err)
+	}
+	out = CheckOutput{Allow: result, Impersonation: impersonation}
+	if err == nil && result && ci.WithDataScope {
+		// 直接授予用户的资源没有映射关系，不限制数据范围
+		scope := MergedDataScope{All: true}
+		if len(allowGroupIds) > 0 {
+			if scope, err = loadDataScope(ctx, ci.ProductId, ci.AppId, allowGroupIds, ci.Resource); err != nil {
+				return CheckOutput{Allow: false}, err
+			}
+		}
+		out.DataScope = &scope
+	}
+	return out, err
+}
+
+// validUserGroups 按passport中的用户类型查询用户在产线下有效的组员资格
+func (ci *CheckInput) validUserGroups(ctx *gin.Context) ([]m.UserGroup, error) {
 	var userType int8
 	// 1. 查看userId 是内网/外网 用户 (同时还要查看userId的有效性)
-	infoFromPass, err := api.GetUserInfoByUserId(ctx, ci.AppId, ci.UserId)
+	infoFromPass, err := ci.getUserInfo(ctx)
 	if err != nil {
 		zlog.Errorf(ctx, "passport get userinfo failure", err)
-		return CheckOutput{Allow: false}, helpers.NewError(components.ErrorApiGetUserInfo, err.Error())
+		return nil, helpers.NewError(components.ErrorApiGetUserInfo, err.Error())
 	}
 	if infoFromPass.UserId > 0 {
 		userType = components.USER_TYPE_OUTER
@@ -45,21 +168,64 @@ func (ci *CheckInput) CheckPermission(ctx *gin.Context) (CheckOutput, error) {
 		"user_type":  userType,
 		"user_id":    ci.UserId,
 	}
-	userGroupInfo, err := userGroup.GetUserGroupByCondition(ctx, condition)
+	dbStart := time.Now()
+	userGroups, err := userGroup.GetValidUserGroupListByCondition(ctx, condition)
+	helpers.ObserveStage(helpers.StageDb, dbStart)
 	if err != nil {
-		return CheckOutput{Allow: false}, helpers.NewError(components.ErrorDbSelect, "get userGroup by condition error")
+		return nil, helpers.NewError(components.ErrorDbSelect, "get userGroup by condition error")
 	}
-	sub := fmt.Sprintf("%d", userGroupInfo.GroupId)
-	dom := fmt.Sprintf("%d:%d", ci.ProductId, ci.AppId)
-	obj := ci.Resource
-	act := components.CASBIN_ACT_ANY
-	e := helpers.Enforcer
-	// 判断策略中是否存在
-	result, err := e.Enforce(sub, dom, obj, act)
-	if err != nil {
-		zlog.Errorf(ctx, "casbin check machine does not work err:%s", err)
+	return userGroups, nil
+}
+
+// recordUsage 记录放行所依据的授权；经权限组放行时同时记录组员资格，只有直接授权放行时记录用户的规则。
+// 只记录实际校验并放行的主体，首个放行的权限组之后的授权不再校验，在使用报告中视为冗余
+func (ci *CheckInput) recordUsage(subject string, allowGroupIds []int64, now int64) {
+	if len(allowGroupIds) == 0 {
+		if subject == "" {
+			return
+		}
+		usage.RecordGrant(ci.ProductId, ci.AppId, subject, ci.Resource, now)
+		return
+	}
+	for _, groupId := range allowGroupIds {
+		usage.RecordGrant(ci.ProductId, ci.AppId, strconv.FormatInt(groupId, 10), ci.Resource, now)
+		usage.RecordMember(ci.ProductId, ci.AppId, ci.UserId, groupId, now)
+	}
+}
+
+// decidedBy 决定鉴权结果的主体及其命中的规则
+type decidedBy struct {
+	subject string
+	rule    string
+}
+
+// set 只记录首个命中规则的主体
+func (d *decidedBy) set(subject string, explain []string) {
+	if d.subject != "" || len(explain) == 0 {
+		return
+	}
+	d.subject, d.rule = subject, strings.Join(explain, ", ")
+}
+
+// getUserInfo 配置了 passportCacheTTL 时优先从本地缓存获取，用户禁用等变更最多延迟该时长生效
+func (ci *CheckInput) getUserInfo(ctx *gin.Context) (api.UserInfo, error) {
+	ttl := conf.BasicConf.Permission.PassportCacheTTL
+	key := fmt.Sprintf("passport:%d:%d", ci.AppId, ci.UserId)
+	if ttl > 0 {
+		if v, ok := helpers.LocalCache.Get(key); ok {
+			helpers.ObserveCache("passport", true)
+			return v.(api.UserInfo), nil
+		}
+		helpers.ObserveCache("passport", false)
+	}
+	passStart := time.Now()
+	info, err := api.GetUserInfoByUserId(ctx, ci.AppId, ci.UserId)
+	helpers.ObserveStage(helpers.StagePassport, passStart)
+	if err != nil || ttl <= 0 {
+		return info, err
 	}
-	return CheckOutput{Allow: result}, err
+	helpers.LocalCache.Set(key, info, ttl)
+	return info, nil
 }
 
 func (ci *CheckInput) checkParams() error {
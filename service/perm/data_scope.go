@@ -0,0 +1,134 @@
+package perm
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"sort"
+	"strings"
+)
+
+// MergedDataScope 用户对某资源的数据范围
+// 多个权限组的范围之间为或的关系，All 为 true 时表示不限制，否则满足 Scopes 中任一范围即可
+type MergedDataScope struct {
+	All           bool           `json:"all"`
+	Scopes        []m.DataScope  `json:"scopes"`
+	Impersonation *Impersonation `json:"impersonation,omitempty"`
+}
+
+type DataScopeInput struct {
+	ProductId    int64
+	AppId        int64
+	UserId       int64
+	Resource     string
+	ClientIp     string
+	Attrs        map[string]string
+	Impersonator int64 // 大于0时为该管理员以 UserId 的身份查询，需持有模拟权限
+}
+
+// GetDataScope 与 checkpermission?withDataScope 走同一套鉴权，未通过时返回空范围(无权限)
+func (di *DataScopeInput) GetDataScope(ctx *gin.Context) (MergedDataScope, error) {
+	if di.ProductId <= 0 || di.AppId <= 0 || di.UserId <= 0 || len(di.Resource) <= 0 {
+		return MergedDataScope{}, helpers.NewError(components.ErrorDataScopeParamsInvalid, "productId/appId/userId/resource 不合法")
+	}
+	checkInput := &CheckInput{
+		ProductId:      di.ProductId,
+		AppId:          di.AppId,
+		UserId:         di.UserId,
+		Resource:       di.Resource,
+		ClientIp:       di.ClientIp,
+		Attrs:          di.Attrs,
+		WithDataScope:  true,
+		Impersonator:   di.Impersonator,
+		impersonateApi: ImpersonateApiDataScope,
+	}
+	out, err := checkInput.CheckPermission(ctx)
+	if err != nil {
+		return MergedDataScope{}, err
+	}
+	var scope MergedDataScope
+	if out.Allow && out.DataScope != nil {
+		scope = *out.DataScope
+	}
+	scope.Impersonation = out.Impersonation
+	return scope, nil
+}
+
+// loadDataScope 合并若干权限组在该资源对应接口节点上的数据范围
+func loadDataScope(ctx *gin.Context, productId, appId int64, groupIds []int64, resource string) (MergedDataScope, error) {
+	node := &m.Node{}
+	nodes, err := node.GetNodeListByCondition(ctx, map[string]interface{}{
+		"product_id": productId,
+		"app_id":     appId,
+		"node_type":  components.NODE_TYPE_API,
+		"resource":   resource,
+	})
+	if err != nil {
+		return MergedDataScope{}, helpers.NewError(components.ErrorDbSelect, "get node list failure")
+	}
+	if len(groupIds) == 0 {
+		return MergedDataScope{}, nil
+	}
+	// 放行的规则没有对应的接口节点，没有可用的范围配置，按不限制处理
+	if len(nodes) == 0 {
+		return MergedDataScope{All: true}, nil
+	}
+	nodeIds := make([]int64, 0, len(nodes))
+	for _, n := range nodes {
+		nodeIds = append(nodeIds, n.ID)
+	}
+	groupNode := &m.GroupNode{}
+	groupNodes, err := groupNode.GetGroupNodeListByConds(ctx, map[string]interface{}{
+		"group_id":  groupIds,
+		"node_id":   nodeIds,
+		"node_type": components.NODE_TYPE_API,
+	})
+	if err != nil {
+		return MergedDataScope{}, helpers.NewError(components.ErrorDbSelect, "get group node failure")
+	}
+	return mergeDataScope(groupIds, groupNodes), nil
+}
+
+// mergeDataScope groupIds 为放行的权限组，其中没有绑定该接口或绑定上未配置范围的权限组不限制数据范围
+func mergeDataScope(groupIds []int64, groupNodes []m.GroupNode) MergedDataScope {
+	bound := make(map[int64]struct{}, len(groupNodes))
+	for _, gn := range groupNodes {
+		bound[gn.GroupId] = struct{}{}
+	}
+	for _, groupId := range groupIds {
+		if _, ok := bound[groupId]; !ok {
+			return MergedDataScope{All: true}
+		}
+	}
+	var merged MergedDataScope
+	seen := make(map[string]struct{})
+	for _, gn := range groupNodes {
+		scope, err := m.ParseDataScope(gn.DataScope)
+		if err != nil {
+			// 不合法的范围按无权限处理，不放大数据权限
+			continue
+		}
+		if len(scope) == 0 {
+			return MergedDataScope{All: true}
+		}
+		key := dataScopeKey(scope)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		merged.Scopes = append(merged.Scopes, scope)
+	}
+	return merged
+}
+
+func dataScopeKey(scope m.DataScope) string {
+	parts := make([]string, 0, len(scope))
+	for dimension, values := range scope {
+		sorted := append([]string(nil), values...)
+		sort.Strings(sorted)
+		parts = append(parts, dimension+"="+strings.Join(sorted, ","))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ";")
+}
@@ -0,0 +1,79 @@
+package perm
+
+import (
+	"testing"
+
+	m "permission/models"
+)
+
+func TestMergeDataScope(t *testing.T) {
+	cases := []struct {
+		name       string
+		groupIds   []int64
+		groupNodes []m.GroupNode
+		all        bool
+		scopes     int
+	}{
+		{
+			name:     "所有权限组均配置了范围时取并集",
+			groupIds: []int64{1, 2},
+			groupNodes: []m.GroupNode{
+				{GroupId: 1, DataScope: `{"region":["north"]}`},
+				{GroupId: 2, DataScope: `{"region":["south"]}`},
+			},
+			scopes: 2,
+		},
+		{
+			name:     "相同范围去重",
+			groupIds: []int64{1, 2},
+			groupNodes: []m.GroupNode{
+				{GroupId: 1, DataScope: `{"region":["north","south"]}`},
+				{GroupId: 2, DataScope: `{"region":["south","north"]}`},
+			},
+			scopes: 1,
+		},
+		{
+			name:     "绑定上未配置范围的权限组不限制",
+			groupIds: []int64{1, 2},
+			groupNodes: []m.GroupNode{
+				{GroupId: 1, DataScope: `{"region":["north"]}`},
+				{GroupId: 2, DataScope: ""},
+			},
+			all: true,
+		},
+		{
+			name:     "绑定上配置为空对象的权限组不限制",
+			groupIds: []int64{1},
+			groupNodes: []m.GroupNode{
+				{GroupId: 1, DataScope: "{}"},
+			},
+			all: true,
+		},
+		{
+			name:     "放行的权限组没有绑定该接口时不限制",
+			groupIds: []int64{1, 2},
+			groupNodes: []m.GroupNode{
+				{GroupId: 1, DataScope: `{"region":["north"]}`},
+			},
+			all: true,
+		},
+		{
+			name:     "不合法的范围按无权限处理",
+			groupIds: []int64{1},
+			groupNodes: []m.GroupNode{
+				{GroupId: 1, DataScope: "not json"},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			merged := mergeDataScope(c.groupIds, c.groupNodes)
+			if merged.All != c.all {
+				t.Fatalf("all = %v, want %v", merged.All, c.all)
+			}
+			if len(merged.Scopes) != c.scopes {
+				t.Fatalf("scopes = %d, want %d", len(merged.Scopes), c.scopes)
+			}
+		})
+	}
+}
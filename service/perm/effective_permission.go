@@ -0,0 +1,93 @@
+package perm
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	"permission/pkg/golib/v2/zlog"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type EffectiveInput struct {
+	ProductId    int64
+	AppId        int64
+	UserId       int64
+	ClientIp     string
+	Attrs        map[string]string
+	Impersonator int64 // 大于0时为该管理员以 UserId 的身份查询，需持有模拟权限
+}
+
+// EffectiveResource 用户最终可访问的资源，Subject 为放行的权限组(或 user:<id>)
+type EffectiveResource struct {
+	Resource string `json:"resource"`
+	Subject  string `json:"subject"`
+}
+
+type EffectiveOutput struct {
+	Resources     []EffectiveResource `json:"resources"`
+	Impersonation *Impersonation      `json:"impersonation,omitempty"`
+}
+
+// GetEffectivePermission 按与鉴权相同的规则对用户涉及的每个资源求值，返回最终放行的资源，不计入授权使用情况
+func (ei *EffectiveInput) GetEffectivePermission(ctx *gin.Context) (out EffectiveOutput, err error) {
+	if ei.ProductId <= 0 || ei.AppId <= 0 || ei.UserId <= 0 {
+		return out, helpers.NewError(components.ErrorPermissionParamsInvalid, "productId/appId/userId 不合法")
+	}
+	if ei.Impersonator > 0 {
+		if out.Impersonation, err = Impersonate(ctx, ei.ProductId, ei.AppId, ei.Impersonator, ei.UserId, ImpersonateApiEffective, ""); err != nil {
+			return out, err
+		}
+	}
+	dom := fmt.Sprintf("%d:%d", ei.ProductId, ei.AppId)
+	served, err := helpers.EnsureDomain(dom)
+	if err != nil {
+		zlog.Errorf(ctx, "load domain policy failure, domain:%s err:%v", dom, err)
+		return out, helpers.NewError(components.ErrorDomainLoad, err.Error())
+	}
+	if !served {
+		return out, helpers.NewError(components.ErrorDomainNotServed, dom)
+	}
+	check := &CheckInput{ProductId: ei.ProductId, AppId: ei.AppId, UserId: ei.UserId}
+	userGroups, err := check.validUserGroups(ctx)
+	if err != nil {
+		return out, err
+	}
+	// 与鉴权顺序一致：先按组员资格逐个权限组求值，最后是直接授予用户的规则
+	subjects := make([]string, 0, len(userGroups)+1)
+	for _, v := range userGroups {
+		subjects = append(subjects, strconv.FormatInt(v.GroupId, 10))
+	}
+	subjects = append(subjects, components.CASBIN_SUB_USER_PREFIX+strconv.FormatInt(ei.UserId, 10))
+
+	e := helpers.EnforcerFor(dom)
+	seen := make(map[string]struct{})
+	var resources []string
+	for _, sub := range subjects {
+		for _, rule := range e.GetFilteredPolicy(0, sub, dom) {
+			if _, ok := seen[rule[2]]; !ok {
+				seen[rule[2]] = struct{}{}
+				resources = append(resources, rule[2])
+			}
+		}
+	}
+	sort.Strings(resources)
+	attrs := &helpers.RequestAttrs{ClientIp: ei.ClientIp, Extra: ei.Attrs, Now: time.Now()}
+	out.Resources = make([]EffectiveResource, 0, len(resources))
+	for _, obj := range resources {
+		for _, sub := range subjects {
+			allow, err := e.Enforce(sub, dom, obj, components.CASBIN_ACT_ANY, attrs)
+			if err != nil {
+				zlog.Errorf(ctx, "casbin check machine does not work err:%s", err)
+				return out, helpers.NewError(components.ErrorSystemError, err.Error())
+			}
+			if allow {
+				out.Resources = append(out.Resources, EffectiveResource{Resource: obj, Subject: sub})
+				break
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,58 @@
+package perm
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	"permission/service/audit"
+)
+
+// 模拟用户时写入审计的接口名
+const (
+	ImpersonateApiCheck     = "checkpermission"
+	ImpersonateApiDataScope = "getdatascope"
+	ImpersonateApiMenuTree  = "getusermenutree"
+	ImpersonateApiEffective = "geteffectivepermission"
+)
+
+// Impersonation 以目标用户身份求值的结果上附带的标记
+type Impersonation struct {
+	Impersonated bool  `json:"impersonated"`
+	OperatorUid  int64 `json:"operatorUid"`
+	UserId       int64 `json:"userId"`
+}
+
+// Impersonate 校验 operatorUid 在该产线下被授予了 components.IMPERSONATE_RESOURCE，通过后写审计。
+// 模拟权限按产线授予，超级管理员也需显式授予
+func Impersonate(ctx *gin.Context, productId, appId, operatorUid, userId int64, api, resource string) (*Impersonation, error) {
+	if operatorUid <= 0 || userId <= 0 || operatorUid == userId {
+		return nil, helpers.NewError(components.ErrorImpersonateParamsInvalid, "impersonatorUid/userId 不合法")
+	}
+	check := &CheckInput{
+		ProductId: productId,
+		AppId:     appId,
+		UserId:    operatorUid,
+		Resource:  components.IMPERSONATE_RESOURCE,
+	}
+	out, err := check.CheckPermission(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !out.Allow {
+		return nil, helpers.NewError(components.ErrorImpersonateDenied, fmt.Sprintf("uid=%d domain=%d:%d", operatorUid, productId, appId))
+	}
+	audit.Record(ctx, audit.Entry{
+		ProductId:  productId,
+		AppId:      appId,
+		OperateUid: operatorUid,
+		Action:     audit.ActionImpersonate,
+		TargetType: audit.TargetUser,
+		TargetId:   userId,
+		Detail: map[string]interface{}{
+			"api":      api,
+			"resource": resource,
+		},
+	}, nil)
+	return &Impersonation{Impersonated: true, OperatorUid: operatorUid, UserId: userId}, nil
+}
@@ -2,6 +2,7 @@ package policy
 
 import (
 	"fmt"
+	"git.zuoyebang.cc/pkg/golib/v2/zlog"
 	"github.com/gin-gonic/gin"
 	"permission/components"
 	"permission/helpers"
@@ -14,6 +15,7 @@ type PCreateInput struct {
 	ProductId      int64
 	Resource       string
 	PermissionType string
+	Condition      string
 }
 
 func (pi *PCreateInput) CreatePolicy(ctx *gin.Context) (bool, error) {
@@ -27,6 +29,7 @@ func (pi *PCreateInput) CreatePolicy(ctx *gin.Context) (bool, error) {
 		Resource:        pi.Resource,
 		PermissionType:  pi.PermissionType,
 		Status:          components.POLICY_STATUS_ALLOW,
+		Condition:       pi.Condition,
 	}
 	condition := map[string]interface{}{
 		"ptype": components.CASBIN_RULE_PTYPE,
@@ -39,10 +42,13 @@ func (pi *PCreateInput) CreatePolicy(ctx *gin.Context) (bool, error) {
 	if policyInfo.ID > 0 {
 		return false, helpers.NewError(components.ErrorDbInsert, "校验规则已存在")
 	}
-	_, err := helpers.Enforcer.AddPolicy(fmt.Sprintf("%d", pi.GroupId), fmt.Sprintf("%d:%d", pi.ProductId, pi.AppId), pi.Resource, pi.PermissionType, components.POLICY_STATUS_ALLOW)
-	if err != nil {
+	if err := policy.InsertCasbinRule(ctx); err != nil {
 		return false, helpers.NewError(components.ErrorDbInsert, "insert policy failure")
 	}
+	if err := helpers.AddPolicies([][]string{policy.PolicyRule()}); err != nil {
+		zlog.Warnf(ctx, "casbin add policy failure, reload all", err)
+		helpers.ReloadPolicy()
+	}
 	return true, nil
 }
 
@@ -56,5 +62,8 @@ func (pi *PCreateInput) checkParams() error {
 	if pi.ProductId < 0 {
 		return helpers.NewError(components.ErrorPolicyParamsInvalid, "productId 不合法")
 	}
+	if _, err := helpers.ParseCondition(pi.Condition); err != nil {
+		return helpers.NewError(components.ErrorPolicyParamsInvalid, "condition 不合法: "+err.Error())
+	}
 	return nil
 }
@@ -15,13 +15,20 @@ func DeletePolicyById(ctx *gin.Context, id int64) (bool, error) {
 	casbinRule := &m.CasbinRule{
 		ID: id,
 	}
-	_, err := casbinRule.DeleteCasbinRule(ctx)
+	rule, err := casbinRule.GetCasbinRuleById(ctx, id)
 	if err != nil {
-		return false, helpers.NewError(components.ErrorDbDelete, "delete casbinRule by id failure")
+		return false, helpers.NewError(components.ErrorDbSelect, "get casbinRule by id failure")
 	}
-	err = helpers.Enforcer.LoadPolicy()
+	_, err = casbinRule.DeleteCasbinRule(ctx)
 	if err != nil {
-		zlog.Warnf(ctx, "casbin reload policy failure", err)
+		return false, helpers.NewError(components.ErrorDbDelete, "delete casbinRule by id failure")
+	}
+	if rule.ID <= 0 {
+		return true, nil
+	}
+	if err := helpers.RemovePolicies([][]string{rule.PolicyRule()}); err != nil {
+		zlog.Warnf(ctx, "casbin remove policy failure, reload all", err)
+		helpers.ReloadPolicy()
 	}
 	return true, nil
 
@@ -0,0 +1,205 @@
+package policy
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/audit"
+	"permission/service/owner"
+	"time"
+)
+
+type MSaveInput struct {
+	Name       string
+	Text       string
+	Remark     string
+	OperateUid int64
+}
+
+// SaveModel 新建或修改草稿状态的模型，保存前校验模型能被解析
+func (ms *MSaveInput) SaveModel(ctx *gin.Context) (bool, error) {
+	if ms.Name == "" || ms.Name == components.CASBIN_MODEL_DEFAULT || ms.Text == "" {
+		return false, helpers.NewError(components.ErrorModelParamsInvalid, "name/text 不合法")
+	}
+	if !owner.IsSuperAdmin(ms.OperateUid) {
+		return false, helpers.NewError(components.ErrorNotSuperAdmin, fmt.Sprintf("uid=%d", ms.OperateUid))
+	}
+	if _, err := helpers.ValidateModel(ms.Text, nil, nil); err != nil {
+		return false, helpers.NewError(components.ErrorModelInvalid, err.Error())
+	}
+	casbinModel := &m.CasbinModel{}
+	current, err := casbinModel.GetCasbinModelByName(ctx, ms.Name)
+	if err != nil {
+		return false, helpers.NewError(components.ErrorDbSelect, "get casbin model failure")
+	}
+	now := time.Now().Unix()
+	if current.ID <= 0 {
+		casbinModel = &m.CasbinModel{
+			Name:       ms.Name,
+			Text:       ms.Text,
+			Status:     components.CASBIN_MODEL_STATUS_DRAFT,
+			Remark:     ms.Remark,
+			CreateUid:  ms.OperateUid,
+			UpdateUid:  ms.OperateUid,
+			CreateTime: now,
+			UpdateTime: now,
+		}
+		if err := casbinModel.InsertCasbinModel(ctx); err != nil {
+			return false, helpers.NewError(components.ErrorDbInsert, "insert casbin model failure")
+		}
+		return true, nil
+	}
+	// 已启用的模型可能正被domain使用，修改需以新名称另存
+	rows, err := casbinModel.UpdateCasbinModelByIdAndStatus(ctx, current.ID, components.CASBIN_MODEL_STATUS_DRAFT, map[string]interface{}{
+		"text":        ms.Text,
+		"remark":      ms.Remark,
+		"update_uid":  ms.OperateUid,
+		"update_time": now,
+	})
+	if err != nil {
+		return false, helpers.NewError(components.ErrorDbUpdate, "update casbin model failure")
+	}
+	if rows < 1 {
+		return false, helpers.NewError(components.ErrorModelParamsInvalid, "已启用的模型不可修改")
+	}
+	return true, nil
+}
+
+type MActivateInput struct {
+	Name       string
+	Policies   [][]string          // 试跑用的规则，字段顺序与 policy_definition 一致
+	Cases      []helpers.ModelCase // 试跑用例，全部符合预期才能启用
+	OperateUid int64
+}
+
+// ActivateModel 以给定的规则和用例试跑草稿模型，全部符合预期后启用
+func (ma *MActivateInput) ActivateModel(ctx *gin.Context) (bool, error) {
+	if ma.Name == "" {
+		return false, helpers.NewError(components.ErrorModelParamsInvalid, "name 不合法")
+	}
+	if !owner.IsSuperAdmin(ma.OperateUid) {
+		return false, helpers.NewError(components.ErrorNotSuperAdmin, fmt.Sprintf("uid=%d", ma.OperateUid))
+	}
+	for i, p := range ma.Policies {
+		if len(p) != 6 {
+			return false, helpers.NewError(components.ErrorModelParamsInvalid, fmt.Sprintf("policies[%d] 需为6个字段", i))
+		}
+	}
+	casbinModel := &m.CasbinModel{}
+	current, err := casbinModel.GetCasbinModelByName(ctx, ma.Name)
+	if err != nil {
+		return false, helpers.NewError(components.ErrorDbSelect, "get casbin model failure")
+	}
+	if current.ID <= 0 || current.Status != components.CASBIN_MODEL_STATUS_DRAFT {
+		return false, helpers.NewError(components.ErrorModelParamsInvalid, "模型不存在或已启用")
+	}
+	failed, err := helpers.ValidateModel(current.Text, ma.Policies, ma.Cases)
+	if err != nil {
+		return false, helpers.NewError(components.ErrorModelInvalid, err.Error())
+	}
+	if len(failed) > 0 {
+		return false, helpers.NewError(components.ErrorModelInvalid, fmt.Sprintf("cases %v not as expected", failed))
+	}
+	rows, err := casbinModel.UpdateCasbinModelByIdAndStatus(ctx, current.ID, components.CASBIN_MODEL_STATUS_DRAFT, map[string]interface{}{
+		"status":      components.CASBIN_MODEL_STATUS_ACTIVE,
+		"update_uid":  ma.OperateUid,
+		"update_time": time.Now().Unix(),
+	})
+	if err != nil {
+		return false, helpers.NewError(components.ErrorDbUpdate, "update casbin model failure")
+	}
+	return rows > 0, nil
+}
+
+type MBindInput struct {
+	ProductId  int64
+	AppId      int64
+	ModelName  string // 为 default 时解除绑定，使用默认模型
+	OperateUid int64
+}
+
+// MBindOutput 绑定只在本实例立即重新加载，其他实例在下一次周期全量加载后生效
+type MBindOutput struct {
+	Serving         string `json:"serving"`         // 本实例重新加载后该domain实际使用的模型，加载失败时仍为原模型
+	ClusterDeadline int64  `json:"clusterDeadline"` // 其他实例最晚生效的时间(秒级时间戳)
+}
+
+// BindDomainModel 为domain选用模型，本实例立即按新模型鉴权，其他实例在 policyReloadInterval 内生效
+func (mb *MBindInput) BindDomainModel(ctx *gin.Context) (out MBindOutput, err error) {
+	if mb.ProductId <= 0 || mb.AppId <= 0 || mb.ModelName == "" {
+		return out, helpers.NewError(components.ErrorModelParamsInvalid, "productId/appId/modelName 不合法")
+	}
+	if !owner.IsSuperAdmin(mb.OperateUid) {
+		return out, helpers.NewError(components.ErrorNotSuperAdmin, fmt.Sprintf("uid=%d", mb.OperateUid))
+	}
+	domain := fmt.Sprintf("%d:%d", mb.ProductId, mb.AppId)
+	domainModel := &m.DomainModel{
+		Domain:     domain,
+		ModelName:  mb.ModelName,
+		UpdateUid:  mb.OperateUid,
+		UpdateTime: time.Now().Unix(),
+	}
+	if mb.ModelName == components.CASBIN_MODEL_DEFAULT {
+		if _, err := domainModel.DeleteDomainModel(ctx, domain, nil); err != nil {
+			return out, helpers.NewError(components.ErrorDbDelete, "delete domain model failure")
+		}
+	} else {
+		casbinModel := &m.CasbinModel{}
+		current, err := casbinModel.GetCasbinModelByName(ctx, mb.ModelName)
+		if err != nil {
+			return out, helpers.NewError(components.ErrorDbSelect, "get casbin model failure")
+		}
+		if current.ID <= 0 || current.Status != components.CASBIN_MODEL_STATUS_ACTIVE {
+			return out, helpers.NewError(components.ErrorModelNotActive, mb.ModelName)
+		}
+		if _, err := domainModel.UpsertDomainModel(ctx, nil); err != nil {
+			return out, helpers.NewError(components.ErrorDbUpsert, "upsert domain model failure")
+		}
+	}
+	previous := helpers.DomainModelName(domain)
+	if err := helpers.ReloadPolicy(); err != nil {
+		zlog.Warnf(ctx, "reload policy after bind domain model failure, err:%v", err)
+	}
+	out.Serving = helpers.DomainModelName(domain)
+	out.ClusterDeadline = time.Now().Add(helpers.PolicyReloadInterval()).Unix()
+	audit.Record(ctx, audit.Entry{
+		ProductId:  mb.ProductId,
+		AppId:      mb.AppId,
+		OperateUid: mb.OperateUid,
+		Action:     audit.ActionModelBind,
+		TargetType: audit.TargetDomain,
+		Detail: map[string]interface{}{
+			"from": previous,
+			"to":   mb.ModelName,
+		},
+	}, nil)
+	return out, nil
+}
+
+// ModelListOutput 已注册的模型及绑定到各模型的domain
+type ModelListOutput struct {
+	Models  []m.CasbinModel   `json:"models"`
+	Domains []m.DomainModel   `json:"domains"`
+	Serving map[string]string `json:"serving"` // 本实例当前各domain实际使用的模型，其他实例可能尚未加载最新的绑定
+}
+
+func GetModelList(ctx *gin.Context) (ModelListOutput, error) {
+	casbinModel := &m.CasbinModel{}
+	models, err := casbinModel.GetCasbinModelList(ctx)
+	if err != nil {
+		return ModelListOutput{}, helpers.NewError(components.ErrorDbSelect, "get casbin model list failure")
+	}
+	domainModel := &m.DomainModel{}
+	domains, err := domainModel.GetDomainModelList(ctx)
+	if err != nil {
+		return ModelListOutput{}, helpers.NewError(components.ErrorDbSelect, "get domain model list failure")
+	}
+	out := ModelListOutput{Models: models, Domains: domains, Serving: make(map[string]string, len(domains))}
+	for _, d := range domains {
+		out.Serving[d.Domain] = helpers.DomainModelName(d.Domain)
+	}
+	return out, nil
+}
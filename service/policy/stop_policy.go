@@ -13,16 +13,25 @@ func StopPolicyById(ctx *gin.Context, id int64) (bool, error) {
 		return false, helpers.NewError(components.ErrorPolicyParamsInvalid, "id 不合法")
 	}
 	casbinRule := &m.CasbinRule{}
+	rule, err := casbinRule.GetCasbinRuleById(ctx, id)
+	if err != nil {
+		return false, helpers.NewError(components.ErrorDbSelect, "get casbinRule by id failure")
+	}
 	updatedFields := map[string]interface{}{
 		"v4": components.POLICY_STATUS_DENY,
 	}
-	_, err := casbinRule.UpdateCasbinRuleById(ctx, id, updatedFields)
+	_, err = casbinRule.UpdateCasbinRuleById(ctx, id, updatedFields)
 	if err != nil {
 		return false, helpers.NewError(components.ErrorDbUpdate, "update casbinRule by id failure")
 	}
-	err = helpers.Enforcer.LoadPolicy()
-	if err != nil {
-		zlog.Warnf(ctx, "casbin reload policy failure", err)
+	if rule.ID <= 0 {
+		return true, nil
+	}
+	oldRule := rule.PolicyRule()
+	rule.Status = components.POLICY_STATUS_DENY
+	if err := helpers.UpdatePolicy(oldRule, rule.PolicyRule()); err != nil {
+		zlog.Warnf(ctx, "casbin update policy failure, reload all", err)
+		helpers.ReloadPolicy()
 	}
 	return true, nil
 }
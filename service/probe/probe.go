@@ -0,0 +1,146 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net"
+	"net/url"
+	"permission/conf"
+	"permission/helpers"
+	"time"
+)
+
+// 单个依赖检查的超时时间，探针整体需在k8s探针超时内返回
+const checkTimeout = time.Second
+
+const (
+	StatusOk   = "ok"
+	StatusFail = "fail"
+)
+
+// Result 单个依赖的检查结果
+type Result struct {
+	Status    string                 `json:"status"`
+	LatencyMs int64                  `json:"latencyMs"`
+	Error     string                 `json:"error,omitempty"`
+	Detail    map[string]interface{} `json:"detail,omitempty"`
+}
+
+// Report 探针返回的各依赖检查明细
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type checker func(ctx *gin.Context) (map[string]interface{}, error)
+
+// Ready 就绪检查：数据库、校验规则和passport均可用时才接收流量
+func Ready(ctx *gin.Context) Report {
+	return run(ctx, map[string]checker{
+		"mysql":    checkMysql,
+		"enforcer": checkEnforcer,
+		"passport": checkPassport,
+	})
+}
+
+// Health 存活检查：只检查进程内状态，外部依赖故障不应导致容器被重启；规则加载失败只影响就绪
+func Health(ctx *gin.Context) Report {
+	return run(ctx, map[string]checker{
+		"model": checkModel,
+	})
+}
+
+func run(ctx *gin.Context, checkers map[string]checker) Report {
+	report := Report{Status: StatusOk, Checks: make(map[string]Result, len(checkers))}
+	for name, check := range checkers {
+		start := time.Now()
+		detail, err := check(ctx)
+		result := Result{
+			Status:    StatusOk,
+			LatencyMs: time.Since(start).Milliseconds(),
+			Detail:    detail,
+		}
+		if err != nil {
+			result.Status = StatusFail
+			result.Error = err.Error()
+			report.Status = StatusFail
+		}
+		report.Checks[name] = result
+	}
+	return report
+}
+
+func checkMysql(ctx *gin.Context) (map[string]interface{}, error) {
+	if helpers.MysqlClientPermission == nil {
+		return nil, errors.New("mysql client not initialized")
+	}
+	sqlDB, err := helpers.MysqlClientPermission.DB()
+	if err != nil {
+		return nil, err
+	}
+	c, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	if err := sqlDB.PingContext(c); err != nil {
+		return nil, err
+	}
+	stats := sqlDB.Stats()
+	return map[string]interface{}{
+		"openConnections": stats.OpenConnections,
+		"inUse":           stats.InUse,
+	}, nil
+}
+
+// checkModel casbin 模型已在进程内初始化，不依赖数据库
+func checkModel(ctx *gin.Context) (map[string]interface{}, error) {
+	if helpers.Enforcer == nil {
+		return nil, errors.New("casbin model not loaded")
+	}
+	return nil, nil
+}
+
+// checkEnforcer 模型已加载且最近一次加载规则成功，ageSeconds 为距最近一次成功加载的时长
+func checkEnforcer(ctx *gin.Context) (map[string]interface{}, error) {
+	if _, err := checkModel(ctx); err != nil {
+		return nil, err
+	}
+	loadedAt, lastErr := helpers.PolicyLoadState()
+	if loadedAt.IsZero() {
+		if lastErr != nil {
+			return nil, lastErr
+		}
+		return nil, errors.New("casbin policy not loaded")
+	}
+	detail := map[string]interface{}{
+		"loadedAt":   loadedAt.Unix(),
+		"ageSeconds": int64(time.Since(loadedAt).Seconds()),
+		"policies":   helpers.LoadedPolicyCount(),
+	}
+	if lastErr != nil {
+		return detail, lastErr
+	}
+	return detail, nil
+}
+
+// checkPassport 只检查passport地址是否可以建立连接，不发起业务请求
+func checkPassport(ctx *gin.Context) (map[string]interface{}, error) {
+	u, err := url.Parse(conf.API.Passport.Domain)
+	if err != nil {
+		return nil, err
+	}
+	host := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		host = net.JoinHostPort(u.Hostname(), port)
+	}
+	detail := map[string]interface{}{"addr": host}
+	conn, err := net.DialTimeout("tcp", host, checkTimeout)
+	if err != nil {
+		return detail, err
+	}
+	conn.Close()
+	return detail, nil
+}
@@ -0,0 +1,63 @@
+package review
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/owner"
+	"time"
+)
+
+const expireBatchSize = 20
+
+type CCloseInput struct {
+	CampaignId int64
+	OperateUid int64
+}
+
+type CCloseOutput struct {
+	Expired int `json:"expired"`
+}
+
+// CloseCampaign 超级管理员提前结束活动，与到期结束一样收回仍待复核的组员资格
+func (cc *CCloseInput) CloseCampaign(ctx *gin.Context) (CCloseOutput, error) {
+	if cc.CampaignId <= 0 || cc.OperateUid <= 0 {
+		return CCloseOutput{}, helpers.NewError(components.ErrorReviewParamsInvalid, "campaignId/operateUid 不合法")
+	}
+	if !owner.IsSuperAdmin(cc.OperateUid) {
+		return CCloseOutput{}, helpers.NewError(components.ErrorNotSuperAdmin, fmt.Sprintf("uid=%d", cc.OperateUid))
+	}
+	campaign, err := getCampaign(ctx, cc.CampaignId)
+	if err != nil {
+		return CCloseOutput{}, err
+	}
+	if campaign.Status != components.REVIEW_CAMPAIGN_STATUS_OPEN {
+		return CCloseOutput{}, helpers.NewError(components.ErrorReviewClosed, fmt.Sprintf("campaignId=%d", cc.CampaignId))
+	}
+	expired, err := closeCampaign(ctx, &campaign, cc.OperateUid)
+	if err != nil {
+		return CCloseOutput{}, err
+	}
+	return CCloseOutput{Expired: expired}, nil
+}
+
+// ExpireCampaigns 定时任务：结束已到截止时间的活动，收回仍待复核的组员资格
+func ExpireCampaigns(ctx *gin.Context) error {
+	reviewCampaign := &m.ReviewCampaign{}
+	campaigns, err := reviewCampaign.GetReviewCampaignListBefore(ctx, time.Now().Unix(), expireBatchSize)
+	if err != nil {
+		return err
+	}
+	for i := range campaigns {
+		expired, err := closeCampaign(ctx, &campaigns[i], 0)
+		if err != nil {
+			zlog.Warnf(ctx, "expire review campaign %d failure, err:%v", campaigns[i].ID, err)
+			continue
+		}
+		zlog.Infof(ctx, "review campaign %d expired, revoked pending items:%d", campaigns[i].ID, expired)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/audit"
+	"permission/service/owner"
+	"time"
+)
+
+type CCreateInput struct {
+	ProductId  int64
+	AppId      int64
+	Name       string
+	GroupIds   []int64
+	Deadline   int64
+	OperateUid int64
+}
+
+type CCreateOutput struct {
+	CampaignId int64 `json:"campaignId"`
+	Items      int   `json:"items"`
+}
+
+// CreateCampaign 为所选权限组当前有效的组员打快照，每个组员资格分配给该组的一位负责人复核，负责人不复核自己
+func (cc *CCreateInput) CreateCampaign(ctx *gin.Context) (out CCreateOutput, err error) {
+	if err := cc.checkParams(); err != nil {
+		return out, err
+	}
+	if !owner.IsSuperAdmin(cc.OperateUid) {
+		return out, helpers.NewError(components.ErrorNotSuperAdmin, fmt.Sprintf("uid=%d", cc.OperateUid))
+	}
+	group := &m.Group{}
+	groups, err := group.GetGroupListByConds(ctx, map[string]interface{}{"id": cc.GroupIds})
+	if err != nil {
+		return out, helpers.NewError(components.ErrorDbSelect, "get group list failure")
+	}
+	valid := make(map[int64]bool, len(groups))
+	for _, g := range groups {
+		if g.Status != components.GROUP_STATUS_DELETED && g.ProductID == cc.ProductId && g.AppID == cc.AppId {
+			valid[g.ID] = true
+		}
+	}
+	for _, groupId := range cc.GroupIds {
+		if !valid[groupId] {
+			return out, helpers.NewError(components.ErrorReviewParamsInvalid, fmt.Sprintf("groupId=%d 不存在或不属于该产线", groupId))
+		}
+	}
+	items, err := cc.snapshot(ctx)
+	if err != nil {
+		return out, err
+	}
+
+	var txFlowErr error
+	tx := helpers.MysqlClientPermission.Begin()
+	if err := tx.Error; err != nil {
+		zlog.Warnf(ctx, "DB错误 开启事务失败", err)
+		return out, helpers.NewError(components.ErrorDbError, err.Error())
+	}
+	defer func() {
+		if txFlowErr != nil {
+			if _err := tx.Rollback().Error; _err != nil {
+				zlog.Warnf(ctx, "DB错误 事务回滚失败", _err)
+			}
+			return
+		}
+		if _err := tx.Commit().Error; _err != nil {
+			zlog.Warnf(ctx, "DB错误 事务提交失败", _err)
+			out, err = CCreateOutput{}, _err
+		}
+	}()
+	groupIds, _ := json.Marshal(cc.GroupIds)
+	campaign := &m.ReviewCampaign{
+		ProductId:  cc.ProductId,
+		AppId:      cc.AppId,
+		Name:       cc.Name,
+		GroupIds:   string(groupIds),
+		Status:     components.REVIEW_CAMPAIGN_STATUS_OPEN,
+		Deadline:   cc.Deadline,
+		CreateUid:  cc.OperateUid,
+		CreateTime: time.Now().Unix(),
+	}
+	if txFlowErr = campaign.InsertReviewCampaign(ctx, tx); txFlowErr != nil {
+		zlog.Errorf(ctx, "insert review campaign fail, err:%v", txFlowErr)
+		return out, txFlowErr
+	}
+	for i := range items {
+		items[i].CampaignId = campaign.ID
+	}
+	reviewItem := &m.ReviewItem{}
+	if _, txFlowErr = reviewItem.BatchInsertReviewItem(ctx, items, tx); txFlowErr != nil {
+		zlog.Errorf(ctx, "insert review items fail, err:%v", txFlowErr)
+		return out, txFlowErr
+	}
+	audit.Record(ctx, audit.Entry{
+		ProductId:  cc.ProductId,
+		AppId:      cc.AppId,
+		OperateUid: cc.OperateUid,
+		Action:     audit.ActionReviewCreate,
+		TargetType: audit.TargetReview,
+		TargetId:   campaign.ID,
+		Detail: map[string]interface{}{
+			"name":     cc.Name,
+			"groupIds": cc.GroupIds,
+			"deadline": cc.Deadline,
+			"items":    len(items),
+		},
+	}, tx)
+	return CCreateOutput{CampaignId: campaign.ID, Items: len(items)}, nil
+}
+
+// snapshot 遍历全部分表取所选权限组当前有效的组员资格
+func (cc *CCreateInput) snapshot(ctx *gin.Context) ([]m.ReviewItem, error) {
+	groupOwner := &m.GroupOwner{}
+	owners, err := groupOwner.GetGroupOwnerListByConds(ctx, map[string]interface{}{"group_id": cc.GroupIds})
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get group owner failure")
+	}
+	ownersOf := make(map[int64][]int64, len(cc.GroupIds))
+	for _, o := range owners {
+		ownersOf[o.GroupId] = append(ownersOf[o.GroupId], o.UserId)
+	}
+	now := time.Now().Unix()
+	items := make([]m.ReviewItem, 0)
+	userGroup := &m.UserGroup{}
+	for shard := int64(0); shard < components.USER_GROUP_SHARD_NUM; shard++ {
+		userGroups, err := userGroup.GetUserGroupListByShard(ctx, shard, map[string]interface{}{
+			"product_id": cc.ProductId,
+			"app_id":     cc.AppId,
+			"group_id":   cc.GroupIds,
+			"status":     components.GROUP_STATUS_ACTIVE,
+		}, nil)
+		if err != nil {
+			return nil, helpers.NewError(components.ErrorDbSelect, "get userGroup list failure")
+		}
+		for _, ug := range userGroups {
+			if ug.ExpireTime > 0 && ug.ExpireTime <= now {
+				continue
+			}
+			items = append(items, m.ReviewItem{
+				GroupId:          ug.GroupId,
+				UserType:         ug.UserType,
+				UserId:           ug.UserId,
+				MemberCreateTime: ug.CreateTime,
+				MemberExpireTime: ug.ExpireTime,
+				ReviewerUid:      reviewerOf(ownersOf[ug.GroupId], ug.UserId),
+				Decision:         components.REVIEW_DECISION_PENDING,
+			})
+		}
+	}
+	return items, nil
+}
+
+// reviewerOf 取该组最早的、不是组员本人的负责人；没有时返回0，由超级管理员复核
+func reviewerOf(owners []int64, userId int64) int64 {
+	for _, uid := range owners {
+		if uid != userId {
+			return uid
+		}
+	}
+	return 0
+}
+
+func (cc *CCreateInput) checkParams() error {
+	if cc.ProductId <= 0 || cc.AppId <= 0 || cc.OperateUid <= 0 {
+		return helpers.NewError(components.ErrorReviewParamsInvalid, "productId/appId/operateUid 不合法")
+	}
+	if cc.Name == "" || len(cc.Name) > 128 {
+		return helpers.NewError(components.ErrorReviewParamsInvalid, "name 不合法")
+	}
+	if len(cc.GroupIds) == 0 {
+		return helpers.NewError(components.ErrorReviewParamsInvalid, "groupIds 不能为空")
+	}
+	seen := make(map[int64]bool, len(cc.GroupIds))
+	groupIds := cc.GroupIds[:0]
+	for _, groupId := range cc.GroupIds {
+		if !seen[groupId] {
+			seen[groupId] = true
+			groupIds = append(groupIds, groupId)
+		}
+	}
+	cc.GroupIds = groupIds
+	if cc.Deadline <= time.Now().Unix() {
+		return helpers.NewError(components.ErrorReviewParamsInvalid, "deadline 须晚于当前时间")
+	}
+	return nil
+}
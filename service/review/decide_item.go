@@ -0,0 +1,132 @@
+package review
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/audit"
+	"permission/service/owner"
+	"time"
+)
+
+type CDecideInput struct {
+	CampaignId int64
+	ItemIds    []int64
+	Decision   int8 // 1:保留 2:收回
+	Remark     string
+	OperateUid int64
+}
+
+// DecideItems 复核人对若干条目给出同一结论，收回的组员资格立即删除；条目只能由分配的复核人或超级管理员处理
+func (cd *CDecideInput) DecideItems(ctx *gin.Context) (ok bool, err error) {
+	if err := cd.checkParams(); err != nil {
+		return false, err
+	}
+	campaign, err := getCampaign(ctx, cd.CampaignId)
+	if err != nil {
+		return false, err
+	}
+	if campaign.Status != components.REVIEW_CAMPAIGN_STATUS_OPEN || campaign.Deadline <= time.Now().Unix() {
+		return false, helpers.NewError(components.ErrorReviewClosed, fmt.Sprintf("campaignId=%d", cd.CampaignId))
+	}
+	reviewItem := &m.ReviewItem{}
+	items, err := reviewItem.GetReviewItemListByConds(ctx, map[string]interface{}{
+		"campaign_id": cd.CampaignId,
+		"id":          cd.ItemIds,
+	}, nil)
+	if err != nil {
+		return false, helpers.NewError(components.ErrorDbSelect, "get review item list failure")
+	}
+	if len(items) != len(cd.ItemIds) {
+		return false, helpers.NewError(components.ErrorReviewNotExist, "itemIds 中有不属于该活动的条目")
+	}
+	superAdmin := owner.IsSuperAdmin(cd.OperateUid)
+	for _, item := range items {
+		if item.Decision != components.REVIEW_DECISION_PENDING {
+			return false, helpers.NewError(components.ErrorReviewDecided, fmt.Sprintf("itemId=%d", item.ID))
+		}
+		if !superAdmin && (item.ReviewerUid == 0 || item.ReviewerUid != cd.OperateUid) {
+			return false, helpers.NewError(components.ErrorReviewNotReviewer, fmt.Sprintf("itemId=%d uid=%d", item.ID, cd.OperateUid))
+		}
+	}
+
+	var txFlowErr error
+	tx := helpers.MysqlClientPermission.Begin()
+	if err := tx.Error; err != nil {
+		zlog.Warnf(ctx, "DB错误 开启事务失败", err)
+		return false, helpers.NewError(components.ErrorDbError, err.Error())
+	}
+	defer func() {
+		if txFlowErr != nil {
+			if _err := tx.Rollback().Error; _err != nil {
+				zlog.Warnf(ctx, "DB错误 事务回滚失败", _err)
+			}
+			return
+		}
+		if _err := tx.Commit().Error; _err != nil {
+			zlog.Warnf(ctx, "DB错误 事务提交失败", _err)
+			ok, err = false, _err
+		}
+	}()
+	rows, txFlowErr := reviewItem.UpdateReviewItemByIds(ctx, cd.ItemIds, components.REVIEW_DECISION_PENDING, map[string]interface{}{
+		"decision":    cd.Decision,
+		"decide_uid":  cd.OperateUid,
+		"decide_time": time.Now().Unix(),
+		"remark":      cd.Remark,
+	}, tx)
+	if txFlowErr != nil {
+		zlog.Errorf(ctx, "update review items fail, err:%v", txFlowErr)
+		return false, txFlowErr
+	}
+	if rows != int64(len(cd.ItemIds)) {
+		txFlowErr = helpers.NewError(components.ErrorReviewDecided, "items have been decided by others")
+		return false, txFlowErr
+	}
+	if cd.Decision == components.REVIEW_DECISION_REVOKE {
+		if txFlowErr = revokeItems(ctx, &campaign, items, cd.OperateUid, revokeByReviewer, tx); txFlowErr != nil {
+			zlog.Errorf(ctx, "revoke review items fail, err:%v", txFlowErr)
+			return false, txFlowErr
+		}
+		return true, nil
+	}
+	for _, item := range items {
+		audit.Record(ctx, audit.Entry{
+			ProductId:  campaign.ProductId,
+			AppId:      campaign.AppId,
+			OperateUid: cd.OperateUid,
+			Action:     audit.ActionReviewKeep,
+			TargetType: audit.TargetUser,
+			TargetId:   item.UserId,
+			Detail: map[string]interface{}{
+				"groupId":    item.GroupId,
+				"campaignId": campaign.ID,
+				"itemId":     item.ID,
+				"remark":     cd.Remark,
+			},
+		}, tx)
+	}
+	return true, nil
+}
+
+func (cd *CDecideInput) checkParams() error {
+	if cd.CampaignId <= 0 || cd.OperateUid <= 0 || len(cd.ItemIds) == 0 {
+		return helpers.NewError(components.ErrorReviewParamsInvalid, "campaignId/itemIds/operateUid 不合法")
+	}
+	if cd.Decision != components.REVIEW_DECISION_KEEP && cd.Decision != components.REVIEW_DECISION_REVOKE {
+		return helpers.NewError(components.ErrorReviewParamsInvalid, "decision 不合法")
+	}
+	if len(cd.Remark) > 255 {
+		return helpers.NewError(components.ErrorReviewParamsInvalid, "remark 过长")
+	}
+	seen := make(map[int64]bool, len(cd.ItemIds))
+	for _, id := range cd.ItemIds {
+		if id <= 0 || seen[id] {
+			return helpers.NewError(components.ErrorReviewParamsInvalid, "itemIds 不合法")
+		}
+		seen[id] = true
+	}
+	return nil
+}
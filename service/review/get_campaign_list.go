@@ -0,0 +1,43 @@
+package review
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+)
+
+// CListInput Statuses 为空时不参与筛选
+type CListInput struct {
+	ProductId int64
+	AppId     int64
+	Statuses  []int8
+	PageNo    int
+	PageSize  int
+}
+
+type CListOutput struct {
+	Total        int                `json:"total"`
+	CampaignList []m.ReviewCampaign `json:"campaignList"`
+}
+
+func (cl *CListInput) GetCampaignList(ctx *gin.Context) (CListOutput, error) {
+	if cl.ProductId <= 0 || cl.AppId <= 0 {
+		return CListOutput{}, helpers.NewError(components.ErrorReviewParamsInvalid, "productId/appId 不合法")
+	}
+	condition := map[string]interface{}{
+		"product_id": cl.ProductId,
+		"app_id":     cl.AppId,
+	}
+	if len(cl.Statuses) > 0 {
+		condition["status"] = cl.Statuses
+	}
+	reviewCampaign := &m.ReviewCampaign{}
+	option := &m.Option{IsNeedCnt: true, IsNeedList: true}
+	page := &m.NormalPage{No: cl.PageNo, Size: cl.PageSize}
+	list, cnt, err := reviewCampaign.GetReviewCampaignListByPage(ctx, condition, option, page)
+	if err != nil {
+		return CListOutput{}, helpers.NewError(components.ErrorDbSelect, "get review campaign list failure")
+	}
+	return CListOutput{Total: cnt, CampaignList: list}, nil
+}
@@ -0,0 +1,49 @@
+package review
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+)
+
+// CItemListInput 各筛选条件为0或为空时不参与筛选，复核人查看待办时传 ReviewerUid 和 Decisions=[0]
+type CItemListInput struct {
+	CampaignId  int64
+	GroupId     int64
+	ReviewerUid int64
+	Decisions   []int8
+	PageNo      int
+	PageSize    int
+}
+
+type CItemListOutput struct {
+	Total    int            `json:"total"`
+	ItemList []m.ReviewItem `json:"itemList"`
+}
+
+func (cl *CItemListInput) GetItemList(ctx *gin.Context) (CItemListOutput, error) {
+	if cl.CampaignId <= 0 {
+		return CItemListOutput{}, helpers.NewError(components.ErrorReviewParamsInvalid, "campaignId 不合法")
+	}
+	condition := map[string]interface{}{
+		"campaign_id": cl.CampaignId,
+	}
+	if cl.GroupId > 0 {
+		condition["group_id"] = cl.GroupId
+	}
+	if cl.ReviewerUid > 0 {
+		condition["reviewer_uid"] = cl.ReviewerUid
+	}
+	if len(cl.Decisions) > 0 {
+		condition["decision"] = cl.Decisions
+	}
+	reviewItem := &m.ReviewItem{}
+	option := &m.Option{IsNeedCnt: true, IsNeedList: true}
+	page := &m.NormalPage{No: cl.PageNo, Size: cl.PageSize}
+	list, cnt, err := reviewItem.GetReviewItemListByPage(ctx, condition, option, page)
+	if err != nil {
+		return CItemListOutput{}, helpers.NewError(components.ErrorDbSelect, "get review item list failure")
+	}
+	return CItemListOutput{Total: cnt, ItemList: list}, nil
+}
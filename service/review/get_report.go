@@ -0,0 +1,127 @@
+package review
+
+import (
+	"encoding/csv"
+	"github.com/gin-gonic/gin"
+	"io"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"strconv"
+	"time"
+)
+
+// 报告中复核结论的文字
+var decisionNames = map[int8]string{
+	components.REVIEW_DECISION_PENDING: "pending",
+	components.REVIEW_DECISION_KEEP:    "keep",
+	components.REVIEW_DECISION_REVOKE:  "revoke",
+	components.REVIEW_DECISION_EXPIRED: "expired",
+}
+
+type CReportInput struct {
+	CampaignId int64
+}
+
+type ReportSummary struct {
+	Total   int `json:"total"`
+	Pending int `json:"pending"`
+	Kept    int `json:"kept"`
+	Revoked int `json:"revoked"`
+	Expired int `json:"expired"`
+}
+
+type ReportItem struct {
+	m.ReviewItem
+	GroupName string `json:"groupName"`
+}
+
+type CReportOutput struct {
+	Campaign      m.ReviewCampaign `json:"campaign"`
+	Summary       ReportSummary    `json:"summary"`
+	Items         []ReportItem     `json:"items"`
+	GeneratedTime int64            `json:"generatedTime"`
+}
+
+// GetReport 活动的认证报告：每个组员资格的复核人、结论及处理时间
+func (cr *CReportInput) GetReport(ctx *gin.Context) (out CReportOutput, err error) {
+	if cr.CampaignId <= 0 {
+		return out, helpers.NewError(components.ErrorReviewParamsInvalid, "campaignId 不合法")
+	}
+	if out.Campaign, err = getCampaign(ctx, cr.CampaignId); err != nil {
+		return out, err
+	}
+	reviewItem := &m.ReviewItem{}
+	items, err := reviewItem.GetReviewItemListByConds(ctx, map[string]interface{}{"campaign_id": cr.CampaignId}, nil)
+	if err != nil {
+		return out, helpers.NewError(components.ErrorDbSelect, "get review item list failure")
+	}
+	groupIds := make([]int64, 0)
+	seen := make(map[int64]bool)
+	for _, item := range items {
+		if !seen[item.GroupId] {
+			seen[item.GroupId] = true
+			groupIds = append(groupIds, item.GroupId)
+		}
+	}
+	groupNames := make(map[int64]string, len(groupIds))
+	if len(groupIds) > 0 {
+		group := &m.Group{}
+		groups, err := group.GetGroupListByConds(ctx, map[string]interface{}{"id": groupIds})
+		if err != nil {
+			return out, helpers.NewError(components.ErrorDbSelect, "get group list failure")
+		}
+		for _, g := range groups {
+			groupNames[g.ID] = g.GroupName
+		}
+	}
+	out.Items = make([]ReportItem, 0, len(items))
+	for _, item := range items {
+		out.Items = append(out.Items, ReportItem{ReviewItem: item, GroupName: groupNames[item.GroupId]})
+		out.Summary.Total++
+		switch item.Decision {
+		case components.REVIEW_DECISION_PENDING:
+			out.Summary.Pending++
+		case components.REVIEW_DECISION_KEEP:
+			out.Summary.Kept++
+		case components.REVIEW_DECISION_REVOKE:
+			out.Summary.Revoked++
+		case components.REVIEW_DECISION_EXPIRED:
+			out.Summary.Expired++
+		}
+	}
+	out.GeneratedTime = time.Now().Unix()
+	return out, nil
+}
+
+// WriteCsv 以csv导出报告条目，时间为本地时间
+func (out *CReportOutput) WriteCsv(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"campaignId", "groupId", "groupName", "userType", "userId", "memberCreateTime",
+		"memberExpireTime", "reviewerUid", "decision", "decideUid", "decideTime", "remark"})
+	for _, item := range out.Items {
+		_ = writer.Write([]string{
+			strconv.FormatInt(item.CampaignId, 10),
+			strconv.FormatInt(item.GroupId, 10),
+			item.GroupName,
+			strconv.Itoa(int(item.UserType)),
+			strconv.FormatInt(item.UserId, 10),
+			formatTime(item.MemberCreateTime),
+			formatTime(item.MemberExpireTime),
+			strconv.FormatInt(item.ReviewerUid, 10),
+			decisionNames[item.Decision],
+			strconv.FormatInt(item.DecideUid, 10),
+			formatTime(item.DecideTime),
+			item.Remark,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatTime(ts int64) string {
+	if ts <= 0 {
+		return ""
+	}
+	return time.Unix(ts, 0).Format("2006-01-02 15:04:05")
+}
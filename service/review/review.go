@@ -0,0 +1,136 @@
+package review
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/audit"
+	"time"
+)
+
+// 组员资格被收回的原因，写入审计
+const (
+	revokeByReviewer = "review_revoke"
+	revokeByDeadline = "review_expired"
+)
+
+// revokeItems 在事务中删除条目对应的组员资格，组员资格已不存在时跳过
+func revokeItems(ctx *gin.Context, campaign *m.ReviewCampaign, items []m.ReviewItem, operateUid int64, reason string, tx *gorm.DB) error {
+	userGroup := &m.UserGroup{}
+	for _, item := range items {
+		shard := item.UserId % components.USER_GROUP_SHARD_NUM
+		rows, err := userGroup.DeleteUserGroupByShard(ctx, shard, map[string]interface{}{
+			"product_id": campaign.ProductId,
+			"app_id":     campaign.AppId,
+			"user_type":  item.UserType,
+			"user_id":    item.UserId,
+			"group_id":   item.GroupId,
+		}, tx)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			continue
+		}
+		audit.Record(ctx, audit.Entry{
+			ProductId:  campaign.ProductId,
+			AppId:      campaign.AppId,
+			OperateUid: operateUid,
+			Action:     audit.ActionMemberRemove,
+			TargetType: audit.TargetUser,
+			TargetId:   item.UserId,
+			Detail: map[string]interface{}{
+				"groupId":    item.GroupId,
+				"campaignId": campaign.ID,
+				"itemId":     item.ID,
+				"reason":     reason,
+			},
+		}, tx)
+	}
+	return nil
+}
+
+// closeCampaign 结束活动，仍待复核的条目按收回处理；closeUid 为0表示到期自动结束
+func closeCampaign(ctx *gin.Context, campaign *m.ReviewCampaign, closeUid int64) (expired int, err error) {
+	var txFlowErr error
+	tx := helpers.MysqlClientPermission.Begin()
+	if err := tx.Error; err != nil {
+		zlog.Warnf(ctx, "DB错误 开启事务失败", err)
+		return 0, helpers.NewError(components.ErrorDbError, err.Error())
+	}
+	defer func() {
+		if txFlowErr != nil {
+			if _err := tx.Rollback().Error; _err != nil {
+				zlog.Warnf(ctx, "DB错误 事务回滚失败", _err)
+			}
+			return
+		}
+		if _err := tx.Commit().Error; _err != nil {
+			zlog.Warnf(ctx, "DB错误 事务提交失败", _err)
+			expired, err = 0, _err
+		}
+	}()
+	now := time.Now().Unix()
+	// 先抢占状态，避免多实例重复处理
+	reviewCampaign := &m.ReviewCampaign{}
+	rows, txFlowErr := reviewCampaign.UpdateReviewCampaignByIdAndStatus(ctx, campaign.ID, components.REVIEW_CAMPAIGN_STATUS_OPEN, map[string]interface{}{
+		"status":     components.REVIEW_CAMPAIGN_STATUS_CLOSED,
+		"close_uid":  closeUid,
+		"close_time": now,
+	}, tx)
+	if txFlowErr != nil {
+		return 0, txFlowErr
+	}
+	if rows < 1 {
+		txFlowErr = helpers.NewError(components.ErrorReviewClosed, fmt.Sprintf("campaignId=%d", campaign.ID))
+		return 0, txFlowErr
+	}
+	reviewItem := &m.ReviewItem{}
+	pending, txFlowErr := reviewItem.GetReviewItemListByConds(ctx, map[string]interface{}{
+		"campaign_id": campaign.ID,
+		"decision":    components.REVIEW_DECISION_PENDING,
+	}, tx)
+	if txFlowErr != nil {
+		return 0, txFlowErr
+	}
+	ids := make([]int64, 0, len(pending))
+	for _, item := range pending {
+		ids = append(ids, item.ID)
+	}
+	if _, txFlowErr = reviewItem.UpdateReviewItemByIds(ctx, ids, components.REVIEW_DECISION_PENDING, map[string]interface{}{
+		"decision":    components.REVIEW_DECISION_EXPIRED,
+		"decide_time": now,
+	}, tx); txFlowErr != nil {
+		return 0, txFlowErr
+	}
+	if txFlowErr = revokeItems(ctx, campaign, pending, closeUid, revokeByDeadline, tx); txFlowErr != nil {
+		zlog.Errorf(ctx, "revoke pending review items fail, campaignId:%d err:%v", campaign.ID, txFlowErr)
+		return 0, txFlowErr
+	}
+	audit.Record(ctx, audit.Entry{
+		ProductId:  campaign.ProductId,
+		AppId:      campaign.AppId,
+		OperateUid: closeUid,
+		Action:     audit.ActionReviewClose,
+		TargetType: audit.TargetReview,
+		TargetId:   campaign.ID,
+		Detail:     map[string]interface{}{"expired": len(pending)},
+	}, tx)
+	return len(pending), nil
+}
+
+func getCampaign(ctx *gin.Context, campaignId int64) (m.ReviewCampaign, error) {
+	reviewCampaign := &m.ReviewCampaign{}
+	campaign, err := reviewCampaign.GetReviewCampaignById(ctx, campaignId)
+	if err != nil {
+		return campaign, helpers.NewError(components.ErrorDbSelect, "get review campaign failure")
+	}
+	if campaign.ID <= 0 {
+		return campaign, helpers.NewError(components.ErrorReviewNotExist, fmt.Sprintf("campaignId=%d", campaignId))
+	}
+	return campaign, nil
+}
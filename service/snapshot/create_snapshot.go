@@ -0,0 +1,60 @@
+package snapshot
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	"permission/service/audit"
+	"permission/service/owner"
+)
+
+type SCreateInput struct {
+	ProductId int64
+	AppId     int64
+	UserId    int64
+	Reason    string
+}
+
+type SCreateOutput struct {
+	SnapshotId int64 `json:"snapshotId"`
+}
+
+// CreateSnapshot 手动为产线打快照，快照包含产线下全部组员，只有超级管理员可以创建
+func (sc *SCreateInput) CreateSnapshot(ctx *gin.Context) (SCreateOutput, error) {
+	if err := sc.checkParams(); err != nil {
+		return SCreateOutput{}, err
+	}
+	if !owner.IsSuperAdmin(sc.UserId) {
+		return SCreateOutput{}, helpers.NewError(components.ErrorNotSuperAdmin, fmt.Sprintf("uid=%d", sc.UserId))
+	}
+	id, err := TakeSnapshot(ctx, sc.ProductId, sc.AppId, sc.UserId, components.SNAPSHOT_TRIGGER_MANUAL, sc.Reason, nil)
+	if err != nil {
+		return SCreateOutput{}, err
+	}
+	audit.Record(ctx, audit.Entry{
+		ProductId:  sc.ProductId,
+		AppId:      sc.AppId,
+		OperateUid: sc.UserId,
+		Action:     audit.ActionSnapshotCreate,
+		TargetType: audit.TargetDomain,
+		TargetId:   id,
+		Detail: map[string]interface{}{
+			"reason": sc.Reason,
+		},
+	}, nil)
+	return SCreateOutput{SnapshotId: id}, nil
+}
+
+func (sc *SCreateInput) checkParams() error {
+	if sc.ProductId <= 0 {
+		return helpers.NewError(components.ErrorSnapshotParamsInvalid, "productId 不合法")
+	}
+	if sc.AppId <= 0 {
+		return helpers.NewError(components.ErrorSnapshotParamsInvalid, "appId 不合法")
+	}
+	if sc.UserId <= 0 {
+		return helpers.NewError(components.ErrorSnapshotParamsInvalid, "userId 不合法")
+	}
+	return nil
+}
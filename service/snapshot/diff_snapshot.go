@@ -0,0 +1,153 @@
+package snapshot
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"strings"
+)
+
+// SDiffInput 对比两个快照，FromId/ToId 为0时表示产线当前线上数据
+type SDiffInput struct {
+	ProductId int64
+	AppId     int64
+	FromId    int64
+	ToId      int64
+}
+
+// 以下 Added 表示在To中存在而From中不存在，Removed 反之
+type GroupDiff struct {
+	Added   []m.Group `json:"added"`
+	Removed []m.Group `json:"removed"`
+	Changed []m.Group `json:"changed"`
+}
+
+type GroupNodeDiff struct {
+	Added   []m.GroupNode `json:"added"`
+	Removed []m.GroupNode `json:"removed"`
+}
+
+type RuleDiff struct {
+	Added   []m.CasbinRule `json:"added"`
+	Removed []m.CasbinRule `json:"removed"`
+}
+
+type MemberDiff struct {
+	Added   []m.UserGroup `json:"added"`
+	Removed []m.UserGroup `json:"removed"`
+}
+
+type SDiffOutput struct {
+	Groups     GroupDiff     `json:"groups"`
+	GroupNodes GroupNodeDiff `json:"groupNodes"`
+	Rules      RuleDiff      `json:"rules"`
+	Members    MemberDiff    `json:"members"`
+}
+
+func (sd *SDiffInput) DiffSnapshot(ctx *gin.Context) (SDiffOutput, error) {
+	if sd.ProductId <= 0 || sd.AppId <= 0 {
+		return SDiffOutput{}, helpers.NewError(components.ErrorSnapshotParamsInvalid, "productId/appId 不合法")
+	}
+	if sd.FromId < 0 || sd.ToId < 0 || sd.FromId == sd.ToId {
+		return SDiffOutput{}, helpers.NewError(components.ErrorSnapshotParamsInvalid, "fromId/toId 不合法")
+	}
+	from, err := loadSnapshotContent(ctx, sd.ProductId, sd.AppId, sd.FromId)
+	if err != nil {
+		return SDiffOutput{}, err
+	}
+	to, err := loadSnapshotContent(ctx, sd.ProductId, sd.AppId, sd.ToId)
+	if err != nil {
+		return SDiffOutput{}, err
+	}
+	return Diff(from, to), nil
+}
+
+// Diff 计算from到to的变化
+func Diff(from, to Content) (out SDiffOutput) {
+	fromGroups := make(map[int64]m.Group, len(from.Groups))
+	for _, g := range from.Groups {
+		fromGroups[g.ID] = g
+	}
+	for _, g := range to.Groups {
+		old, ok := fromGroups[g.ID]
+		if !ok {
+			out.Groups.Added = append(out.Groups.Added, g)
+			continue
+		}
+		if old.GroupName != g.GroupName || old.Status != g.Status || old.ParentId != g.ParentId {
+			out.Groups.Changed = append(out.Groups.Changed, g)
+		}
+		delete(fromGroups, g.ID)
+	}
+	for _, g := range from.Groups {
+		if _, ok := fromGroups[g.ID]; ok {
+			out.Groups.Removed = append(out.Groups.Removed, g)
+		}
+	}
+
+	fromNodes := make(map[string]struct{}, len(from.GroupNodes))
+	for _, gn := range from.GroupNodes {
+		fromNodes[groupNodeKey(gn)] = struct{}{}
+	}
+	toNodes := make(map[string]struct{}, len(to.GroupNodes))
+	for _, gn := range to.GroupNodes {
+		toNodes[groupNodeKey(gn)] = struct{}{}
+		if _, ok := fromNodes[groupNodeKey(gn)]; !ok {
+			out.GroupNodes.Added = append(out.GroupNodes.Added, gn)
+		}
+	}
+	for _, gn := range from.GroupNodes {
+		if _, ok := toNodes[groupNodeKey(gn)]; !ok {
+			out.GroupNodes.Removed = append(out.GroupNodes.Removed, gn)
+		}
+	}
+
+	fromRules := make(map[string]struct{}, len(from.Rules))
+	for _, r := range from.Rules {
+		fromRules[ruleKey(r)] = struct{}{}
+	}
+	toRules := make(map[string]struct{}, len(to.Rules))
+	for _, r := range to.Rules {
+		toRules[ruleKey(r)] = struct{}{}
+		if _, ok := fromRules[ruleKey(r)]; !ok {
+			out.Rules.Added = append(out.Rules.Added, r)
+		}
+	}
+	for _, r := range from.Rules {
+		if _, ok := toRules[ruleKey(r)]; !ok {
+			out.Rules.Removed = append(out.Rules.Removed, r)
+		}
+	}
+
+	fromMembers := make(map[string]struct{}, len(from.Members))
+	for _, ug := range from.Members {
+		fromMembers[memberKey(ug)] = struct{}{}
+	}
+	toMembers := make(map[string]struct{}, len(to.Members))
+	for _, ug := range to.Members {
+		toMembers[memberKey(ug)] = struct{}{}
+		if _, ok := fromMembers[memberKey(ug)]; !ok {
+			out.Members.Added = append(out.Members.Added, ug)
+		}
+	}
+	for _, ug := range from.Members {
+		if _, ok := toMembers[memberKey(ug)]; !ok {
+			out.Members.Removed = append(out.Members.Removed, ug)
+		}
+	}
+	return out
+}
+
+func groupNodeKey(gn m.GroupNode) string {
+	return fmt.Sprintf("%d:%d:%d:%s", gn.GroupId, gn.NodeId, gn.NodeType, gn.DataScope)
+}
+
+func ruleKey(r m.CasbinRule) string {
+	return strings.Join([]string{r.Ptype, r.GroupId, r.ProductAppField, r.Resource, r.PermissionType, r.Status, r.Condition}, "|")
+}
+
+func memberKey(ug m.UserGroup) string {
+	return fmt.Sprintf("%d:%d:%d:%d", ug.UserType, ug.UserId, ug.GroupId, ug.Status)
+}
@@ -0,0 +1,38 @@
+package snapshot
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+)
+
+type SListInput struct {
+	ProductId int64
+	AppId     int64
+	PageNo    int
+	PageSize  int
+}
+
+type SListOutput struct {
+	Total        int          `json:"total"`
+	SnapshotList []m.Snapshot `json:"snapshotList"`
+}
+
+func (sl *SListInput) GetSnapshotList(ctx *gin.Context) (SListOutput, error) {
+	if sl.ProductId <= 0 || sl.AppId <= 0 {
+		return SListOutput{}, helpers.NewError(components.ErrorSnapshotParamsInvalid, "productId/appId 不合法")
+	}
+	snapshot := &m.Snapshot{}
+	condition := map[string]interface{}{
+		"product_id": sl.ProductId,
+		"app_id":     sl.AppId,
+	}
+	option := &m.Option{IsNeedCnt: true, IsNeedList: true}
+	page := &m.NormalPage{No: sl.PageNo, Size: sl.PageSize}
+	list, cnt, err := snapshot.GetSnapshotListByPage(ctx, condition, option, page)
+	if err != nil {
+		return SListOutput{}, helpers.NewError(components.ErrorDbSelect, "get snapshot list failure")
+	}
+	return SListOutput{Total: cnt, SnapshotList: list}, nil
+}
@@ -0,0 +1,194 @@
+package snapshot
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/audit"
+	"permission/service/owner"
+	"time"
+)
+
+type SRollbackInput struct {
+	ProductId  int64
+	AppId      int64
+	SnapshotId int64
+	UserId     int64
+}
+
+type SRollbackOutput struct {
+	// 回滚前自动保存的快照，可用于撤销本次回滚
+	BackupSnapshotId int64 `json:"backupSnapshotId"`
+}
+
+// RollbackSnapshot 将产线整体恢复为快照时的状态，只有超级管理员可以回滚
+func (sr *SRollbackInput) RollbackSnapshot(ctx *gin.Context) (SRollbackOutput, error) {
+	if err := sr.checkParams(); err != nil {
+		return SRollbackOutput{}, err
+	}
+	if !owner.IsSuperAdmin(sr.UserId) {
+		return SRollbackOutput{}, helpers.NewError(components.ErrorNotSuperAdmin, fmt.Sprintf("uid=%d", sr.UserId))
+	}
+	target, err := loadSnapshotContent(ctx, sr.ProductId, sr.AppId, sr.SnapshotId)
+	if err != nil {
+		return SRollbackOutput{}, err
+	}
+	backupId, err := sr.restore(ctx, target)
+	if err != nil {
+		return SRollbackOutput{}, err
+	}
+	return SRollbackOutput{BackupSnapshotId: backupId}, nil
+}
+
+// restore 在一个事务中锁定产线当前数据并备份，再整体恢复为target，提交后重新加载校验规则
+func (sr *SRollbackInput) restore(ctx *gin.Context, target Content) (backupId int64, txFlowErr error) {
+	// 开始事务
+	var tx = helpers.MysqlClientPermission.Begin()
+	if err := tx.Error; err != nil {
+		zlog.Warnf(ctx, "DB错误 开启事务失败", err)
+		return 0, helpers.NewError(components.ErrorDbError, err.Error())
+	}
+	defer func() {
+		if txFlowErr != nil {
+			// 回滚事务
+			if _err := tx.Rollback().Error; _err != nil {
+				zlog.Warnf(ctx, "DB错误 事务回滚失败", _err)
+			}
+			return
+		}
+		// 提交事务
+		if _err := tx.Commit().Error; _err != nil {
+			zlog.Warnf(ctx, "DB错误 事务提交失败", _err)
+			txFlowErr = _err
+			return
+		}
+		if _err := helpers.ReloadPolicy(); _err != nil {
+			zlog.Warnf(ctx, "casbin reload policy failure", _err)
+		}
+	}()
+	domain := domainOf(sr.ProductId, sr.AppId)
+	domainCondition := map[string]interface{}{
+		"product_id": sr.ProductId,
+		"app_id":     sr.AppId,
+	}
+	// 备份与恢复在同一事务中，备份读到的记录在提交前不会被其他事务修改
+	current, err := lockContent(ctx, sr.ProductId, sr.AppId, tx)
+	if err != nil {
+		return 0, err
+	}
+	backupId, err = saveSnapshot(ctx, current, sr.ProductId, sr.AppId, sr.UserId, components.SNAPSHOT_TRIGGER_ROLLBACK,
+		fmt.Sprintf("rollback to snapshot %d", sr.SnapshotId), tx)
+	if err != nil {
+		return 0, err
+	}
+	// 1.权限组：快照中存在的按原值覆盖，快照中不存在的置为删除
+	group := &m.Group{}
+	currentGroups := current.Groups
+	keepGroups := make(map[int64]struct{}, len(target.Groups))
+	groupIds := make([]int64, 0, len(currentGroups)+len(target.Groups))
+	for _, g := range target.Groups {
+		keepGroups[g.ID] = struct{}{}
+		groupIds = append(groupIds, g.ID)
+	}
+	for _, g := range currentGroups {
+		groupIds = append(groupIds, g.ID)
+		if _, ok := keepGroups[g.ID]; ok || g.Status == components.GROUP_STATUS_DELETED {
+			continue
+		}
+		updatedFields := map[string]interface{}{
+			"status":      components.GROUP_STATUS_DELETED,
+			"update_uid":  sr.UserId,
+			"update_time": time.Now().Unix(),
+			"version":     gorm.Expr("`version` + 1"),
+		}
+		if _, err := group.UpdateGroupById(ctx, g.ID, updatedFields, tx); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := group.BatchUpsertGroup(ctx, target.Groups, tx); err != nil {
+		return 0, err
+	}
+	// 2.node/menu映射关系：清空后按快照重建
+	groupNode := &m.GroupNode{}
+	if _, err := groupNode.DeleteGroupNodeByGroupIds(ctx, groupIds, tx); err != nil {
+		return 0, err
+	}
+	if _, err := groupNode.BatchInsertGroupNode(ctx, resetGroupNodeIds(target.GroupNodes), tx); err != nil {
+		return 0, err
+	}
+	// 3.校验规则：清空产线下规则后按快照重建
+	casbinRule := &m.CasbinRule{}
+	if _, err := casbinRule.DeleteCasbinRulesByDomain(ctx, domain, tx); err != nil {
+		return 0, err
+	}
+	if _, err := casbinRule.BatchInsertCasbinRule(ctx, resetRuleIds(target.Rules), tx); err != nil {
+		return 0, err
+	}
+	// 4.组员：逐个分表清空产线下组员后按快照重建
+	membersByShard := make(map[int64][]m.UserGroup)
+	for _, ug := range target.Members {
+		shard := ug.UserId % components.USER_GROUP_SHARD_NUM
+		membersByShard[shard] = append(membersByShard[shard], ug)
+	}
+	userGroup := &m.UserGroup{}
+	for shard := int64(0); shard < components.USER_GROUP_SHARD_NUM; shard++ {
+		if _, err := userGroup.DeleteUserGroupByShard(ctx, shard, domainCondition, tx); err != nil {
+			return 0, err
+		}
+		if _, err := userGroup.BatchInsertUserGroupByShard(ctx, shard, membersByShard[shard], tx); err != nil {
+			return 0, err
+		}
+	}
+	audit.Record(ctx, audit.Entry{
+		ProductId:  sr.ProductId,
+		AppId:      sr.AppId,
+		OperateUid: sr.UserId,
+		Action:     audit.ActionSnapshotRollback,
+		TargetType: audit.TargetDomain,
+		TargetId:   sr.SnapshotId,
+		Detail: map[string]interface{}{
+			"snapshotId":       sr.SnapshotId,
+			"backupSnapshotId": backupId,
+		},
+	}, tx)
+	return backupId, nil
+}
+
+// 映射关系和校验规则没有外部引用，重建时使用新的自增id，避免与回滚期间新增的记录冲突
+func resetGroupNodeIds(groupNodes []m.GroupNode) []m.GroupNode {
+	ret := make([]m.GroupNode, 0, len(groupNodes))
+	for _, gn := range groupNodes {
+		gn.ID = 0
+		ret = append(ret, gn)
+	}
+	return ret
+}
+
+func resetRuleIds(rules []m.CasbinRule) []m.CasbinRule {
+	ret := make([]m.CasbinRule, 0, len(rules))
+	for _, r := range rules {
+		r.ID = 0
+		ret = append(ret, r)
+	}
+	return ret
+}
+
+func (sr *SRollbackInput) checkParams() error {
+	if sr.ProductId <= 0 {
+		return helpers.NewError(components.ErrorSnapshotParamsInvalid, "productId 不合法")
+	}
+	if sr.AppId <= 0 {
+		return helpers.NewError(components.ErrorSnapshotParamsInvalid, "appId 不合法")
+	}
+	if sr.SnapshotId <= 0 {
+		return helpers.NewError(components.ErrorSnapshotParamsInvalid, "snapshotId 不合法")
+	}
+	if sr.UserId <= 0 {
+		return helpers.NewError(components.ErrorSnapshotParamsInvalid, "userId 不合法")
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"time"
+)
+
+// Content 快照内容，覆盖一个产线下的权限组、node/menu映射关系、校验规则和组员
+type Content struct {
+	Groups     []m.Group      `json:"groups"`
+	GroupNodes []m.GroupNode  `json:"groupNodes"`
+	Rules      []m.CasbinRule `json:"rules"`
+	Members    []m.UserGroup  `json:"members"`
+}
+
+func domainOf(productId, appId int64) string {
+	return fmt.Sprintf("%d:%d", productId, appId)
+}
+
+// LoadContent 读取产线当前的完整权限数据
+func LoadContent(ctx *gin.Context, productId, appId int64) (content Content, err error) {
+	group := &m.Group{}
+	groupCondition := map[string]interface{}{
+		"product_id": productId,
+		"app_id":     appId,
+	}
+	if content.Groups, err = group.GetGroupListByConds(ctx, groupCondition); err != nil {
+		return content, err
+	}
+	groupIds := make([]int64, 0, len(content.Groups))
+	for _, g := range content.Groups {
+		groupIds = append(groupIds, g.ID)
+	}
+	if len(groupIds) > 0 {
+		groupNode := &m.GroupNode{}
+		if content.GroupNodes, err = groupNode.GetGroupNodeListByConds(ctx, map[string]interface{}{"group_id": groupIds}); err != nil {
+			return content, err
+		}
+	}
+	rule := &m.CasbinRule{}
+	if content.Rules, err = rule.GetCasbinRulesListByConds(ctx, map[string]interface{}{"v1": domainOf(productId, appId)}); err != nil {
+		return content, err
+	}
+	userGroup := &m.UserGroup{}
+	for shard := int64(0); shard < components.USER_GROUP_SHARD_NUM; shard++ {
+		members, err := userGroup.GetUserGroupListByShard(ctx, shard, groupCondition, nil)
+		if err != nil {
+			return content, err
+		}
+		content.Members = append(content.Members, members...)
+	}
+	return content, nil
+}
+
+// lockContent 在事务中加写锁读取产线当前的完整权限数据，提交前其他事务无法修改读到的记录
+func lockContent(ctx *gin.Context, productId, appId int64, tx *gorm.DB) (content Content, err error) {
+	group := &m.Group{}
+	groupCondition := map[string]interface{}{
+		"product_id": productId,
+		"app_id":     appId,
+	}
+	if content.Groups, err = group.GetGroupListForUpdate(ctx, groupCondition, tx); err != nil {
+		return content, err
+	}
+	groupIds := make([]int64, 0, len(content.Groups))
+	for _, g := range content.Groups {
+		groupIds = append(groupIds, g.ID)
+	}
+	if len(groupIds) > 0 {
+		groupNode := &m.GroupNode{}
+		if content.GroupNodes, err = groupNode.GetGroupNodeListForUpdate(ctx, map[string]interface{}{"group_id": groupIds}, tx); err != nil {
+			return content, err
+		}
+	}
+	rule := &m.CasbinRule{}
+	if content.Rules, err = rule.GetCasbinRulesListForUpdate(ctx, map[string]interface{}{"v1": domainOf(productId, appId)}, tx); err != nil {
+		return content, err
+	}
+	userGroup := &m.UserGroup{}
+	for shard := int64(0); shard < components.USER_GROUP_SHARD_NUM; shard++ {
+		members, err := userGroup.GetUserGroupListByShardForUpdate(ctx, shard, groupCondition, tx)
+		if err != nil {
+			return content, err
+		}
+		content.Members = append(content.Members, members...)
+	}
+	return content, nil
+}
+
+// TakeSnapshot 为产线打快照并返回快照id，db不为空时快照记录随该事务一起提交
+func TakeSnapshot(ctx *gin.Context, productId, appId, uid int64, trigger int8, reason string, db *gorm.DB) (int64, error) {
+	content, err := LoadContent(ctx, productId, appId)
+	if err != nil {
+		return 0, err
+	}
+	return saveSnapshot(ctx, content, productId, appId, uid, trigger, reason, db)
+}
+
+func saveSnapshot(ctx *gin.Context, content Content, productId, appId, uid int64, trigger int8, reason string, db *gorm.DB) (int64, error) {
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return 0, helpers.NewError(components.ErrorSystemError, err.Error())
+	}
+	snapshot := &m.Snapshot{
+		ProductId:  productId,
+		AppId:      appId,
+		Trigger:    trigger,
+		Reason:     reason,
+		Content:    string(raw),
+		CreateUid:  uid,
+		CreateTime: time.Now().Unix(),
+	}
+	if err := snapshot.InsertSnapshot(ctx, db); err != nil {
+		return 0, err
+	}
+	return snapshot.ID, nil
+}
+
+// loadSnapshotContent id为0时表示产线当前的线上数据
+func loadSnapshotContent(ctx *gin.Context, productId, appId, id int64) (Content, error) {
+	if id == 0 {
+		return LoadContent(ctx, productId, appId)
+	}
+	var content Content
+	snapshot := &m.Snapshot{}
+	info, err := snapshot.GetSnapshotById(ctx, id)
+	if err != nil {
+		return content, err
+	}
+	if info.ID <= 0 || info.ProductId != productId || info.AppId != appId {
+		return content, helpers.NewError(components.ErrorSnapshotNotExist, fmt.Sprintf("id=%d", id))
+	}
+	if err := json.Unmarshal([]byte(info.Content), &content); err != nil {
+		return content, helpers.NewError(components.ErrorSystemError, err.Error())
+	}
+	return content, nil
+}
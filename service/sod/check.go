@@ -0,0 +1,94 @@
+package sod
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+)
+
+// CheckAssignment 校验把用户加入权限组是否与其已有的组员资格互斥
+func CheckAssignment(ctx *gin.Context, productId, appId, userId, groupId int64) error {
+	return CheckMove(ctx, productId, appId, userId, 0, groupId)
+}
+
+// CheckMove 校验把用户从 fromGroupId 调整到 groupId 后是否与其其余组员资格互斥，fromGroupId 为0表示新增
+func CheckMove(ctx *gin.Context, productId, appId, userId, fromGroupId, groupId int64) error {
+	return CheckReplace(ctx, productId, appId, userId, []int64{fromGroupId}, groupId)
+}
+
+// CheckReplace 校验用户的 fromGroupIds 组员资格被 groupId 替换后是否与其其余组员资格互斥
+func CheckReplace(ctx *gin.Context, productId, appId, userId int64, fromGroupIds []int64, groupId int64) error {
+	exclusion := &m.GroupExclusion{}
+	exclusions, err := exclusion.GetGroupExclusionListByGroupId(ctx, groupId, nil)
+	if err != nil {
+		return helpers.NewError(components.ErrorDbSelect, "get group exclusion failure")
+	}
+	if len(exclusions) == 0 {
+		return nil
+	}
+	userGroup := &m.UserGroup{UserId: userId}
+	userGroups, err := userGroup.GetValidUserGroupListByCondition(ctx, map[string]interface{}{
+		"product_id": productId,
+		"app_id":     appId,
+		"user_id":    userId,
+	})
+	if err != nil {
+		return helpers.NewError(components.ErrorDbSelect, "get userGroup by condition error")
+	}
+	from := make(map[int64]struct{}, len(fromGroupIds))
+	for _, id := range fromGroupIds {
+		from[id] = struct{}{}
+	}
+	var heldGroupIds []int64
+	for _, id := range m.UserGroupIds(userGroups) {
+		if _, ok := from[id]; !ok {
+			heldGroupIds = append(heldGroupIds, id)
+		}
+	}
+	return violation(userId, groupId, heldGroupIds, exclusions)
+}
+
+// CheckGroups 校验用户加入 groupId 是否与给定的 heldGroupIds 互斥，用于批量写入前校验同批次内的组员资格
+func CheckGroups(ctx *gin.Context, userId, groupId int64, heldGroupIds []int64) error {
+	if len(heldGroupIds) == 0 {
+		return nil
+	}
+	exclusion := &m.GroupExclusion{}
+	exclusions, err := exclusion.GetGroupExclusionListByGroupId(ctx, groupId, nil)
+	if err != nil {
+		return helpers.NewError(components.ErrorDbSelect, "get group exclusion failure")
+	}
+	return violation(userId, groupId, heldGroupIds, exclusions)
+}
+
+func violation(userId, groupId int64, heldGroupIds []int64, exclusions []m.GroupExclusion) error {
+	if e, ok := conflict(groupId, heldGroupIds, exclusions); ok {
+		return helpers.NewError(components.ErrorSodViolation,
+			fmt.Sprintf("user %d: group %d conflicts with group %d (exclusion %d)", userId, e.GroupIdA, e.GroupIdB, e.ID))
+	}
+	return nil
+}
+
+// conflict 返回 groupId 与 heldGroupIds 之间命中的第一条互斥约束
+func conflict(groupId int64, heldGroupIds []int64, exclusions []m.GroupExclusion) (m.GroupExclusion, bool) {
+	held := make(map[int64]struct{}, len(heldGroupIds))
+	for _, id := range heldGroupIds {
+		if id != groupId {
+			held[id] = struct{}{}
+		}
+	}
+	for _, e := range exclusions {
+		other := e.GroupIdA
+		if other == groupId {
+			other = e.GroupIdB
+		} else if e.GroupIdB != groupId {
+			continue
+		}
+		if _, ok := held[other]; ok {
+			return e, true
+		}
+	}
+	return m.GroupExclusion{}, false
+}
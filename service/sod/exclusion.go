@@ -0,0 +1,100 @@
+package sod
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"time"
+)
+
+type SCreateInput struct {
+	ProductId int64
+	AppId     int64
+	GroupIdA  int64
+	GroupIdB  int64
+	Reason    string
+	UserId    int64
+}
+
+// CreateExclusion 新增互斥约束，已存在的违规组员资格不受影响，可通过 sodreport 任务查出
+func (sc *SCreateInput) CreateExclusion(ctx *gin.Context) (int64, error) {
+	if sc.ProductId <= 0 || sc.AppId <= 0 {
+		return 0, helpers.NewError(components.ErrorSodParamsInvalid, "productId/appId 不合法")
+	}
+	if sc.GroupIdA <= 0 || sc.GroupIdB <= 0 || sc.GroupIdA == sc.GroupIdB {
+		return 0, helpers.NewError(components.ErrorSodParamsInvalid, "groupIdA/groupIdB 不合法")
+	}
+	if sc.GroupIdA > sc.GroupIdB {
+		sc.GroupIdA, sc.GroupIdB = sc.GroupIdB, sc.GroupIdA
+	}
+	group := &m.Group{}
+	for _, groupId := range []int64{sc.GroupIdA, sc.GroupIdB} {
+		groupInfo, err := group.GetGroupById(ctx, groupId)
+		if err != nil {
+			return 0, helpers.NewError(components.ErrorDbSelect, "get group by id failure")
+		}
+		if groupInfo.ID <= 0 || groupInfo.ProductID != sc.ProductId || groupInfo.AppID != sc.AppId {
+			return 0, helpers.NewError(components.ErrorSodParamsInvalid, fmt.Sprintf("group %d 不属于该产线", groupId))
+		}
+	}
+	exclusion := &m.GroupExclusion{
+		ProductId:  sc.ProductId,
+		AppId:      sc.AppId,
+		GroupIdA:   sc.GroupIdA,
+		GroupIdB:   sc.GroupIdB,
+		Reason:     sc.Reason,
+		CreateUid:  sc.UserId,
+		CreateTime: time.Now().Unix(),
+	}
+	existed, err := exclusion.GetGroupExclusionListByConds(ctx, map[string]interface{}{
+		"group_id_a": sc.GroupIdA,
+		"group_id_b": sc.GroupIdB,
+	})
+	if err != nil {
+		return 0, helpers.NewError(components.ErrorDbSelect, "get group exclusion failure")
+	}
+	if len(existed) > 0 {
+		return existed[0].ID, nil
+	}
+	if err := exclusion.InsertGroupExclusion(ctx); err != nil {
+		return 0, helpers.NewError(components.ErrorDbInsert, "insert group exclusion failure")
+	}
+	return exclusion.ID, nil
+}
+
+type SDeleteInput struct {
+	Id int64
+}
+
+func (sd *SDeleteInput) DeleteExclusion(ctx *gin.Context) (bool, error) {
+	if sd.Id <= 0 {
+		return false, helpers.NewError(components.ErrorSodParamsInvalid, "id 不合法")
+	}
+	exclusion := &m.GroupExclusion{}
+	if _, err := exclusion.DeleteGroupExclusionById(ctx, sd.Id); err != nil {
+		return false, helpers.NewError(components.ErrorDbDelete, "delete group exclusion failure")
+	}
+	return true, nil
+}
+
+type SListInput struct {
+	ProductId int64
+	AppId     int64
+}
+
+func (sl *SListInput) GetExclusionList(ctx *gin.Context) ([]m.GroupExclusion, error) {
+	if sl.ProductId <= 0 || sl.AppId <= 0 {
+		return nil, helpers.NewError(components.ErrorSodParamsInvalid, "productId/appId 不合法")
+	}
+	exclusion := &m.GroupExclusion{}
+	list, err := exclusion.GetGroupExclusionListByConds(ctx, map[string]interface{}{
+		"product_id": sl.ProductId,
+		"app_id":     sl.AppId,
+	})
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get group exclusion failure")
+	}
+	return list, nil
+}
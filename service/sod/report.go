@@ -0,0 +1,95 @@
+package sod
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	m "permission/models"
+	"time"
+)
+
+// Violation 同时属于两个互斥权限组的用户
+type Violation struct {
+	ExclusionId int64 `json:"exclusionId"`
+	ProductId   int64 `json:"productId"`
+	AppId       int64 `json:"appId"`
+	UserId      int64 `json:"userId"`
+	GroupIdA    int64 `json:"groupIdA"`
+	GroupIdB    int64 `json:"groupIdB"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("exclusion=%d domain=%d:%d user=%d groups=%d,%d", v.ExclusionId, v.ProductId, v.AppId, v.UserId, v.GroupIdA, v.GroupIdB)
+}
+
+type memberKey struct {
+	productId int64
+	appId     int64
+	userId    int64
+}
+
+// Report 遍历全部用户权限组分表，找出违反互斥约束的有效组员资格
+func Report(ctx *gin.Context) ([]Violation, error) {
+	exclusion := &m.GroupExclusion{}
+	exclusions, err := exclusion.GetGroupExclusionListByConds(ctx, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	if len(exclusions) == 0 {
+		return nil, nil
+	}
+	groupIdSet := make(map[int64]struct{})
+	for _, e := range exclusions {
+		groupIdSet[e.GroupIdA] = struct{}{}
+		groupIdSet[e.GroupIdB] = struct{}{}
+	}
+	groupIds := make([]int64, 0, len(groupIdSet))
+	for id := range groupIdSet {
+		groupIds = append(groupIds, id)
+	}
+
+	var violations []Violation
+	userGroup := &m.UserGroup{}
+	now := time.Now().Unix()
+	for shard := int64(0); shard < components.USER_GROUP_SHARD_NUM; shard++ {
+		userGroups, err := userGroup.GetUserGroupListByShard(ctx, shard, map[string]interface{}{
+			"group_id": groupIds,
+			"status":   components.GROUP_STATUS_ACTIVE,
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		// 同一用户在同一产线下持有的权限组，user_id 取模分表，同一用户只会出现在一个分表中
+		held := make(map[memberKey]map[int64]struct{})
+		for _, ug := range userGroups {
+			if ug.ExpireTime > 0 && ug.ExpireTime <= now {
+				continue
+			}
+			key := memberKey{productId: ug.ProductId, appId: ug.AppId, userId: ug.UserId}
+			if held[key] == nil {
+				held[key] = make(map[int64]struct{})
+			}
+			held[key][ug.GroupId] = struct{}{}
+		}
+		for key, groups := range held {
+			for _, e := range exclusions {
+				if e.ProductId != key.productId || e.AppId != key.appId {
+					continue
+				}
+				_, okA := groups[e.GroupIdA]
+				_, okB := groups[e.GroupIdB]
+				if okA && okB {
+					violations = append(violations, Violation{
+						ExclusionId: e.ID,
+						ProductId:   key.productId,
+						AppId:       key.appId,
+						UserId:      key.userId,
+						GroupIdA:    e.GroupIdA,
+						GroupIdB:    e.GroupIdB,
+					})
+				}
+			}
+		}
+	}
+	return violations, nil
+}
@@ -0,0 +1,182 @@
+package usage
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"strconv"
+	"time"
+)
+
+// 未使用报告最多返回的条数
+const maxReportItems = 5000
+
+// UReportInput GroupId 为0时统计产线下全部权限组
+type UReportInput struct {
+	ProductId int64
+	AppId     int64
+	GroupId   int64
+	Days      int
+}
+
+// UnusedGrant 统计期内没有作为放行依据的授权，可能从未被请求，也可能被用户的其他授权覆盖(冗余)。
+// LastUsedTime 为0表示开始统计以来从未作为放行依据
+type UnusedGrant struct {
+	Subject      string `json:"subject"`
+	Resource     string `json:"resource"`
+	HitCount     int64  `json:"hitCount"`
+	LastUsedTime int64  `json:"lastUsedTime"`
+}
+
+// UnusedMember 与 UnusedGrant 相同，组员资格可能从未使用，也可能被用户所在的其他权限组覆盖
+type UnusedMember struct {
+	UserId       int64 `json:"userId"`
+	UserType     int8  `json:"userType"`
+	GroupId      int64 `json:"groupId"`
+	CreateTime   int64 `json:"createTime"`
+	HitCount     int64 `json:"hitCount"`
+	LastUsedTime int64 `json:"lastUsedTime"`
+}
+
+type UReportOutput struct {
+	Cutoff    int64          `json:"cutoff"`
+	Truncated bool           `json:"truncated"`
+	Grants    []UnusedGrant  `json:"grants"`
+	Members   []UnusedMember `json:"members"`
+}
+
+// GetUnusedReport 列出最近 Days 天内没有作为放行依据的授权规则和组员资格，即未使用或冗余的授权；Days 天内新加入的组员资格不列出。
+// 鉴权时首个放行的权限组即决定结果，用户所在的其余权限组和直接授权不再校验，因此只被覆盖的授权也会列出，收回后不影响统计期内的访问
+func (ur *UReportInput) GetUnusedReport(ctx *gin.Context) (out UReportOutput, err error) {
+	if ur.ProductId <= 0 || ur.AppId <= 0 {
+		return out, helpers.NewError(components.ErrorUsageParamsInvalid, "productId/appId 不合法")
+	}
+	if ur.Days <= 0 {
+		return out, helpers.NewError(components.ErrorUsageParamsInvalid, "days 不合法")
+	}
+	out.Cutoff = time.Now().AddDate(0, 0, -ur.Days).Unix()
+	out.Grants, err = ur.unusedGrants(ctx, out.Cutoff)
+	if err != nil {
+		return out, err
+	}
+	out.Members, err = ur.unusedMembers(ctx, out.Cutoff)
+	if err != nil {
+		return out, err
+	}
+	if len(out.Grants) > maxReportItems {
+		out.Grants, out.Truncated = out.Grants[:maxReportItems], true
+	}
+	if len(out.Members) > maxReportItems {
+		out.Members, out.Truncated = out.Members[:maxReportItems], true
+	}
+	return out, nil
+}
+
+// unusedGrants 只统计放行规则，拒绝规则不视为授权
+func (ur *UReportInput) unusedGrants(ctx *gin.Context, cutoff int64) ([]UnusedGrant, error) {
+	condition := map[string]interface{}{
+		"ptype": components.CASBIN_RULE_PTYPE,
+		"v1":    fmt.Sprintf("%d:%d", ur.ProductId, ur.AppId),
+		"v4":    components.POLICY_STATUS_ALLOW,
+	}
+	if ur.GroupId > 0 {
+		condition["v0"] = strconv.FormatInt(ur.GroupId, 10)
+	}
+	casbinRule := &m.CasbinRule{}
+	rules, err := casbinRule.GetCasbinRulesListByConds(ctx, condition)
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get casbin rule list failure")
+	}
+	usageCondition := map[string]interface{}{
+		"product_id": ur.ProductId,
+		"app_id":     ur.AppId,
+	}
+	if ur.GroupId > 0 {
+		usageCondition["subject"] = strconv.FormatInt(ur.GroupId, 10)
+	}
+	grantUsage := &m.GrantUsage{}
+	usages, err := grantUsage.GetGrantUsageListByConds(ctx, usageCondition)
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get grant usage list failure")
+	}
+	usageMap := make(map[[2]string]m.GrantUsage, len(usages))
+	for _, u := range usages {
+		usageMap[[2]string{u.Subject, u.Resource}] = u
+	}
+	grants := make([]UnusedGrant, 0)
+	seen := make(map[[2]string]bool, len(rules))
+	for _, r := range rules {
+		key := [2]string{r.GroupId, r.Resource}
+		// 同一资源按不同条件授予的多条规则合并统计
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		u := usageMap[key]
+		if u.LastUsedTime >= cutoff {
+			continue
+		}
+		grants = append(grants, UnusedGrant{
+			Subject:      r.GroupId,
+			Resource:     r.Resource,
+			HitCount:     u.HitCount,
+			LastUsedTime: u.LastUsedTime,
+		})
+	}
+	return grants, nil
+}
+
+func (ur *UReportInput) unusedMembers(ctx *gin.Context, cutoff int64) ([]UnusedMember, error) {
+	usageCondition := map[string]interface{}{
+		"product_id": ur.ProductId,
+		"app_id":     ur.AppId,
+	}
+	condition := map[string]interface{}{
+		"product_id": ur.ProductId,
+		"app_id":     ur.AppId,
+		"status":     components.GROUP_STATUS_ACTIVE,
+	}
+	if ur.GroupId > 0 {
+		usageCondition["group_id"] = ur.GroupId
+		condition["group_id"] = ur.GroupId
+	}
+	memberUsage := &m.MemberUsage{}
+	usages, err := memberUsage.GetMemberUsageListByConds(ctx, usageCondition)
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get member usage list failure")
+	}
+	usageMap := make(map[[2]int64]m.MemberUsage, len(usages))
+	for _, u := range usages {
+		usageMap[[2]int64{u.UserId, u.GroupId}] = u
+	}
+	now := time.Now().Unix()
+	members := make([]UnusedMember, 0)
+	userGroup := &m.UserGroup{}
+	for shard := int64(0); shard < components.USER_GROUP_SHARD_NUM; shard++ {
+		userGroups, err := userGroup.GetUserGroupListByShard(ctx, shard, condition, nil)
+		if err != nil {
+			return nil, helpers.NewError(components.ErrorDbSelect, "get userGroup list failure")
+		}
+		for _, ug := range userGroups {
+			// Days 天内新加入的和已到期的组员资格不列出
+			if ug.CreateTime > cutoff || (ug.ExpireTime > 0 && ug.ExpireTime <= now) {
+				continue
+			}
+			u := usageMap[[2]int64{ug.UserId, ug.GroupId}]
+			if u.LastUsedTime >= cutoff {
+				continue
+			}
+			members = append(members, UnusedMember{
+				UserId:       ug.UserId,
+				UserType:     ug.UserType,
+				GroupId:      ug.GroupId,
+				CreateTime:   ug.CreateTime,
+				HitCount:     u.HitCount,
+				LastUsedTime: u.LastUsedTime,
+			})
+		}
+	}
+	return members, nil
+}
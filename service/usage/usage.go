@@ -0,0 +1,164 @@
+package usage
+
+import (
+	"github.com/gin-gonic/gin"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"sync"
+)
+
+// 鉴权路径只在内存中累加放行次数，由周期任务批量写库；实例退出时尚未写库的计数会丢失
+
+// 单条 insert 写入的记录数
+const flushBatchSize = 500
+
+type grantKey struct {
+	productId int64
+	appId     int64
+	subject   string
+	resource  string
+}
+
+type memberKey struct {
+	productId int64
+	appId     int64
+	userId    int64
+	groupId   int64
+}
+
+type hit struct {
+	count    int64
+	lastUsed int64
+}
+
+func (h *hit) add(count, lastUsed int64) {
+	h.count += count
+	if lastUsed > h.lastUsed {
+		h.lastUsed = lastUsed
+	}
+}
+
+var buffer = struct {
+	sync.Mutex
+	grants  map[grantKey]*hit
+	members map[memberKey]*hit
+}{
+	grants:  make(map[grantKey]*hit),
+	members: make(map[memberKey]*hit),
+}
+
+// RecordGrant 记录一次由 subject(权限组id或user:<用户id>)对 resource 的规则放行的鉴权
+func RecordGrant(productId, appId int64, subject, resource string, now int64) {
+	key := grantKey{productId: productId, appId: appId, subject: subject, resource: resource}
+	buffer.Lock()
+	h, ok := buffer.grants[key]
+	if !ok {
+		h = &hit{}
+		buffer.grants[key] = h
+	}
+	h.add(1, now)
+	buffer.Unlock()
+}
+
+// RecordMember 记录一次用户经 groupId 的组员资格被放行的鉴权
+func RecordMember(productId, appId, userId, groupId int64, now int64) {
+	key := memberKey{productId: productId, appId: appId, userId: userId, groupId: groupId}
+	buffer.Lock()
+	h, ok := buffer.members[key]
+	if !ok {
+		h = &hit{}
+		buffer.members[key] = h
+	}
+	h.add(1, now)
+	buffer.Unlock()
+}
+
+// Flush 将内存中累计的使用情况写库，写入失败的部分放回内存等下次写入
+func Flush(ctx *gin.Context) error {
+	buffer.Lock()
+	grants, members := buffer.grants, buffer.members
+	buffer.grants, buffer.members = make(map[grantKey]*hit), make(map[memberKey]*hit)
+	buffer.Unlock()
+
+	grantUsages := make([]m.GrantUsage, 0, len(grants))
+	for k, h := range grants {
+		grantUsages = append(grantUsages, m.GrantUsage{
+			ProductId:    k.productId,
+			AppId:        k.appId,
+			Subject:      k.subject,
+			Resource:     k.resource,
+			HitCount:     h.count,
+			LastUsedTime: h.lastUsed,
+		})
+	}
+	memberUsages := make([]m.MemberUsage, 0, len(members))
+	for k, h := range members {
+		memberUsages = append(memberUsages, m.MemberUsage{
+			ProductId:    k.productId,
+			AppId:        k.appId,
+			UserId:       k.userId,
+			GroupId:      k.groupId,
+			HitCount:     h.count,
+			LastUsedTime: h.lastUsed,
+		})
+	}
+
+	var flushErr error
+	grantUsage := &m.GrantUsage{}
+	for i := 0; i < len(grantUsages); i += flushBatchSize {
+		end := i + flushBatchSize
+		if end > len(grantUsages) {
+			end = len(grantUsages)
+		}
+		batch := grantUsages[i:end]
+		if _, err := grantUsage.BatchUpsertGrantUsage(ctx, batch, nil); err != nil {
+			zlog.Warnf(ctx, "flush grant usage fail, err:%v", err)
+			restoreGrants(grantUsages[i:])
+			flushErr = err
+			break
+		}
+	}
+	memberUsage := &m.MemberUsage{}
+	for i := 0; i < len(memberUsages); i += flushBatchSize {
+		end := i + flushBatchSize
+		if end > len(memberUsages) {
+			end = len(memberUsages)
+		}
+		batch := memberUsages[i:end]
+		if _, err := memberUsage.BatchUpsertMemberUsage(ctx, batch, nil); err != nil {
+			zlog.Warnf(ctx, "flush member usage fail, err:%v", err)
+			restoreMembers(memberUsages[i:])
+			flushErr = err
+			break
+		}
+	}
+	return flushErr
+}
+
+func restoreGrants(usages []m.GrantUsage) {
+	buffer.Lock()
+	defer buffer.Unlock()
+	for _, u := range usages {
+		key := grantKey{productId: u.ProductId, appId: u.AppId, subject: u.Subject, resource: u.Resource}
+		h, ok := buffer.grants[key]
+		if !ok {
+			h = &hit{}
+			buffer.grants[key] = h
+		}
+		h.add(u.HitCount, u.LastUsedTime)
+	}
+}
+
+func restoreMembers(usages []m.MemberUsage) {
+	buffer.Lock()
+	defer buffer.Unlock()
+	for _, u := range usages {
+		key := memberKey{productId: u.ProductId, appId: u.AppId, userId: u.UserId, groupId: u.GroupId}
+		h, ok := buffer.members[key]
+		if !ok {
+			h = &hit{}
+			buffer.members[key] = h
+		}
+		h.add(u.HitCount, u.LastUsedTime)
+	}
+}
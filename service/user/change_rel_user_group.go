@@ -0,0 +1,137 @@
+package user
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/audit"
+	"permission/service/owner"
+	"permission/service/sod"
+	"time"
+)
+
+type RChangeInput struct {
+	ProductId   int64
+	AppId       int64
+	UserType    int8
+	UserId      int64
+	FromGroupId int64
+	ToGroupId   int64
+	OperateUid  int64
+}
+
+// ChangeUserGroup 将用户从一个权限组调整到同产线下的另一个权限组，保留原组员资格的状态和到期时间
+func (rc *RChangeInput) ChangeUserGroup(ctx *gin.Context) (bool, error) {
+	if err := rc.checkParams(); err != nil {
+		return false, err
+	}
+	// 调整需同时具备两个权限组的管理权限
+	for _, groupId := range []int64{rc.FromGroupId, rc.ToGroupId} {
+		if _, err := owner.Authorize(ctx, groupId, rc.OperateUid); err != nil {
+			return false, err
+		}
+	}
+	group := &m.Group{}
+	target, err := group.GetGroupById(ctx, rc.ToGroupId)
+	if err != nil {
+		return false, helpers.NewError(components.ErrorDbSelect, "get group by id failure")
+	}
+	if target.ID <= 0 || target.Status == components.GROUP_STATUS_DELETED || target.ProductID != rc.ProductId || target.AppID != rc.AppId {
+		return false, helpers.NewError(components.ErrorGroupParamsInvalid, "toGroupId 不存在或不属于该产线")
+	}
+	userGroup := &m.UserGroup{UserId: rc.UserId}
+	condition := map[string]interface{}{
+		"product_id": rc.ProductId,
+		"app_id":     rc.AppId,
+		"user_type":  rc.UserType,
+		"user_id":    rc.UserId,
+		"group_id":   rc.FromGroupId,
+	}
+	from, err := userGroup.GetUserGroupByCondition(ctx, condition)
+	if err != nil {
+		return false, helpers.NewError(components.ErrorDbSelect, "get userGroup by condition failure")
+	}
+	if from.ID <= 0 {
+		return false, helpers.NewError(components.ErrorUserGroupNotExist, fmt.Sprintf("userId=%d groupId=%d", rc.UserId, rc.FromGroupId))
+	}
+	if from.Status == components.GROUP_STATUS_ACTIVE {
+		if err := sod.CheckMove(ctx, rc.ProductId, rc.AppId, rc.UserId, rc.FromGroupId, rc.ToGroupId); err != nil {
+			return false, err
+		}
+	}
+	return rc.move(ctx, userGroup, from)
+}
+
+func (rc *RChangeInput) move(ctx *gin.Context, userGroup *m.UserGroup, from m.UserGroup) (ok bool, err error) {
+	var txFlowErr error
+	tx := helpers.MysqlClientPermission.Begin()
+	if err := tx.Error; err != nil {
+		zlog.Warnf(ctx, "DB错误 开启事务失败", err)
+		return false, helpers.NewError(components.ErrorDbError, err.Error())
+	}
+	defer func() {
+		if txFlowErr != nil {
+			if _err := tx.Rollback().Error; _err != nil {
+				zlog.Warnf(ctx, "DB错误 事务回滚失败", _err)
+			}
+			return
+		}
+		if _err := tx.Commit().Error; _err != nil {
+			zlog.Warnf(ctx, "DB错误 事务提交失败", _err)
+			ok, err = false, _err
+		}
+	}()
+	shard := rc.UserId % components.USER_GROUP_SHARD_NUM
+	// 用户已在目标组中时以原组员资格为准，删除目标组中的旧记录
+	_, txFlowErr = userGroup.DeleteUserGroupByShard(ctx, shard, map[string]interface{}{
+		"product_id": rc.ProductId,
+		"app_id":     rc.AppId,
+		"user_type":  rc.UserType,
+		"user_id":    rc.UserId,
+		"group_id":   rc.ToGroupId,
+	}, tx)
+	if txFlowErr != nil {
+		zlog.Errorf(ctx, "delete target userGroup fail, err:%v", txFlowErr)
+		return false, txFlowErr
+	}
+	_, txFlowErr = userGroup.UpdateUserGroupByShard(ctx, shard, from.ID, map[string]interface{}{
+		"group_id":    rc.ToGroupId,
+		"update_uid":  rc.OperateUid,
+		"update_time": time.Now().Unix(),
+	}, tx)
+	if txFlowErr != nil {
+		zlog.Errorf(ctx, "move userGroup fail, err:%v", txFlowErr)
+		return false, txFlowErr
+	}
+	audit.Record(ctx, audit.Entry{
+		ProductId:  rc.ProductId,
+		AppId:      rc.AppId,
+		OperateUid: rc.OperateUid,
+		Action:     audit.ActionMemberChange,
+		TargetType: audit.TargetUser,
+		TargetId:   rc.UserId,
+		Detail: map[string]interface{}{
+			"fromGroupId": rc.FromGroupId,
+			"toGroupId":   rc.ToGroupId,
+			"status":      from.Status,
+			"expireTime":  from.ExpireTime,
+		},
+	}, tx)
+	return true, nil
+}
+
+func (rc *RChangeInput) checkParams() error {
+	if rc.ProductId <= 0 || rc.AppId <= 0 {
+		return helpers.NewError(components.ErrorUserGroupParamsInvalid, "productId/appId 不合法")
+	}
+	if rc.UserId <= 0 || rc.OperateUid <= 0 {
+		return helpers.NewError(components.ErrorUserGroupParamsInvalid, "userId/operateUid 不合法")
+	}
+	if rc.FromGroupId <= 0 || rc.ToGroupId <= 0 || rc.FromGroupId == rc.ToGroupId {
+		return helpers.NewError(components.ErrorUserGroupParamsInvalid, "fromGroupId/toGroupId 不合法")
+	}
+	return nil
+}
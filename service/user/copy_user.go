@@ -0,0 +1,399 @@
+package user
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/api"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/audit"
+	"permission/service/owner"
+	"permission/service/sod"
+	"strconv"
+	"time"
+)
+
+// 复制结果
+const (
+	CopyAdd     = "add"
+	CopyExists  = "exists"  // 目标用户已具备，不做变更
+	CopySkipped = "skipped" // 无法复制，原因见 Reason
+)
+
+type RCopyInput struct {
+	SourceUserId int64
+	TargetUserId int64
+	OperateUid   int64
+	Domains      []string // 限定复制的产线(productId:appId)，为空表示全部
+	ExpireTime   int64    // 复制出的授权到期时间，0表示与源用户一致
+	Preview      bool     // 只返回复制计划，不写入
+}
+
+type CopyMembership struct {
+	ProductId  int64  `json:"productId"`
+	AppId      int64  `json:"appId"`
+	GroupId    int64  `json:"groupId"`
+	Status     int8   `json:"status"`
+	ExpireTime int64  `json:"expireTime"`
+	Result     string `json:"result"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+type CopyGrant struct {
+	Domain     string `json:"domain"`
+	Resource   string `json:"resource"`
+	Action     string `json:"action"`
+	Effect     string `json:"effect"`
+	Condition  string `json:"condition,omitempty"`
+	ExpireTime int64  `json:"expireTime"`
+	Result     string `json:"result"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+type RCopyOutput struct {
+	Memberships []CopyMembership `json:"memberships"`
+	Grants      []CopyGrant      `json:"grants"`
+}
+
+// CopyUser 将源用户在各产线下的组员资格和直接授权复制给目标用户，已具备的不做变更
+func (rc *RCopyInput) CopyUser(ctx *gin.Context) (RCopyOutput, error) {
+	if err := rc.checkParams(); err != nil {
+		return RCopyOutput{}, err
+	}
+	if !owner.IsSuperAdmin(rc.OperateUid) {
+		return RCopyOutput{}, helpers.NewError(components.ErrorNotSuperAdmin, fmt.Sprintf("uid=%d", rc.OperateUid))
+	}
+	out := RCopyOutput{Memberships: []CopyMembership{}, Grants: []CopyGrant{}}
+	memberships, err := rc.planMemberships(ctx)
+	if err != nil {
+		return out, err
+	}
+	grants, rules, err := rc.planGrants(ctx)
+	if err != nil {
+		return out, err
+	}
+	for _, p := range memberships {
+		out.Memberships = append(out.Memberships, p.CopyMembership)
+	}
+	out.Grants = grants
+	if rc.Preview {
+		return out, nil
+	}
+	if err := rc.apply(ctx, memberships, rules); err != nil {
+		return RCopyOutput{}, err
+	}
+	return out, nil
+}
+
+// plannedMembership 待写入的组员资格及目标用户类型
+type plannedMembership struct {
+	CopyMembership
+	userType int8
+}
+
+func (rc *RCopyInput) planMemberships(ctx *gin.Context) ([]plannedMembership, error) {
+	userGroup := &m.UserGroup{}
+	sources, err := userGroup.GetUserGroupListByShard(ctx, rc.SourceUserId%components.USER_GROUP_SHARD_NUM,
+		map[string]interface{}{"user_id": rc.SourceUserId}, nil)
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get source userGroup list failure")
+	}
+	targets, err := userGroup.GetUserGroupListByShard(ctx, rc.TargetUserId%components.USER_GROUP_SHARD_NUM,
+		map[string]interface{}{"user_id": rc.TargetUserId}, nil)
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get target userGroup list failure")
+	}
+	held := make(map[[2]int64]struct{}, len(targets))
+	for _, ug := range targets {
+		held[[2]int64{ug.AppId, ug.GroupId}] = struct{}{}
+	}
+	now := time.Now().Unix()
+	userTypes := make(map[int64]int8)
+	var planned []plannedMembership
+	for _, ug := range sources {
+		if !rc.inDomains(ug.ProductId, ug.AppId) || (ug.ExpireTime > 0 && ug.ExpireTime <= now) {
+			continue
+		}
+		p := plannedMembership{CopyMembership: CopyMembership{
+			ProductId:  ug.ProductId,
+			AppId:      ug.AppId,
+			GroupId:    ug.GroupId,
+			Status:     ug.Status,
+			ExpireTime: rc.expireTime(ug.ExpireTime),
+			Result:     CopyAdd,
+		}}
+		if _, ok := held[[2]int64{ug.AppId, ug.GroupId}]; ok {
+			p.Result = CopyExists
+			planned = append(planned, p)
+			continue
+		}
+		// 目标用户的内外网类型以passport为准，与鉴权时的判断一致
+		userType, ok := userTypes[ug.AppId]
+		if !ok {
+			info, err := api.GetUserInfoByUserId(ctx, ug.AppId, rc.TargetUserId)
+			if err != nil {
+				zlog.Warnf(ctx, "copy user passport get userinfo failure, appId=%d err:%v", ug.AppId, err)
+				return nil, helpers.NewError(components.ErrorApiGetUserInfo, err.Error())
+			}
+			if info.UserId > 0 {
+				userType = components.USER_TYPE_OUTER
+			}
+			userTypes[ug.AppId] = userType
+		}
+		p.userType = userType
+		if ug.Status == components.GROUP_STATUS_ACTIVE {
+			if err := sod.CheckAssignment(ctx, ug.ProductId, ug.AppId, rc.TargetUserId, ug.GroupId); err != nil {
+				p.Result, p.Reason = CopySkipped, err.Error()
+			}
+		}
+		planned = append(planned, p)
+	}
+	return planned, nil
+}
+
+// plannedGrant 待写入的直接授权规则及其到期时间
+type plannedGrant struct {
+	rule       m.CasbinRule
+	expireTime int64
+}
+
+// planGrants 返回直接授权的复制计划及待写入的规则
+func (rc *RCopyInput) planGrants(ctx *gin.Context) ([]CopyGrant, []plannedGrant, error) {
+	casbinRule := &m.CasbinRule{}
+	sources, err := casbinRule.GetCasbinRulesListByConds(ctx, map[string]interface{}{
+		"ptype": components.CASBIN_RULE_PTYPE,
+		"v0":    components.CASBIN_SUB_USER_PREFIX + strconv.FormatInt(rc.SourceUserId, 10),
+	})
+	if err != nil {
+		return nil, nil, helpers.NewError(components.ErrorDbSelect, "get source user policy failure")
+	}
+	targetSubject := components.CASBIN_SUB_USER_PREFIX + strconv.FormatInt(rc.TargetUserId, 10)
+	targets, err := casbinRule.GetCasbinRulesListByConds(ctx, map[string]interface{}{
+		"ptype": components.CASBIN_RULE_PTYPE,
+		"v0":    targetSubject,
+	})
+	if err != nil {
+		return nil, nil, helpers.NewError(components.ErrorDbSelect, "get target user policy failure")
+	}
+	held := make(map[[5]string]struct{}, len(targets))
+	for _, r := range targets {
+		held[[5]string{r.ProductAppField, r.Resource, r.PermissionType, r.Status, r.Condition}] = struct{}{}
+	}
+	// 源用户的限时授权记录在已通过的申请单上
+	accessRequest := &m.AccessRequest{}
+	requests, err := accessRequest.GetAccessRequestListByConds(ctx, map[string]interface{}{
+		"user_id":      rc.SourceUserId,
+		"request_type": components.ACCESS_REQUEST_TYPE_RESOURCE,
+		"status":       components.ACCESS_REQUEST_STATUS_APPROVED,
+	})
+	if err != nil {
+		return nil, nil, helpers.NewError(components.ErrorDbSelect, "get source access request failure")
+	}
+	expires := make(map[[2]string]int64, len(requests))
+	for _, r := range requests {
+		key := [2]string{fmt.Sprintf("%d:%d", r.ProductId, r.AppId), r.Resource}
+		if cur, ok := expires[key]; !ok || (cur > 0 && (r.GrantExpireTime == 0 || r.GrantExpireTime > cur)) {
+			expires[key] = r.GrantExpireTime
+		}
+	}
+
+	grants := []CopyGrant{}
+	var rules []plannedGrant
+	for _, r := range sources {
+		var productId, appId int64
+		fmt.Sscanf(r.ProductAppField, "%d:%d", &productId, &appId)
+		if !rc.inDomains(productId, appId) {
+			continue
+		}
+		g := CopyGrant{
+			Domain:     r.ProductAppField,
+			Resource:   r.Resource,
+			Action:     r.PermissionType,
+			Effect:     r.Status,
+			Condition:  r.Condition,
+			ExpireTime: rc.expireTime(expires[[2]string{r.ProductAppField, r.Resource}]),
+			Result:     CopyAdd,
+		}
+		switch {
+		case hasKey(held, [5]string{r.ProductAppField, r.Resource, r.PermissionType, r.Status, r.Condition}):
+			g.Result = CopyExists
+		case g.ExpireTime > 0 && !isPlainGrant(r):
+			// 到期收回只处理申请通过时授予的规则形式
+			g.Result, g.Reason = CopySkipped, "带条件或拒绝的规则不支持限时复制"
+		default:
+			rule := r
+			rule.ID = 0
+			rule.GroupId = targetSubject
+			rules = append(rules, plannedGrant{rule: rule, expireTime: g.ExpireTime})
+		}
+		grants = append(grants, g)
+	}
+	return grants, rules, nil
+}
+
+func hasKey(held map[[5]string]struct{}, key [5]string) bool {
+	_, ok := held[key]
+	return ok
+}
+
+func isPlainGrant(r m.CasbinRule) bool {
+	return r.PermissionType == components.CASBIN_ACT_ANY && r.Status == components.POLICY_STATUS_ALLOW && r.Condition == ""
+}
+
+// expireTime 取源授权与指定到期时间中较早的一个
+func (rc *RCopyInput) expireTime(source int64) int64 {
+	if rc.ExpireTime > 0 && (source == 0 || rc.ExpireTime < source) {
+		return rc.ExpireTime
+	}
+	return source
+}
+
+func (rc *RCopyInput) inDomains(productId, appId int64) bool {
+	if len(rc.Domains) == 0 {
+		return true
+	}
+	domain := fmt.Sprintf("%d:%d", productId, appId)
+	for _, d := range rc.Domains {
+		if d == domain {
+			return true
+		}
+	}
+	return false
+}
+
+func (rc *RCopyInput) apply(ctx *gin.Context, memberships []plannedMembership, grants []plannedGrant) (err error) {
+	now := time.Now().Unix()
+	details := make(map[string]map[string]interface{})
+	detailOf := func(domain string) map[string]interface{} {
+		if details[domain] == nil {
+			details[domain] = map[string]interface{}{"sourceUserId": rc.SourceUserId, "expireTime": rc.ExpireTime}
+		}
+		return details[domain]
+	}
+	var userGroups []m.UserGroup
+	for _, p := range memberships {
+		if p.Result != CopyAdd {
+			continue
+		}
+		userGroups = append(userGroups, m.UserGroup{
+			ProductId:  p.ProductId,
+			AppId:      p.AppId,
+			UserType:   p.userType,
+			UserId:     rc.TargetUserId,
+			GroupId:    p.GroupId,
+			Status:     p.Status,
+			CreateUid:  rc.OperateUid,
+			UpdateUid:  rc.OperateUid,
+			CreateTime: now,
+			UpdateTime: now,
+			ExpireTime: p.ExpireTime,
+		})
+		d := detailOf(fmt.Sprintf("%d:%d", p.ProductId, p.AppId))
+		groupIds, _ := d["groupIds"].([]int64)
+		d["groupIds"] = append(groupIds, p.GroupId)
+	}
+	// 限时的直接授权补一条已通过的申请单，到期后由过期任务收回
+	var rules []m.CasbinRule
+	var requests []m.AccessRequest
+	for _, g := range grants {
+		r := g.rule
+		rules = append(rules, r)
+		var productId, appId int64
+		fmt.Sscanf(r.ProductAppField, "%d:%d", &productId, &appId)
+		d := detailOf(r.ProductAppField)
+		resources, _ := d["resources"].([]string)
+		d["resources"] = append(resources, r.Resource)
+		if g.expireTime == 0 {
+			continue
+		}
+		requests = append(requests, m.AccessRequest{
+			ProductId:       productId,
+			AppId:           appId,
+			UserId:          rc.TargetUserId,
+			RequestType:     components.ACCESS_REQUEST_TYPE_RESOURCE,
+			Resource:        r.Resource,
+			Reason:          fmt.Sprintf("copied from user %d", rc.SourceUserId),
+			Status:          components.ACCESS_REQUEST_STATUS_APPROVED,
+			HandleUid:       rc.OperateUid,
+			HandleTime:      now,
+			GrantExpireTime: g.expireTime,
+			CreateTime:      now,
+			UpdateTime:      now,
+		})
+	}
+	if len(userGroups) == 0 && len(rules) == 0 {
+		return nil
+	}
+
+	var txFlowErr error
+	tx := helpers.MysqlClientPermission.Begin()
+	if err := tx.Error; err != nil {
+		zlog.Warnf(ctx, "DB错误 开启事务失败", err)
+		return helpers.NewError(components.ErrorDbError, err.Error())
+	}
+	defer func() {
+		if txFlowErr != nil {
+			if _err := tx.Rollback().Error; _err != nil {
+				zlog.Warnf(ctx, "DB错误 事务回滚失败", _err)
+			}
+			return
+		}
+		if _err := tx.Commit().Error; _err != nil {
+			zlog.Warnf(ctx, "DB错误 事务提交失败", _err)
+			err = _err
+			return
+		}
+		policies := make([][]string, 0, len(rules))
+		for i := range rules {
+			policies = append(policies, rules[i].PolicyRule())
+		}
+		if _err := helpers.AddPolicies(policies); _err != nil {
+			zlog.Warnf(ctx, "casbin add copied policy failure, reload all", _err)
+			helpers.ReloadPolicy()
+		}
+	}()
+	userGroup := &m.UserGroup{}
+	if _, txFlowErr = userGroup.BatchInsertUserGroupByShard(ctx, rc.TargetUserId%components.USER_GROUP_SHARD_NUM, userGroups, tx); txFlowErr != nil {
+		zlog.Errorf(ctx, "copy user insert userGroup fail, err:%v", txFlowErr)
+		return txFlowErr
+	}
+	casbinRule := &m.CasbinRule{}
+	if _, txFlowErr = casbinRule.BatchInsertCasbinRule(ctx, rules, tx); txFlowErr != nil {
+		zlog.Errorf(ctx, "copy user insert policy fail, err:%v", txFlowErr)
+		return txFlowErr
+	}
+	accessRequest := &m.AccessRequest{}
+	if _, txFlowErr = accessRequest.BatchInsertAccessRequest(ctx, requests, tx); txFlowErr != nil {
+		zlog.Errorf(ctx, "copy user insert access request fail, err:%v", txFlowErr)
+		return txFlowErr
+	}
+	for domain, detail := range details {
+		var productId, appId int64
+		fmt.Sscanf(domain, "%d:%d", &productId, &appId)
+		audit.Record(ctx, audit.Entry{
+			ProductId:  productId,
+			AppId:      appId,
+			OperateUid: rc.OperateUid,
+			Action:     audit.ActionUserCopy,
+			TargetType: audit.TargetUser,
+			TargetId:   rc.TargetUserId,
+			Detail:     detail,
+		}, tx)
+	}
+	return nil
+}
+
+func (rc *RCopyInput) checkParams() error {
+	if rc.SourceUserId <= 0 || rc.TargetUserId <= 0 || rc.SourceUserId == rc.TargetUserId {
+		return helpers.NewError(components.ErrorUserGroupParamsInvalid, "sourceUserId/targetUserId 不合法")
+	}
+	if rc.OperateUid <= 0 {
+		return helpers.NewError(components.ErrorUserGroupParamsInvalid, "operateUid 不合法")
+	}
+	if rc.ExpireTime < 0 || (rc.ExpireTime > 0 && rc.ExpireTime <= time.Now().Unix()) {
+		return helpers.NewError(components.ErrorUserGroupParamsInvalid, "expireTime 不合法")
+	}
+	return nil
+}
@@ -5,6 +5,10 @@ import (
 	"permission/components"
 	"permission/helpers"
 	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/audit"
+	"permission/service/owner"
+	"permission/service/sod"
 	"time"
 )
 
@@ -16,12 +20,20 @@ type RCreateInput struct {
 	GroupId    int64
 	Status     int8
 	OperateUid int64
+	ExpireTime int64 // 组员资格到期时间，0表示永久
+	Append     bool  // 为 true 时在已有组员资格之外加入该权限组；为 false 时将用户在产线下的权限组设置为该权限组，替换原有的组员资格
+	System     bool  // 系统内部授权(如审批通过)，不校验操作人是否为负责人
 }
 
-func (rc *RCreateInput) CreateUserGroup(ctx *gin.Context) (bool, error) {
+func (rc *RCreateInput) CreateUserGroup(ctx *gin.Context) (ok bool, err error) {
 	if err := rc.checkParams(); err != nil {
 		return false, err
 	}
+	if !rc.System {
+		if _, err := owner.Authorize(ctx, rc.GroupId, rc.OperateUid); err != nil {
+			return false, err
+		}
+	}
 	userGroup := &m.UserGroup{
 		ProductId:  rc.ProductId,
 		AppId:      rc.AppId,
@@ -33,22 +45,86 @@ func (rc *RCreateInput) CreateUserGroup(ctx *gin.Context) (bool, error) {
 		CreateTime: time.Now().Unix(),
 		UpdateTime: time.Now().Unix(),
 		Status:     rc.Status,
+		ExpireTime: rc.ExpireTime,
 	}
+	shard := rc.UserId % components.USER_GROUP_SHARD_NUM
 	condition := map[string]interface{}{
 		"product_id": rc.ProductId,
 		"app_id":     rc.AppId,
 		"user_type":  rc.UserType,
 		"user_id":    rc.UserId,
 	}
-	userGroupInfo, err1 := userGroup.GetUserGroupByCondition(ctx, condition)
-	if err1 != nil {
+	existing, err := userGroup.GetUserGroupListByShard(ctx, shard, condition, nil)
+	if err != nil {
 		return false, helpers.NewError(components.ErrorDbSelect, "get all userGroupList by condition failure")
 	}
-	userGroup.ID = userGroupInfo.ID
-	_, err2 := userGroup.UpsertUserGroup(ctx)
-	if err2 != nil {
-		return false, helpers.NewError(components.ErrorDbUpdate, "upsert userGroup failure")
+	// 同一权限组下只保留一条组员资格；设置模式下复用原有的一条记录，其余的删除
+	var replaced []m.UserGroup
+	for _, ug := range existing {
+		if ug.GroupId == rc.GroupId {
+			userGroup.ID = ug.ID
+		} else if !rc.Append {
+			replaced = append(replaced, ug)
+		}
+	}
+	if userGroup.ID == 0 && len(replaced) > 0 {
+		userGroup.ID, userGroup.CreateUid, userGroup.CreateTime = replaced[0].ID, replaced[0].CreateUid, replaced[0].CreateTime
+	}
+	replacedGroupIds := m.UserGroupIds(replaced)
+	if rc.Status == components.GROUP_STATUS_ACTIVE {
+		if err := sod.CheckReplace(ctx, rc.ProductId, rc.AppId, rc.UserId, replacedGroupIds, rc.GroupId); err != nil {
+			return false, err
+		}
+	}
+
+	var txFlowErr error
+	tx := helpers.MysqlClientPermission.Begin()
+	if err := tx.Error; err != nil {
+		zlog.Warnf(ctx, "DB错误 开启事务失败", err)
+		return false, helpers.NewError(components.ErrorDbError, err.Error())
+	}
+	defer func() {
+		if txFlowErr != nil {
+			if _err := tx.Rollback().Error; _err != nil {
+				zlog.Warnf(ctx, "DB错误 事务回滚失败", _err)
+			}
+			return
+		}
+		if _err := tx.Commit().Error; _err != nil {
+			zlog.Warnf(ctx, "DB错误 事务提交失败", _err)
+			ok, err = false, _err
+		}
+	}()
+	if _, err := userGroup.UpsertUserGroup(ctx, tx); err != nil {
+		txFlowErr = helpers.NewError(components.ErrorDbUpdate, "upsert userGroup failure")
+		return false, txFlowErr
 	}
+	var deleteIds []int64
+	for _, ug := range replaced {
+		if ug.ID != userGroup.ID {
+			deleteIds = append(deleteIds, ug.ID)
+		}
+	}
+	if len(deleteIds) > 0 {
+		if _, err := userGroup.DeleteUserGroupByShard(ctx, shard, map[string]interface{}{"id": deleteIds}, tx); err != nil {
+			txFlowErr = helpers.NewError(components.ErrorDbDelete, "delete replaced userGroup failure")
+			return false, txFlowErr
+		}
+	}
+	audit.Record(ctx, audit.Entry{
+		ProductId:  rc.ProductId,
+		AppId:      rc.AppId,
+		OperateUid: rc.OperateUid,
+		Action:     audit.ActionMemberAdd,
+		TargetType: audit.TargetUser,
+		TargetId:   rc.UserId,
+		Detail: map[string]interface{}{
+			"groupId":          rc.GroupId,
+			"status":           rc.Status,
+			"expireTime":       rc.ExpireTime,
+			"replacedGroupIds": replacedGroupIds,
+		},
+	}, tx)
 	return true, nil
 }
 
@@ -68,6 +144,9 @@ func (rc *RCreateInput) checkParams() error {
 	if rc.Status < 0 {
 		return helpers.NewError(components.ErrorUserGroupParamsInvalid, "status 不合法")
 	}
+	if rc.ExpireTime < 0 {
+		return helpers.NewError(components.ErrorUserGroupParamsInvalid, "expireTime 不合法")
+	}
 	if rc.OperateUid < 0 {
 		return helpers.NewError(components.ErrorUserGroupParamsInvalid, "operatedUid 不合法")
 	}
@@ -0,0 +1,65 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+)
+
+type RListInput struct {
+	GroupId  int64
+	PageNo   int
+	PageSize int
+}
+
+type RListOutput struct {
+	Total      int           `json:"total"`
+	MemberList []m.UserGroup `json:"memberList"`
+}
+
+// GetGroupMemberList 权限组的组员分散在全部用户分表中，按分表顺序、分表内id升序分页
+func (rl *RListInput) GetGroupMemberList(ctx *gin.Context) (RListOutput, error) {
+	if rl.GroupId <= 0 {
+		return RListOutput{}, helpers.NewError(components.ErrorUserGroupParamsInvalid, "groupId 不合法")
+	}
+	pageNo, pageSize := rl.PageNo, rl.PageSize
+	if pageNo <= 0 {
+		pageNo = 1
+	}
+	switch {
+	case pageSize > 100:
+		pageSize = 100
+	case pageSize <= 0:
+		pageSize = 10
+	}
+	condition := map[string]interface{}{
+		"group_id": rl.GroupId,
+	}
+	userGroup := &m.UserGroup{}
+	counts := make([]int64, components.USER_GROUP_SHARD_NUM)
+	var total int64
+	for shard := int64(0); shard < components.USER_GROUP_SHARD_NUM; shard++ {
+		cnt, err := userGroup.CountUserGroupByShard(ctx, shard, condition)
+		if err != nil {
+			return RListOutput{}, helpers.NewError(components.ErrorDbSelect, "count userGroup failure")
+		}
+		counts[shard] = cnt
+		total += cnt
+	}
+	out := RListOutput{Total: int(total), MemberList: []m.UserGroup{}}
+	skip := int64((pageNo - 1) * pageSize)
+	for shard := int64(0); shard < components.USER_GROUP_SHARD_NUM && len(out.MemberList) < pageSize; shard++ {
+		if skip >= counts[shard] {
+			skip -= counts[shard]
+			continue
+		}
+		list, err := userGroup.GetUserGroupListByShardPage(ctx, shard, condition, int(skip), pageSize-len(out.MemberList))
+		if err != nil {
+			return RListOutput{}, helpers.NewError(components.ErrorDbSelect, "get userGroup list failure")
+		}
+		out.MemberList = append(out.MemberList, list...)
+		skip = 0
+	}
+	return out, nil
+}
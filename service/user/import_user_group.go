@@ -0,0 +1,392 @@
+package user
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"permission/api"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/audit"
+	"permission/service/owner"
+	"permission/service/sod"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 单次导入的最大行数
+const importMaxRows = 5000
+
+// 导入结果
+const (
+	ImportValid   = "valid" // 校验通过，dryRun 时不写入
+	ImportCreated = "created"
+	ImportUpdated = "updated"
+	ImportInvalid = "invalid"
+	ImportFailed  = "failed" // 校验通过但写入失败
+)
+
+// ImportRow 一行组员资格，csv 首行为表头，列名与 json 字段名一致
+type ImportRow struct {
+	ProductId int64 `json:"productId"`
+	AppId     int64 `json:"appId"`
+	UserType  int8  `json:"userType"`
+	UserId    int64 `json:"userId"`
+	GroupId   int64 `json:"groupId"`
+	Status    int8  `json:"status"`
+}
+
+type ImportResult struct {
+	Line   int       `json:"line"` // csv 为文件行号，json 为数组下标+1
+	Row    ImportRow `json:"row"`
+	Result string    `json:"result"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// RImportInput Rows 与 Csv 二选一
+type RImportInput struct {
+	Rows       []ImportRow
+	Csv        string
+	OperateUid int64
+	DryRun     bool
+}
+
+type RImportOutput struct {
+	Total   int            `json:"total"`
+	Succ    int            `json:"succ"`
+	Invalid int            `json:"invalid"`
+	Failed  int            `json:"failed"`
+	Results []ImportResult `json:"results"`
+}
+
+var csvColumns = []string{"productId", "appId", "userType", "userId", "groupId", "status"}
+
+// ImportUserGroup 批量导入组员资格：逐行校验后按用户分表分批写入，返回逐行结果
+func (ri *RImportInput) ImportUserGroup(ctx *gin.Context) (RImportOutput, error) {
+	if ri.OperateUid <= 0 {
+		return RImportOutput{}, helpers.NewError(components.ErrorUserGroupParamsInvalid, "operateUid 不合法")
+	}
+	if (len(ri.Rows) == 0) == (ri.Csv == "") {
+		return RImportOutput{}, helpers.NewError(components.ErrorUserGroupParamsInvalid, "rows 与 csv 需且只需传一个")
+	}
+	results := make([]ImportResult, 0, len(ri.Rows))
+	for i, row := range ri.Rows {
+		results = append(results, ImportResult{Line: i + 1, Row: row})
+	}
+	if ri.Csv != "" {
+		var err error
+		if results, err = parseImportCsv(ri.Csv); err != nil {
+			return RImportOutput{}, helpers.NewError(components.ErrorUserGroupParamsInvalid, err.Error())
+		}
+	}
+	if len(results) > importMaxRows {
+		return RImportOutput{}, helpers.NewError(components.ErrorUserGroupParamsInvalid, fmt.Sprintf("单次最多导入 %d 行", importMaxRows))
+	}
+
+	v := newImportValidator(ri.OperateUid)
+	for i := range results {
+		if results[i].Result == ImportInvalid {
+			continue
+		}
+		if err := v.validate(ctx, results[i].Row); err != nil {
+			results[i].Result = ImportInvalid
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Result = ImportValid
+	}
+	if !ri.DryRun {
+		ri.write(ctx, results)
+	}
+	out := RImportOutput{Total: len(results), Results: results}
+	for _, r := range results {
+		switch r.Result {
+		case ImportInvalid:
+			out.Invalid++
+		case ImportFailed:
+			out.Failed++
+		default:
+			out.Succ++
+		}
+	}
+	return out, nil
+}
+
+func parseImportCsv(content string) ([]ImportResult, error) {
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %v", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, col := range csvColumns {
+		if _, ok := index[strings.ToLower(col)]; !ok {
+			return nil, fmt.Errorf("csv header missing column %s", col)
+		}
+	}
+	var results []ImportResult
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv: %v", err)
+		}
+		line, _ := reader.FieldPos(0)
+		result := ImportResult{Line: line}
+		values := make(map[string]int64, len(csvColumns))
+		for _, col := range csvColumns {
+			raw := strings.TrimSpace(record[index[strings.ToLower(col)]])
+			if raw == "" {
+				continue
+			}
+			n, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				result.Result = ImportInvalid
+				result.Error = fmt.Sprintf("%s 不是整数: %q", col, raw)
+				break
+			}
+			values[col] = n
+		}
+		result.Row = ImportRow{
+			ProductId: values["productId"],
+			AppId:     values["appId"],
+			UserType:  int8(values["userType"]),
+			UserId:    values["userId"],
+			GroupId:   values["groupId"],
+			Status:    int8(values["status"]),
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// importValidator 逐行校验，权限组、负责人鉴权和passport结果在一次导入内复用
+type importValidator struct {
+	operateUid int64
+	groups     map[int64]m.Group
+	authorized map[int64]error
+	userTypes  map[[2]int64]int8
+	seen       map[[4]int64]struct{}
+	assigned   map[[3]int64][]int64 // 前面已通过校验的启用行中，用户在各产线下加入的权限组
+}
+
+func newImportValidator(operateUid int64) *importValidator {
+	return &importValidator{
+		operateUid: operateUid,
+		groups:     make(map[int64]m.Group),
+		authorized: make(map[int64]error),
+		userTypes:  make(map[[2]int64]int8),
+		seen:       make(map[[4]int64]struct{}),
+		assigned:   make(map[[3]int64][]int64),
+	}
+}
+
+func (v *importValidator) validate(ctx *gin.Context, row ImportRow) error {
+	if row.ProductId <= 0 || row.AppId <= 0 || row.UserId <= 0 || row.GroupId <= 0 {
+		return fmt.Errorf("productId/appId/userId/groupId 不合法")
+	}
+	if row.UserType != components.USER_TYPE_INTERNAL && row.UserType != components.USER_TYPE_OUTER {
+		return fmt.Errorf("userType 不合法")
+	}
+	if row.Status != components.GROUP_STATUS_ACTIVE && row.Status != components.GROUP_STATUS_CLOSE {
+		return fmt.Errorf("status 不合法")
+	}
+	key := [4]int64{row.AppId, int64(row.UserType), row.UserId, row.GroupId}
+	if _, ok := v.seen[key]; ok {
+		return fmt.Errorf("与前面的行重复")
+	}
+	g, err := v.group(ctx, row.GroupId)
+	if err != nil {
+		return err
+	}
+	if g.ID <= 0 || g.Status != components.GROUP_STATUS_ACTIVE {
+		return fmt.Errorf("权限组 %d 不存在或未启用", row.GroupId)
+	}
+	if g.ProductID != row.ProductId || g.AppID != row.AppId {
+		return fmt.Errorf("权限组 %d 不属于该产线", row.GroupId)
+	}
+	if err := v.authorize(ctx, row.GroupId); err != nil {
+		return err
+	}
+	userType, err := v.userType(ctx, row.AppId, row.UserId)
+	if err != nil {
+		return err
+	}
+	if userType != row.UserType {
+		return fmt.Errorf("userType 与 passport 不一致")
+	}
+	// 互斥约束既要校验库中已有的组员资格，也要校验同一批次中前面的行
+	userKey := [3]int64{row.ProductId, row.AppId, row.UserId}
+	if row.Status == components.GROUP_STATUS_ACTIVE {
+		if err := sod.CheckAssignment(ctx, row.ProductId, row.AppId, row.UserId, row.GroupId); err != nil {
+			return err
+		}
+		if err := sod.CheckGroups(ctx, row.UserId, row.GroupId, v.assigned[userKey]); err != nil {
+			return err
+		}
+		v.assigned[userKey] = append(v.assigned[userKey], row.GroupId)
+	}
+	v.seen[key] = struct{}{}
+	return nil
+}
+
+func (v *importValidator) group(ctx *gin.Context, groupId int64) (m.Group, error) {
+	if g, ok := v.groups[groupId]; ok {
+		return g, nil
+	}
+	group := &m.Group{}
+	g, err := group.GetGroupById(ctx, groupId)
+	if err != nil {
+		return g, fmt.Errorf("get group by id failure")
+	}
+	v.groups[groupId] = g
+	return g, nil
+}
+
+func (v *importValidator) authorize(ctx *gin.Context, groupId int64) error {
+	if err, ok := v.authorized[groupId]; ok {
+		return err
+	}
+	_, err := owner.Authorize(ctx, groupId, v.operateUid)
+	v.authorized[groupId] = err
+	return err
+}
+
+// userType 按passport结果区分内外网用户，与鉴权时的判断一致
+func (v *importValidator) userType(ctx *gin.Context, appId, userId int64) (int8, error) {
+	key := [2]int64{appId, userId}
+	if t, ok := v.userTypes[key]; ok {
+		return t, nil
+	}
+	info, err := api.GetUserInfoByUserId(ctx, appId, userId)
+	if err != nil {
+		zlog.Warnf(ctx, "import passport get userinfo failure, userId=%d err:%v", userId, err)
+		return 0, fmt.Errorf("passport 查询用户失败")
+	}
+	userType := components.USER_TYPE_INTERNAL
+	if info.UserId > 0 {
+		userType = components.USER_TYPE_OUTER
+	}
+	v.userTypes[key] = userType
+	return userType, nil
+}
+
+// write 按用户分表分批写入，每个分表一个事务，事务失败时该分表的行全部标记为失败
+func (ri *RImportInput) write(ctx *gin.Context, results []ImportResult) {
+	batches := make(map[int64][]int)
+	for i, r := range results {
+		if r.Result != ImportValid {
+			continue
+		}
+		shard := r.Row.UserId % components.USER_GROUP_SHARD_NUM
+		batches[shard] = append(batches[shard], i)
+	}
+	shards := make([]int64, 0, len(batches))
+	for shard := range batches {
+		shards = append(shards, shard)
+	}
+	sort.Slice(shards, func(i, j int) bool { return shards[i] < shards[j] })
+	for _, shard := range shards {
+		idx := batches[shard]
+		if err := ri.writeShard(ctx, shard, results, idx); err != nil {
+			zlog.Errorf(ctx, "import userGroup shard=%d fail, err:%v", shard, err)
+			for _, i := range idx {
+				results[i].Result = ImportFailed
+				results[i].Error = err.Error()
+			}
+		}
+	}
+}
+
+func (ri *RImportInput) writeShard(ctx *gin.Context, shard int64, results []ImportResult, idx []int) (err error) {
+	var txFlowErr error
+	tx := helpers.MysqlClientPermission.Begin()
+	if err := tx.Error; err != nil {
+		zlog.Warnf(ctx, "DB错误 开启事务失败", err)
+		return err
+	}
+	defer func() {
+		if txFlowErr != nil {
+			if _err := tx.Rollback().Error; _err != nil {
+				zlog.Warnf(ctx, "DB错误 事务回滚失败", _err)
+			}
+			return
+		}
+		if _err := tx.Commit().Error; _err != nil {
+			zlog.Warnf(ctx, "DB错误 事务提交失败", _err)
+			err = _err
+		}
+	}()
+	userIds := make([]int64, 0, len(idx))
+	for _, i := range idx {
+		userIds = append(userIds, results[i].Row.UserId)
+	}
+	userGroup := &m.UserGroup{}
+	existing, txFlowErr := userGroup.GetUserGroupListByShard(ctx, shard, map[string]interface{}{"user_id": userIds}, tx)
+	if txFlowErr != nil {
+		return txFlowErr
+	}
+	existingIds := make(map[[4]int64]int64, len(existing))
+	for _, ug := range existing {
+		existingIds[[4]int64{ug.AppId, int64(ug.UserType), ug.UserId, ug.GroupId}] = ug.ID
+	}
+
+	now := time.Now().Unix()
+	var inserts []m.UserGroup
+	for _, i := range idx {
+		row := results[i].Row
+		id, ok := existingIds[[4]int64{row.AppId, int64(row.UserType), row.UserId, row.GroupId}]
+		if ok {
+			if _, txFlowErr = userGroup.UpdateUserGroupByShard(ctx, shard, id, map[string]interface{}{
+				"status":      row.Status,
+				"update_uid":  ri.OperateUid,
+				"update_time": now,
+			}, tx); txFlowErr != nil {
+				return txFlowErr
+			}
+			results[i].Result = ImportUpdated
+		} else {
+			inserts = append(inserts, m.UserGroup{
+				ProductId:  row.ProductId,
+				AppId:      row.AppId,
+				UserType:   row.UserType,
+				UserId:     row.UserId,
+				GroupId:    row.GroupId,
+				Status:     row.Status,
+				CreateUid:  ri.OperateUid,
+				UpdateUid:  ri.OperateUid,
+				CreateTime: now,
+				UpdateTime: now,
+			})
+			results[i].Result = ImportCreated
+		}
+		audit.Record(ctx, audit.Entry{
+			ProductId:  row.ProductId,
+			AppId:      row.AppId,
+			OperateUid: ri.OperateUid,
+			Action:     audit.ActionMemberAdd,
+			TargetType: audit.TargetUser,
+			TargetId:   row.UserId,
+			Detail: map[string]interface{}{
+				"groupId": row.GroupId,
+				"status":  row.Status,
+				"import":  true,
+			},
+		}, tx)
+	}
+	if _, txFlowErr = userGroup.BatchInsertUserGroupByShard(ctx, shard, inserts, tx); txFlowErr != nil {
+		return txFlowErr
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package user
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/pkg/golib/v2/zlog"
+	"permission/service/audit"
+	"permission/service/owner"
+	"strconv"
+	"time"
+)
+
+type ROffboardInput struct {
+	UserId     int64
+	OperateUid int64
+}
+
+type ROffboardOutput struct {
+	Memberships       int64 `json:"memberships"`
+	DirectRules       int64 `json:"directRules"`
+	Ownerships        int64 `json:"ownerships"`
+	Approvals         int64 `json:"approvals"`
+	CancelledRequests int64 `json:"cancelledRequests"`
+}
+
+// offboardDetail 离职用户在一个产线下被收回的权限，按产线写入审计
+type offboardDetail struct {
+	productId        int64
+	appId            int64
+	GroupIds         []int64  `json:"groupIds,omitempty"`
+	Resources        []string `json:"resources,omitempty"`
+	OwnedGroupIds    []int64  `json:"ownedGroupIds,omitempty"`
+	ApproverGroupIds []int64  `json:"approverGroupIds,omitempty"`
+}
+
+// OffboardUser 收回离职用户在全部产线下的组员资格、直接授权、负责人和审批人身份，并撤回其待审批的申请
+func (ro *ROffboardInput) OffboardUser(ctx *gin.Context) (out ROffboardOutput, err error) {
+	if ro.UserId <= 0 || ro.OperateUid <= 0 {
+		return out, helpers.NewError(components.ErrorUserGroupParamsInvalid, "userId/operateUid 不合法")
+	}
+	if !owner.IsSuperAdmin(ro.OperateUid) {
+		return out, helpers.NewError(components.ErrorNotSuperAdmin, fmt.Sprintf("uid=%d", ro.OperateUid))
+	}
+	subject := components.CASBIN_SUB_USER_PREFIX + strconv.FormatInt(ro.UserId, 10)
+	shard := ro.UserId % components.USER_GROUP_SHARD_NUM
+	details, err := ro.collect(ctx, subject, shard)
+	if err != nil {
+		return out, err
+	}
+
+	var txFlowErr error
+	tx := helpers.MysqlClientPermission.Begin()
+	if err := tx.Error; err != nil {
+		zlog.Warnf(ctx, "DB错误 开启事务失败", err)
+		return out, helpers.NewError(components.ErrorDbError, err.Error())
+	}
+	defer func() {
+		if txFlowErr != nil {
+			if _err := tx.Rollback().Error; _err != nil {
+				zlog.Warnf(ctx, "DB错误 事务回滚失败", _err)
+			}
+			return
+		}
+		if _err := tx.Commit().Error; _err != nil {
+			zlog.Warnf(ctx, "DB错误 事务提交失败", _err)
+			out, err = ROffboardOutput{}, _err
+			return
+		}
+		if out.DirectRules > 0 {
+			if _err := helpers.RemoveFilteredPolicy(subject); _err != nil {
+				zlog.Warnf(ctx, "casbin remove user policy failure, reload all", _err)
+				helpers.ReloadPolicy()
+			}
+		}
+	}()
+	userGroup := &m.UserGroup{}
+	if out.Memberships, txFlowErr = userGroup.DeleteUserGroupByShard(ctx, shard, map[string]interface{}{"user_id": ro.UserId}, tx); txFlowErr != nil {
+		zlog.Errorf(ctx, "offboard delete userGroup fail, err:%v", txFlowErr)
+		return ROffboardOutput{}, txFlowErr
+	}
+	casbinRule := &m.CasbinRule{}
+	if out.DirectRules, txFlowErr = casbinRule.DeleteCasbinRuleByCondition(ctx, map[string]interface{}{
+		"ptype": components.CASBIN_RULE_PTYPE,
+		"v0":    subject,
+	}, tx); txFlowErr != nil {
+		zlog.Errorf(ctx, "offboard delete user policy fail, err:%v", txFlowErr)
+		return ROffboardOutput{}, txFlowErr
+	}
+	groupOwner := &m.GroupOwner{}
+	if out.Ownerships, txFlowErr = groupOwner.DeleteGroupOwnerByUserId(ctx, ro.UserId, tx); txFlowErr != nil {
+		zlog.Errorf(ctx, "offboard delete group owner fail, err:%v", txFlowErr)
+		return ROffboardOutput{}, txFlowErr
+	}
+	groupApprover := &m.GroupApprover{}
+	if out.Approvals, txFlowErr = groupApprover.DeleteGroupApproverByUserId(ctx, ro.UserId, tx); txFlowErr != nil {
+		zlog.Errorf(ctx, "offboard delete group approver fail, err:%v", txFlowErr)
+		return ROffboardOutput{}, txFlowErr
+	}
+	accessRequest := &m.AccessRequest{}
+	if out.CancelledRequests, txFlowErr = accessRequest.UpdateAccessRequestByConds(ctx, map[string]interface{}{
+		"user_id": ro.UserId,
+		"status":  components.ACCESS_REQUEST_STATUS_PENDING,
+	}, map[string]interface{}{
+		"status":      components.ACCESS_REQUEST_STATUS_CANCELLED,
+		"update_time": time.Now().Unix(),
+	}, tx); txFlowErr != nil {
+		zlog.Errorf(ctx, "offboard cancel access request fail, err:%v", txFlowErr)
+		return ROffboardOutput{}, txFlowErr
+	}
+	for _, d := range details {
+		audit.Record(ctx, audit.Entry{
+			ProductId:  d.productId,
+			AppId:      d.appId,
+			OperateUid: ro.OperateUid,
+			Action:     audit.ActionUserOffboard,
+			TargetType: audit.TargetUser,
+			TargetId:   ro.UserId,
+			Detail:     d,
+		}, tx)
+	}
+	return out, nil
+}
+
+// collect 按产线汇总用户将被收回的权限，用于写审计
+func (ro *ROffboardInput) collect(ctx *gin.Context, subject string, shard int64) (map[string]*offboardDetail, error) {
+	details := make(map[string]*offboardDetail)
+	detailOf := func(productId, appId int64) *offboardDetail {
+		key := fmt.Sprintf("%d:%d", productId, appId)
+		if details[key] == nil {
+			details[key] = &offboardDetail{productId: productId, appId: appId}
+		}
+		return details[key]
+	}
+	userGroup := &m.UserGroup{}
+	userGroups, err := userGroup.GetUserGroupListByShard(ctx, shard, map[string]interface{}{"user_id": ro.UserId}, nil)
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get userGroup list failure")
+	}
+	for _, ug := range userGroups {
+		d := detailOf(ug.ProductId, ug.AppId)
+		d.GroupIds = append(d.GroupIds, ug.GroupId)
+	}
+	casbinRule := &m.CasbinRule{}
+	rules, err := casbinRule.GetCasbinRulesListByConds(ctx, map[string]interface{}{
+		"ptype": components.CASBIN_RULE_PTYPE,
+		"v0":    subject,
+	})
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get user policy failure")
+	}
+	for _, r := range rules {
+		var productId, appId int64
+		fmt.Sscanf(r.ProductAppField, "%d:%d", &productId, &appId)
+		d := detailOf(productId, appId)
+		d.Resources = append(d.Resources, r.Resource)
+	}
+
+	// 负责人和审批人身份按权限组所属产线归类
+	groupOwner := &m.GroupOwner{}
+	owners, err := groupOwner.GetGroupOwnerListByConds(ctx, map[string]interface{}{"user_id": ro.UserId})
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get group owner failure")
+	}
+	groupApprover := &m.GroupApprover{}
+	approvers, err := groupApprover.GetGroupApproverListByConds(ctx, map[string]interface{}{"user_id": ro.UserId})
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get group approver failure")
+	}
+	var groupIds []int64
+	for _, o := range owners {
+		groupIds = append(groupIds, o.GroupId)
+	}
+	for _, a := range approvers {
+		groupIds = append(groupIds, a.GroupId)
+	}
+	if len(groupIds) == 0 {
+		return details, nil
+	}
+	group := &m.Group{}
+	groups, err := group.GetGroupListByConds(ctx, map[string]interface{}{"id": groupIds})
+	if err != nil {
+		return nil, helpers.NewError(components.ErrorDbSelect, "get group list failure")
+	}
+	groupMap := make(map[int64]m.Group, len(groups))
+	for _, g := range groups {
+		groupMap[g.ID] = g
+	}
+	for _, o := range owners {
+		g := groupMap[o.GroupId]
+		d := detailOf(g.ProductID, g.AppID)
+		d.OwnedGroupIds = append(d.OwnedGroupIds, o.GroupId)
+	}
+	for _, a := range approvers {
+		g := groupMap[a.GroupId]
+		d := detailOf(g.ProductID, g.AppID)
+		d.ApproverGroupIds = append(d.ApproverGroupIds, a.GroupId)
+	}
+	return details, nil
+}
@@ -0,0 +1,62 @@
+package user
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"permission/components"
+	"permission/helpers"
+	m "permission/models"
+	"permission/service/audit"
+	"permission/service/owner"
+)
+
+type RRemoveInput struct {
+	ProductId  int64
+	AppId      int64
+	UserType   int8
+	UserId     int64
+	GroupId    int64
+	OperateUid int64
+}
+
+// RemoveUserGroup 将用户移出权限组
+func (rr *RRemoveInput) RemoveUserGroup(ctx *gin.Context) (bool, error) {
+	if rr.ProductId <= 0 || rr.AppId <= 0 || rr.UserId <= 0 || rr.GroupId <= 0 || rr.OperateUid <= 0 {
+		return false, helpers.NewError(components.ErrorUserGroupParamsInvalid, "productId/appId/userId/groupId/operateUid 不合法")
+	}
+	if _, err := owner.Authorize(ctx, rr.GroupId, rr.OperateUid); err != nil {
+		return false, err
+	}
+	userGroup := &m.UserGroup{UserId: rr.UserId}
+	info, err := userGroup.GetUserGroupByCondition(ctx, map[string]interface{}{
+		"product_id": rr.ProductId,
+		"app_id":     rr.AppId,
+		"user_type":  rr.UserType,
+		"user_id":    rr.UserId,
+		"group_id":   rr.GroupId,
+	})
+	if err != nil {
+		return false, helpers.NewError(components.ErrorDbSelect, "get userGroup by condition failure")
+	}
+	if info.ID <= 0 {
+		return false, helpers.NewError(components.ErrorUserGroupNotExist, fmt.Sprintf("userId=%d groupId=%d", rr.UserId, rr.GroupId))
+	}
+	shard := rr.UserId % components.USER_GROUP_SHARD_NUM
+	if _, err := userGroup.DeleteUserGroupByShard(ctx, shard, map[string]interface{}{"id": info.ID}, nil); err != nil {
+		return false, helpers.NewError(components.ErrorDbDelete, "delete userGroup failure")
+	}
+	audit.Record(ctx, audit.Entry{
+		ProductId:  rr.ProductId,
+		AppId:      rr.AppId,
+		OperateUid: rr.OperateUid,
+		Action:     audit.ActionMemberRemove,
+		TargetType: audit.TargetUser,
+		TargetId:   rr.UserId,
+		Detail: map[string]interface{}{
+			"groupId":    rr.GroupId,
+			"status":     info.Status,
+			"expireTime": info.ExpireTime,
+		},
+	}, nil)
+	return true, nil
+}
@@ -0,0 +1,281 @@
+-- 权限系统表结构变更，按上线顺序追加
+
+-- 权限组乐观锁版本号，编辑权限组时需携带并校验
+ALTER TABLE `tb_permission_group`
+    ADD COLUMN `version` BIGINT NOT NULL DEFAULT 0 COMMENT '乐观锁版本号，每次编辑+1';
+
+-- 产线权限快照，content 为权限组、映射关系、校验规则、组员的json
+CREATE TABLE IF NOT EXISTS `tb_permission_snapshot`
+(
+    `id`          BIGINT      NOT NULL AUTO_INCREMENT,
+    `product_id`  BIGINT      NOT NULL COMMENT '产品线id',
+    `app_id`      BIGINT      NOT NULL COMMENT '应用id',
+    `trigger`     TINYINT     NOT NULL DEFAULT 0 COMMENT '0:手动 1:大批量变更前自动 2:回滚前自动',
+    `reason`      VARCHAR(255) NOT NULL DEFAULT '' COMMENT '快照原因',
+    `content`     LONGTEXT    NOT NULL COMMENT '快照内容json',
+    `create_uid`  BIGINT      NOT NULL DEFAULT 0,
+    `create_time` BIGINT      NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    KEY `idx_product_app` (`product_id`, `app_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='产线权限快照';
+
+-- 自助权限申请单
+CREATE TABLE IF NOT EXISTS `tb_permission_access_request`
+(
+    `id`                BIGINT       NOT NULL AUTO_INCREMENT,
+    `product_id`        BIGINT       NOT NULL COMMENT '产品线id',
+    `app_id`            BIGINT       NOT NULL COMMENT '应用id',
+    `user_type`         TINYINT      NOT NULL DEFAULT 0,
+    `user_id`           BIGINT       NOT NULL COMMENT '申请人',
+    `request_type`      TINYINT      NOT NULL DEFAULT 0 COMMENT '0:权限组 1:单个资源',
+    `group_id`          BIGINT       NOT NULL DEFAULT 0,
+    `resource`          VARCHAR(255) NOT NULL DEFAULT '',
+    `reason`            VARCHAR(512) NOT NULL DEFAULT '' COMMENT '申请理由',
+    `duration`          BIGINT       NOT NULL DEFAULT 0 COMMENT '授权时长(秒)，0表示永久',
+    `status`            TINYINT      NOT NULL DEFAULT 0 COMMENT '0:待审批 1:已通过 2:已驳回 3:已过期 4:已撤回',
+    `handle_uid`        BIGINT       NOT NULL DEFAULT 0,
+    `handle_remark`     VARCHAR(512) NOT NULL DEFAULT '',
+    `handle_time`       BIGINT       NOT NULL DEFAULT 0,
+    `grant_expire_time` BIGINT       NOT NULL DEFAULT 0 COMMENT '授权到期时间，0表示永久',
+    `create_time`       BIGINT       NOT NULL DEFAULT 0,
+    `update_time`       BIGINT       NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    KEY `idx_product_app_user` (`product_id`, `app_id`, `user_id`),
+    KEY `idx_status_create` (`status`, `create_time`),
+    KEY `idx_status_grant_expire` (`status`, `grant_expire_time`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='自助权限申请单';
+
+-- 权限组审批人
+CREATE TABLE IF NOT EXISTS `tb_permission_group_approver`
+(
+    `id`          BIGINT NOT NULL AUTO_INCREMENT,
+    `group_id`    BIGINT NOT NULL,
+    `user_id`     BIGINT NOT NULL COMMENT '审批人',
+    `create_uid`  BIGINT NOT NULL DEFAULT 0,
+    `create_time` BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_group_user` (`group_id`, `user_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='权限组审批人';
+
+-- 用户权限组关系增加到期时间，16张分表均需执行
+ALTER TABLE `tb_permission_rel_user_group0` ADD COLUMN `expire_time` BIGINT NOT NULL DEFAULT 0 COMMENT '到期时间，0表示永久';
+ALTER TABLE `tb_permission_rel_user_group1` ADD COLUMN `expire_time` BIGINT NOT NULL DEFAULT 0 COMMENT '到期时间，0表示永久';
+ALTER TABLE `tb_permission_rel_user_group2` ADD COLUMN `expire_time` BIGINT NOT NULL DEFAULT 0 COMMENT '到期时间，0表示永久';
+ALTER TABLE `tb_permission_rel_user_group3` ADD COLUMN `expire_time` BIGINT NOT NULL DEFAULT 0 COMMENT '到期时间，0表示永久';
+ALTER TABLE `tb_permission_rel_user_group4` ADD COLUMN `expire_time` BIGINT NOT NULL DEFAULT 0 COMMENT '到期时间，0表示永久';
+ALTER TABLE `tb_permission_rel_user_group5` ADD COLUMN `expire_time` BIGINT NOT NULL DEFAULT 0 COMMENT '到期时间，0表示永久';
+ALTER TABLE `tb_permission_rel_user_group6` ADD COLUMN `expire_time` BIGINT NOT NULL DEFAULT 0 COMMENT '到期时间，0表示永久';
+ALTER TABLE `tb_permission_rel_user_group7` ADD COLUMN `expire_time` BIGINT NOT NULL DEFAULT 0 COMMENT '到期时间，0表示永久';
+ALTER TABLE `tb_permission_rel_user_group8` ADD COLUMN `expire_time` BIGINT NOT NULL DEFAULT 0 COMMENT '到期时间，0表示永久';
+ALTER TABLE `tb_permission_rel_user_group9` ADD COLUMN `expire_time` BIGINT NOT NULL DEFAULT 0 COMMENT '到期时间，0表示永久';
+ALTER TABLE `tb_permission_rel_user_group10` ADD COLUMN `expire_time` BIGINT NOT NULL DEFAULT 0 COMMENT '到期时间，0表示永久';
+ALTER TABLE `tb_permission_rel_user_group11` ADD COLUMN `expire_time` BIGINT NOT NULL DEFAULT 0 COMMENT '到期时间，0表示永久';
+ALTER TABLE `tb_permission_rel_user_group12` ADD COLUMN `expire_time` BIGINT NOT NULL DEFAULT 0 COMMENT '到期时间，0表示永久';
+ALTER TABLE `tb_permission_rel_user_group13` ADD COLUMN `expire_time` BIGINT NOT NULL DEFAULT 0 COMMENT '到期时间，0表示永久';
+ALTER TABLE `tb_permission_rel_user_group14` ADD COLUMN `expire_time` BIGINT NOT NULL DEFAULT 0 COMMENT '到期时间，0表示永久';
+ALTER TABLE `tb_permission_rel_user_group15` ADD COLUMN `expire_time` BIGINT NOT NULL DEFAULT 0 COMMENT '到期时间，0表示永久';
+
+-- 节点展示信息，菜单树同级按 sort_order 升序
+ALTER TABLE `tb_permission_node`
+    ADD COLUMN `sort_order` INT          NOT NULL DEFAULT 0 COMMENT '同级排序，升序',
+    ADD COLUMN `icon`       VARCHAR(128) NOT NULL DEFAULT '' COMMENT '菜单图标',
+    ADD COLUMN `route`      VARCHAR(255) NOT NULL DEFAULT '' COMMENT '前端路由';
+
+-- 权限组与接口节点映射关系上的数据范围
+ALTER TABLE `tb_permission_rel_group_node`
+    ADD COLUMN `data_scope` VARCHAR(1024) NOT NULL DEFAULT '' COMMENT '数据范围json，为空表示不限制';
+
+-- 权限组互斥约束，group_id_a < group_id_b
+CREATE TABLE IF NOT EXISTS `tb_permission_group_exclusion`
+(
+    `id`          BIGINT       NOT NULL AUTO_INCREMENT,
+    `product_id`  BIGINT       NOT NULL COMMENT '产品线id',
+    `app_id`      BIGINT       NOT NULL COMMENT '应用id',
+    `group_id_a`  BIGINT       NOT NULL,
+    `group_id_b`  BIGINT       NOT NULL,
+    `reason`      VARCHAR(255) NOT NULL DEFAULT '',
+    `create_uid`  BIGINT       NOT NULL DEFAULT 0,
+    `create_time` BIGINT       NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_groups` (`group_id_a`, `group_id_b`),
+    KEY `idx_group_b` (`group_id_b`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='权限组互斥约束';
+
+-- 用户可同时属于多个权限组(设置模式仍只保留一个，append 时追加)，同一用户在同一权限组下只保留一条组员资格，16张分表均需执行；
+-- 执行前需清理重复记录。读取组员资格的鉴权、菜单、节点列表、数据范围均改为合并用户的全部有效权限组
+ALTER TABLE `tb_permission_rel_user_group0` ADD UNIQUE KEY `uk_user_group` (`user_id`, `product_id`, `app_id`, `user_type`, `group_id`);
+ALTER TABLE `tb_permission_rel_user_group1` ADD UNIQUE KEY `uk_user_group` (`user_id`, `product_id`, `app_id`, `user_type`, `group_id`);
+ALTER TABLE `tb_permission_rel_user_group2` ADD UNIQUE KEY `uk_user_group` (`user_id`, `product_id`, `app_id`, `user_type`, `group_id`);
+ALTER TABLE `tb_permission_rel_user_group3` ADD UNIQUE KEY `uk_user_group` (`user_id`, `product_id`, `app_id`, `user_type`, `group_id`);
+ALTER TABLE `tb_permission_rel_user_group4` ADD UNIQUE KEY `uk_user_group` (`user_id`, `product_id`, `app_id`, `user_type`, `group_id`);
+ALTER TABLE `tb_permission_rel_user_group5` ADD UNIQUE KEY `uk_user_group` (`user_id`, `product_id`, `app_id`, `user_type`, `group_id`);
+ALTER TABLE `tb_permission_rel_user_group6` ADD UNIQUE KEY `uk_user_group` (`user_id`, `product_id`, `app_id`, `user_type`, `group_id`);
+ALTER TABLE `tb_permission_rel_user_group7` ADD UNIQUE KEY `uk_user_group` (`user_id`, `product_id`, `app_id`, `user_type`, `group_id`);
+ALTER TABLE `tb_permission_rel_user_group8` ADD UNIQUE KEY `uk_user_group` (`user_id`, `product_id`, `app_id`, `user_type`, `group_id`);
+ALTER TABLE `tb_permission_rel_user_group9` ADD UNIQUE KEY `uk_user_group` (`user_id`, `product_id`, `app_id`, `user_type`, `group_id`);
+ALTER TABLE `tb_permission_rel_user_group10` ADD UNIQUE KEY `uk_user_group` (`user_id`, `product_id`, `app_id`, `user_type`, `group_id`);
+ALTER TABLE `tb_permission_rel_user_group11` ADD UNIQUE KEY `uk_user_group` (`user_id`, `product_id`, `app_id`, `user_type`, `group_id`);
+ALTER TABLE `tb_permission_rel_user_group12` ADD UNIQUE KEY `uk_user_group` (`user_id`, `product_id`, `app_id`, `user_type`, `group_id`);
+ALTER TABLE `tb_permission_rel_user_group13` ADD UNIQUE KEY `uk_user_group` (`user_id`, `product_id`, `app_id`, `user_type`, `group_id`);
+ALTER TABLE `tb_permission_rel_user_group14` ADD UNIQUE KEY `uk_user_group` (`user_id`, `product_id`, `app_id`, `user_type`, `group_id`);
+ALTER TABLE `tb_permission_rel_user_group15` ADD UNIQUE KEY `uk_user_group` (`user_id`, `product_id`, `app_id`, `user_type`, `group_id`);
+
+-- 权限组负责人
+CREATE TABLE IF NOT EXISTS `tb_permission_group_owner`
+(
+    `id`          BIGINT NOT NULL AUTO_INCREMENT,
+    `group_id`    BIGINT NOT NULL,
+    `user_id`     BIGINT NOT NULL COMMENT '负责人',
+    `create_uid`  BIGINT NOT NULL DEFAULT 0,
+    `create_time` BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_group_user` (`group_id`, `user_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='权限组负责人';
+
+-- 权限管理操作审计日志
+CREATE TABLE IF NOT EXISTS `tb_permission_audit_log`
+(
+    `id`          BIGINT      NOT NULL AUTO_INCREMENT,
+    `product_id`  BIGINT      NOT NULL DEFAULT 0 COMMENT '产品线id',
+    `app_id`      BIGINT      NOT NULL DEFAULT 0 COMMENT '应用id',
+    `operate_uid` BIGINT      NOT NULL DEFAULT 0,
+    `action`      VARCHAR(64) NOT NULL DEFAULT '',
+    `target_type` VARCHAR(32) NOT NULL DEFAULT '',
+    `target_id`   BIGINT      NOT NULL DEFAULT 0,
+    `detail`      TEXT        NOT NULL COMMENT '操作详情json',
+    `create_time` BIGINT      NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    KEY `idx_product_app` (`product_id`, `app_id`),
+    KEY `idx_target` (`target_type`, `target_id`),
+    KEY `idx_operate_uid` (`operate_uid`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='权限管理审计日志';
+
+-- 路由清单同步
+ALTER TABLE `tb_permission_node` ADD COLUMN `is_stale` TINYINT NOT NULL DEFAULT 0 COMMENT '1:路由清单中已不存在该接口';
+
+-- 页面依赖的接口，授予页面时一并授予
+CREATE TABLE IF NOT EXISTS `tb_permission_node_dependency`
+(
+    `id`           BIGINT NOT NULL AUTO_INCREMENT,
+    `page_node_id` BIGINT NOT NULL COMMENT '页面节点id',
+    `api_node_id`  BIGINT NOT NULL COMMENT '接口节点id',
+    `create_uid`   BIGINT NOT NULL DEFAULT 0,
+    `create_time`  BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_page_api` (`page_node_id`, `api_node_id`),
+    KEY `idx_api_node_id` (`api_node_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='页面依赖的接口';
+
+ALTER TABLE `tb_permission_rel_group_node`
+    ADD COLUMN `implied` TINYINT NOT NULL DEFAULT 0 COMMENT '1:由页面依赖带出的接口';
+
+-- casbin 模型注册表，各domain可绑定不同的模型
+CREATE TABLE IF NOT EXISTS `tb_permission_casbin_model`
+(
+    `id`          BIGINT       NOT NULL AUTO_INCREMENT,
+    `name`        VARCHAR(64)  NOT NULL COMMENT '模型名称',
+    `text`        TEXT         NOT NULL COMMENT '模型定义',
+    `status`      TINYINT      NOT NULL DEFAULT 0 COMMENT '0:草稿 1:已启用',
+    `remark`      VARCHAR(255) NOT NULL DEFAULT '',
+    `create_uid`  BIGINT       NOT NULL DEFAULT 0,
+    `update_uid`  BIGINT       NOT NULL DEFAULT 0,
+    `create_time` BIGINT       NOT NULL DEFAULT 0,
+    `update_time` BIGINT       NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_name` (`name`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='casbin模型';
+
+CREATE TABLE IF NOT EXISTS `tb_permission_domain_model`
+(
+    `id`          BIGINT      NOT NULL AUTO_INCREMENT,
+    `domain`      VARCHAR(64) NOT NULL COMMENT 'product_id:app_id',
+    `model_name`  VARCHAR(64) NOT NULL,
+    `update_uid`  BIGINT      NOT NULL DEFAULT 0,
+    `update_time` BIGINT      NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_domain` (`domain`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='domain绑定的casbin模型，未绑定时使用默认模型';
+
+-- 授权使用情况，由鉴权路径在内存中汇总后周期写入，用于找出长期未使用的授权
+CREATE TABLE IF NOT EXISTS `tb_permission_grant_usage`
+(
+    `id`             BIGINT       NOT NULL AUTO_INCREMENT,
+    `product_id`     BIGINT       NOT NULL DEFAULT 0,
+    `app_id`         BIGINT       NOT NULL DEFAULT 0,
+    `subject`        VARCHAR(64)  NOT NULL COMMENT '权限组id或user:<用户id>',
+    `resource`       VARCHAR(255) NOT NULL DEFAULT '',
+    `hit_count`      BIGINT       NOT NULL DEFAULT 0 COMMENT '放行次数',
+    `last_used_time` BIGINT       NOT NULL DEFAULT 0 COMMENT '最近一次放行时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_subject_resource` (`product_id`, `app_id`, `subject`, `resource`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='权限组(或用户)对资源的授权使用情况';
+
+CREATE TABLE IF NOT EXISTS `tb_permission_member_usage`
+(
+    `id`             BIGINT NOT NULL AUTO_INCREMENT,
+    `product_id`     BIGINT NOT NULL DEFAULT 0,
+    `app_id`         BIGINT NOT NULL DEFAULT 0,
+    `user_id`        BIGINT NOT NULL DEFAULT 0,
+    `group_id`       BIGINT NOT NULL DEFAULT 0,
+    `hit_count`      BIGINT NOT NULL DEFAULT 0 COMMENT '经该权限组放行的次数',
+    `last_used_time` BIGINT NOT NULL DEFAULT 0 COMMENT '最近一次经该权限组放行的时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_user_group` (`user_id`, `group_id`),
+    KEY `idx_product_app` (`product_id`, `app_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='组员资格使用情况';
+
+-- 权限复核活动，创建时为所选权限组的组员打快照，由权限组负责人逐条确认保留或收回
+CREATE TABLE IF NOT EXISTS `tb_permission_review_campaign`
+(
+    `id`          BIGINT        NOT NULL AUTO_INCREMENT,
+    `product_id`  BIGINT        NOT NULL DEFAULT 0,
+    `app_id`      BIGINT        NOT NULL DEFAULT 0,
+    `name`        VARCHAR(128)  NOT NULL DEFAULT '',
+    `group_ids`   VARCHAR(1024) NOT NULL DEFAULT '' COMMENT '参与复核的权限组id json数组',
+    `status`      TINYINT       NOT NULL DEFAULT 0 COMMENT '0:进行中 1:已结束',
+    `deadline`    BIGINT        NOT NULL DEFAULT 0 COMMENT '截止时间，届时未复核的组员资格收回',
+    `create_uid`  BIGINT        NOT NULL DEFAULT 0,
+    `create_time` BIGINT        NOT NULL DEFAULT 0,
+    `close_uid`   BIGINT        NOT NULL DEFAULT 0 COMMENT '提前结束的操作人，0表示到期自动结束',
+    `close_time`  BIGINT        NOT NULL DEFAULT 0,
+    PRIMARY KEY (`id`),
+    KEY `idx_product_app` (`product_id`, `app_id`),
+    KEY `idx_status_deadline` (`status`, `deadline`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='权限复核活动';
+
+CREATE TABLE IF NOT EXISTS `tb_permission_review_item`
+(
+    `id`                 BIGINT       NOT NULL AUTO_INCREMENT,
+    `campaign_id`        BIGINT       NOT NULL DEFAULT 0,
+    `group_id`           BIGINT       NOT NULL DEFAULT 0,
+    `user_type`          TINYINT      NOT NULL DEFAULT 0,
+    `user_id`            BIGINT       NOT NULL DEFAULT 0,
+    `member_create_time` BIGINT       NOT NULL DEFAULT 0 COMMENT '快照时组员资格的加入时间',
+    `member_expire_time` BIGINT       NOT NULL DEFAULT 0 COMMENT '快照时组员资格的到期时间，0表示永久',
+    `reviewer_uid`       BIGINT       NOT NULL DEFAULT 0 COMMENT '复核人，0表示权限组没有其他负责人，由超级管理员复核',
+    `decision`           TINYINT      NOT NULL DEFAULT 0 COMMENT '0:待复核 1:保留 2:收回 3:截止时未复核已收回',
+    `decide_uid`         BIGINT       NOT NULL DEFAULT 0,
+    `decide_time`        BIGINT       NOT NULL DEFAULT 0,
+    `remark`             VARCHAR(255) NOT NULL DEFAULT '',
+    PRIMARY KEY (`id`),
+    KEY `idx_campaign` (`campaign_id`),
+    KEY `idx_reviewer` (`reviewer_uid`, `decision`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='权限复核条目，每个组员资格一条';
+
+-- 权限组版本号从1开始，编辑时必须携带版本号，0不再视为合法版本
+ALTER TABLE `tb_permission_group`
+    ALTER COLUMN `version` SET DEFAULT 1;
+UPDATE `tb_permission_group` SET `version` = 1 WHERE `version` = 0;